@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"text/tabwriter"
+
+	"wms/internal/config"
+)
+
+// runConfig implements "wms config", which inspects and changes wms.toml.
+func runConfig(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: wms config get|set|show|path|edit|validate|migrate")
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "get":
+		return runConfigGet(args[1:])
+	case "set":
+		return runConfigSet(args[1:])
+	case "show":
+		return runConfigShow(args[1:])
+	case "path":
+		fmt.Println(config.GetConfigPath())
+		return exitOK
+	case "edit":
+		return runConfigEdit(args[1:])
+	case "validate":
+		return runConfigValidate(args[1:])
+	case "migrate":
+		return runConfigMigrate(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "wms: unknown config command %q\n", args[0])
+		return exitUsage
+	}
+}
+
+// runConfigGet prints the effective value of one setting, or of every setting
+// when no key is given. Environment variables and flags are taken into account.
+func runConfigGet(args []string) int {
+	fs, flags := newFlagSet("wms config get", "[options] [key]")
+	if err := fs.Parse(args); err != nil {
+		return parseErrorCode(err)
+	}
+
+	cfg, issues := config.Load(flags)
+	printIssues(os.Stderr, issues)
+
+	keys := fs.Args()
+	if len(keys) == 0 {
+		for _, key := range config.Keys() {
+			value, _ := config.GetField(cfg, key)
+			fmt.Printf("%s = %s\n", key, value)
+		}
+		return exitOK
+	}
+
+	for _, key := range keys {
+		value, err := config.GetField(cfg, key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "wms: %v\n", err)
+			return exitUsage
+		}
+		fmt.Println(value)
+	}
+	return exitOK
+}
+
+// runConfigShow prints the effective value of every setting, optionally
+// together with where it came from: the default, wms.toml, an environment
+// variable or a flag.
+func runConfigShow(args []string) int {
+	fs, flags := newFlagSet("wms config show", "[options]")
+	origin := fs.Bool("origin", false, "Show where each value came from")
+	if err := fs.Parse(args); err != nil {
+		return parseErrorCode(err)
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	cfg, origins, issues := config.LoadWithOrigins(flags)
+	printIssues(os.Stderr, issues)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, key := range config.Keys() {
+		value, _ := config.GetField(cfg, key)
+		if *origin {
+			fmt.Fprintf(w, "%s\t= %s\t# %s\n", key, value, origins[key])
+		} else {
+			fmt.Fprintf(w, "%s\t= %s\n", key, value)
+		}
+	}
+	w.Flush()
+	return exitOK
+}
+
+// runConfigSet changes one setting in wms.toml. Only the file is read and
+// written, so environment variables and flags are never persisted.
+func runConfigSet(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "Usage: wms config set <key> <value>")
+		return exitUsage
+	}
+	key, value := args[0], args[1]
+
+	cfg, err := config.ReadConfigFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wms: %v (run \"wms config validate\")\n", err)
+		return exitFetch
+	}
+	if err := config.SetField(&cfg, key, value); err != nil {
+		fmt.Fprintf(os.Stderr, "wms: %v\n", err)
+		return exitUsage
+	}
+
+	// Refuse values that validation would reject; other issues in the file
+	// are not the concern of this command.
+	checked := cfg
+	for _, issue := range config.ValidateConfig(&checked) {
+		if issue.Key == key && issue.Severity == config.SeverityError {
+			fmt.Fprintf(os.Stderr, "wms: %s: %s\n", key, issue.Message)
+			return exitUsage
+		}
+	}
+
+	if err := config.WriteConfig(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "wms: %v\n", err)
+		return exitFetch
+	}
+	return exitOK
+}
+
+// runConfigEdit opens wms.toml in the user's editor and validates it
+// afterwards.
+func runConfigEdit(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: wms config edit")
+		return exitUsage
+	}
+
+	// Make sure there is a file to edit
+	if _, err := config.EnsureConfigFile(); err != nil {
+		fmt.Fprintf(os.Stderr, "wms: %v\n", err)
+		return exitFetch
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	cmd := exec.Command(editor, config.GetConfigPath())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "wms: failed to run editor %q: %v\n", editor, err)
+		return exitFetch
+	}
+
+	return runConfigValidate(nil)
+}
+
+// runConfigValidate checks wms.toml and reports every problem in the
+// "file:line: severity: key: message" form understood by most editors. It
+// exits with a non-zero code only when there are errors; warnings alone still
+// leave a usable configuration.
+func runConfigValidate(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: wms config validate")
+		return exitUsage
+	}
+
+	path := config.GetConfigPath()
+	issues, err := config.ValidateFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wms: %v\n", err)
+		return exitFetch
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fmt.Printf("%s does not exist, using defaults\n", path)
+	}
+
+	for _, issue := range issues {
+		location := path
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d", path, issue.Line)
+		}
+		message := issue.Message
+		if issue.Key != "" {
+			message = issue.Key + ": " + message
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s: %s\n", location, issue.Severity, message)
+	}
+	if config.HasErrors(issues) {
+		return exitFetch
+	}
+
+	if len(issues) == 0 {
+		fmt.Printf("%s is valid\n", path)
+	}
+	return exitOK
+}
+
+// runConfigMigrate upgrades wms.toml to the current format version, keeping a
+// backup of the old file.
+func runConfigMigrate(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: wms config migrate")
+		return exitUsage
+	}
+
+	result, err := config.MigrateConfigFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wms: %v\n", err)
+		return exitFetch
+	}
+	if !result.Migrated() {
+		fmt.Printf("%s is up to date (version %d)\n", config.GetConfigPath(), result.From)
+		return exitOK
+	}
+
+	printMigration(os.Stdout, result)
+	return exitOK
+}
+
+// printMigration describes a completed migration.
+func printMigration(w io.Writer, result config.MigrationResult) {
+	fmt.Fprintf(w, "Migrated %s from version %d to %d, backup saved to %s\n", config.GetConfigPath(), result.From, result.To, result.Backup)
+	for _, step := range result.Applied {
+		fmt.Fprintf(w, "  - %s\n", step)
+	}
+}
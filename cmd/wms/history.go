@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"wms/internal/config"
+	"wms/internal/history"
+	"wms/internal/weather"
+)
+
+// formatCSV is the default output format of "wms history", which also
+// supports formatJSON.
+const formatCSV = "csv"
+
+// runHistory implements "wms history", which prints the recorded observations
+// as CSV or JSON. -location narrows them down to matching places instead of
+// choosing where to fetch the weather. With -date, the weather of past days is
+// looked up in the Open-Meteo archive instead, where -location chooses the
+// place like for the other commands.
+func runHistory(args []string, stdout, stderr io.Writer) int {
+	fs, flags := newFlagSet("wms history", "[options]")
+	fs.SetOutput(stderr)
+	format := fs.String("format", formatCSV, "Output format (csv, json)")
+	since := fs.String("since", "", "Only observations from this time on: a date (2006-01-02), RFC 3339 time or age (36h, 7d)")
+	until := fs.String("until", "", "Only observations before this time, in the same forms as -since")
+	date := fs.String("date", "", "Look up a past day (2006-01-02) or range of days (2006-01-01..2006-01-07) in the Open-Meteo archive")
+	if err := fs.Parse(args); err != nil {
+		return parseErrorCode(err)
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "wms: unexpected argument %q\n", fs.Arg(0))
+		return exitUsage
+	}
+	if *format != formatCSV && *format != formatJSON {
+		fmt.Fprintf(stderr, "wms: unknown format %q (use %s or %s)\n", *format, formatCSV, formatJSON)
+		return exitUsage
+	}
+
+	if *date != "" {
+		if *since != "" || *until != "" {
+			fmt.Fprintln(stderr, "wms: -date cannot be combined with -since or -until")
+			return exitUsage
+		}
+		return runArchive(flags, *date, *format, stdout, stderr)
+	}
+
+	filter := history.Filter{Location: strings.TrimSpace(flags.Location)}
+	var err error
+	now := time.Now()
+	if filter.Since, err = parseHistoryTime(*since, now); err != nil {
+		fmt.Fprintf(stderr, "wms: -since: %v\n", err)
+		return exitUsage
+	}
+	if filter.Until, err = parseHistoryTime(*until, now); err != nil {
+		fmt.Fprintf(stderr, "wms: -until: %v\n", err)
+		return exitUsage
+	}
+
+	cfg, issues := config.Load(flags)
+	printIssues(stderr, issues)
+
+	observations, err := history.Load(filter)
+	if err != nil {
+		fmt.Fprintf(stderr, "wms: %v\n", err)
+		return exitFetch
+	}
+	if len(observations) == 0 && !cfg.History.Enabled {
+		fmt.Fprintln(stderr, "wms: recording is off, turn it on with \"wms config set history.enabled true\"")
+	}
+
+	if *format == formatJSON {
+		err = history.WriteJSON(stdout, observations)
+	} else {
+		err = history.WriteCSV(stdout, observations)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "wms: %v\n", err)
+		return exitFetch
+	}
+	return exitOK
+}
+
+// parseHistoryTime parses a -since or -until value: a date, taken as midnight
+// local time, an RFC 3339 time, or an age such as "36h" or "7d" counted back
+// from now. An empty value gives the zero time.
+func parseHistoryTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, use a date (2006-01-02), an RFC 3339 time or an age (36h, 7d)", value)
+}
+
+// runArchive implements "wms history -date", which looks up the hourly weather
+// of past days and prints it as CSV, with the columns of the recorded history,
+// or as JSON, one normalized weather.Weather per hour.
+func runArchive(flags *config.Flags, date, format string, stdout, stderr io.Writer) int {
+	from, to, err := parseDateRange(date)
+	if err != nil {
+		fmt.Fprintf(stderr, "wms: -date: %v\n", err)
+		return exitUsage
+	}
+
+	cfg, issues := config.Load(flags)
+	printIssues(stderr, issues)
+
+	archive, err := weather.FetchArchiveWithConfig(cfg, from, to)
+	if err != nil {
+		fmt.Fprintf(stderr, "wms: %v\n", err)
+		return exitFetch
+	}
+
+	if format == formatJSON {
+		data, err := json.MarshalIndent(archive.Hours, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stderr, "wms: failed to encode weather: %v\n", err)
+			return exitFetch
+		}
+		fmt.Fprintln(stdout, string(data))
+		return exitOK
+	}
+	if err := history.WriteCSV(stdout, history.FromArchive(archive)); err != nil {
+		fmt.Fprintf(stderr, "wms: %v\n", err)
+		return exitFetch
+	}
+	return exitOK
+}
+
+// parseDateRange parses a -date value: a single day or a range of days such
+// as "2025-01-10..2025-01-15", both ends included. Days from today on have no
+// archived weather yet and are rejected, and so are ranges the archive cannot
+// look up at once, see weather.ValidateArchiveRange.
+func parseDateRange(value string) (from, to time.Time, err error) {
+	first, last, isRange := strings.Cut(value, "..")
+	if !isRange {
+		last = first
+	}
+	if from, err = time.Parse("2006-01-02", strings.TrimSpace(first)); err != nil {
+		return from, to, fmt.Errorf("invalid date %q, use 2006-01-02 or 2006-01-01..2006-01-07", first)
+	}
+	if to, err = time.Parse("2006-01-02", strings.TrimSpace(last)); err != nil {
+		return from, to, fmt.Errorf("invalid date %q, use 2006-01-02 or 2006-01-01..2006-01-07", last)
+	}
+
+	if err := weather.ValidateArchiveRange(from, to); err != nil {
+		return from, to, err
+	}
+	if today := time.Now().Format("2006-01-02"); to.Format("2006-01-02") >= today {
+		return from, to, fmt.Errorf("%s has no archived weather yet, pick a day before today", to.Format("2006-01-02"))
+	}
+	return from, to, nil
+}
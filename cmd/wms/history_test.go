@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHistoryTime(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "", want: time.Time{}},
+		{value: "2025-03-01", want: time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)},
+		{value: "2025-03-01T08:00:00+01:00", want: time.Date(2025, 3, 1, 7, 0, 0, 0, time.UTC)},
+		{value: "7d", want: now.AddDate(0, 0, -7)},
+		{value: "0d", want: now},
+		{value: "36h", want: now.Add(-36 * time.Hour)},
+		{value: "90m", want: now.Add(-90 * time.Minute)},
+		{value: "-3d", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "yesterday", wantErr: true},
+		{value: "2025-13-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseHistoryTime(tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseHistoryTime(%q) = %v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHistoryTime(%q) error = %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseHistoryTime(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	today := time.Now().Format("2006-01-02")
+	lastWeek := time.Now().AddDate(0, 0, -7).Format("2006-01-02")
+	day := func(value string) time.Time {
+		d, _ := time.Parse("2006-01-02", value)
+		return d
+	}
+	tests := []struct {
+		value    string
+		wantFrom string
+		wantTo   string
+		wantErr  string
+	}{
+		{value: "2025-01-15", wantFrom: "2025-01-15", wantTo: "2025-01-15"},
+		{value: "2025-01-10..2025-01-15", wantFrom: "2025-01-10", wantTo: "2025-01-15"},
+		{value: " 2025-01-10 .. 2025-01-15 ", wantFrom: "2025-01-10", wantTo: "2025-01-15"},
+		{value: "2025-01-01..2025-01-31", wantFrom: "2025-01-01", wantTo: "2025-01-31"},
+		{value: yesterday, wantFrom: yesterday, wantTo: yesterday},
+		{value: "2025-01-01..2025-02-01", wantErr: "at most 31"},
+		{value: "2025-01-15..2025-01-10", wantErr: "ends before it starts"},
+		{value: today, wantErr: "no archived weather yet"},
+		{value: lastWeek + ".." + today, wantErr: "no archived weather yet"},
+		{value: "15.01.2025", wantErr: "invalid date"},
+		{value: "2025-01-10..soon", wantErr: "invalid date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			from, to, err := parseDateRange(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseDateRange(%q) error = %v, want one containing %q", tt.value, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDateRange(%q) error = %v", tt.value, err)
+			}
+			if !from.Equal(day(tt.wantFrom)) || !to.Equal(day(tt.wantTo)) {
+				t.Errorf("parseDateRange(%q) = %v..%v, want %s..%s", tt.value, from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestRunHistoryUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"unknown format", []string{"-format", "xml"}},
+		{"date with since", []string{"-date", "2025-01-15", "-since", "7d"}},
+		{"invalid since", []string{"-since", "soon"}},
+		{"invalid date", []string{"-date", "2025-01-15..2025-01-10"}},
+		{"argument", []string{"today"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			if code := runHistory(tt.args, &stdout, &stderr); code != exitUsage {
+				t.Errorf("runHistory(%q) = %d, want %d (stderr: %s)", tt.args, code, exitUsage, stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"wms/internal/config"
+)
+
+// runKey implements "wms key", which manages the WeatherAPI key stored in the
+// .env file next to wms.toml.
+func runKey(args []string) int {
+	if len(args) == 0 || args[0] != "set" || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, "Usage: wms key set [key]")
+		return exitUsage
+	}
+
+	// Read the key from stdin when it is not given, so it stays out of the
+	// shell history.
+	apiKey := ""
+	if len(args) == 2 {
+		apiKey = args[1]
+	} else {
+		fmt.Fprint(os.Stderr, "WeatherAPI key: ")
+		scanner := bufio.NewScanner(os.Stdin)
+		if scanner.Scan() {
+			apiKey = scanner.Text()
+		}
+	}
+
+	cleanedKey, err := config.SaveAPIKey(apiKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wms: %v\n", err)
+		return exitFetch
+	}
+	if cleanedKey == "" {
+		fmt.Fprintf(os.Stderr, "API key cleared in %s\n", config.GetEnvPath())
+		return exitOK
+	}
+
+	fmt.Fprintf(os.Stderr, "API key saved to %s\n", config.GetEnvPath())
+	return exitOK
+}
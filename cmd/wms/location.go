@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"wms/internal/config"
+)
+
+// runLocation implements "wms location", which manages the saved locations
+// shown in the Locations tab.
+func runLocation(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: wms location add|list|remove")
+		return exitUsage
+	}
+
+	cfg, err := config.ReadConfigFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wms: %v (run \"wms config validate\")\n", err)
+		return exitFetch
+	}
+
+	switch args[0] {
+	case "list":
+		for i, location := range cfg.Locations {
+			fmt.Printf("%d. %s\n", i+1, location)
+		}
+		return exitOK
+	case "add":
+		name := strings.TrimSpace(strings.Join(args[1:], " "))
+		if name == "" {
+			fmt.Fprintln(os.Stderr, "Usage: wms location add <location>")
+			return exitUsage
+		}
+		if findLocation(cfg.Locations, name) >= 0 {
+			fmt.Fprintf(os.Stderr, "wms: %q is already saved\n", name)
+			return exitUsage
+		}
+		cfg.Locations = append(cfg.Locations, name)
+	case "remove":
+		name := strings.TrimSpace(strings.Join(args[1:], " "))
+		if name == "" {
+			fmt.Fprintln(os.Stderr, "Usage: wms location remove <location|number>")
+			return exitUsage
+		}
+		// A saved name wins over a number, so a location called "2" can be
+		// removed by name
+		index := findLocation(cfg.Locations, name)
+		if n, err := strconv.Atoi(name); err == nil && index < 0 && n >= 1 && n <= len(cfg.Locations) {
+			index = n - 1
+		}
+		if index < 0 {
+			fmt.Fprintf(os.Stderr, "wms: %q is not a saved location\n", name)
+			return exitUsage
+		}
+		cfg.Locations = append(cfg.Locations[:index], cfg.Locations[index+1:]...)
+	default:
+		fmt.Fprintf(os.Stderr, "wms: unknown location command %q\n", args[0])
+		return exitUsage
+	}
+
+	if err := config.WriteConfig(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "wms: %v\n", err)
+		return exitFetch
+	}
+	return exitOK
+}
+
+// findLocation returns the index of a saved location, ignoring case, or -1.
+func findLocation(locations []string, name string) int {
+	for i, location := range locations {
+		if strings.EqualFold(location, name) {
+			return i
+		}
+	}
+	return -1
+}
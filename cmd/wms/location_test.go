@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"wms/internal/config"
+)
+
+func TestLocationRemove(t *testing.T) {
+	tests := []struct {
+		name     string
+		argument string
+		want     []string
+		wantCode int
+	}{
+		{"by name", "tokyo", []string{"Berlin", "1"}, exitOK},
+		{"name wins over number", "1", []string{"Tokyo", "Berlin"}, exitOK},
+		{"by number", "2", []string{"Tokyo", "1"}, exitOK},
+		{"unknown", "Paris", []string{"Tokyo", "Berlin", "1"}, exitUsage},
+		{"number out of range", "4", []string{"Tokyo", "Berlin", "1"}, exitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", dir)
+			t.Setenv(config.EnvConfigPath, "")
+			path := filepath.Join(dir, "wms", "wms.toml")
+			os.MkdirAll(filepath.Dir(path), 0700)
+			if err := os.WriteFile(path, []byte("version = 2\nlocations = [\"Tokyo\", \"Berlin\", \"1\"]\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if code := runLocation([]string{"remove", tt.argument}); code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", code, tt.wantCode)
+			}
+			cfg, err := config.ReadConfigFile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cfg.Locations, tt.want) {
+				t.Errorf("locations = %q, want %q", cfg.Locations, tt.want)
+			}
+		})
+	}
+}
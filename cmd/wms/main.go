@@ -3,58 +3,222 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"wms/internal/config"
 	"wms/internal/ui/models"
+	"wms/internal/ui/styles"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// Exit codes shared by all commands so scripts can tell failures apart.
+const (
+	exitOK    = 0
+	exitFetch = 1 // Fetching data or running the command failed
+	exitUsage = 2 // Invalid command-line usage
+)
+
 func main() {
-	// Parse command line flags
-	location := flag.String("location", "", "Location to get weather for")
-	units := flag.String("units", "metric", "Units (metric, imperial)")
-	timeFormat := flag.String("time", "24", "Time format (12, 24)")
-	compact := flag.Bool("compact", false, "Compact display mode")
-	refresh := flag.Int("refresh", 5, "Refresh interval in minutes")
-	help := flag.Bool("help", false, "Show help")
-	flag.Parse()
+	os.Exit(run(os.Args[1:]))
+}
 
-	if *help {
-		flag.Usage()
-		return
+// run dispatches to the subcommand named by the first argument. Without a
+// subcommand, the full-screen TUI is started. A leading -config option applies
+// to every command, including those that take no other flags.
+func run(args []string) int {
+	args, ok := parseConfigOption(args)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "wms: -config requires a file name")
+		return exitUsage
 	}
 
-	// Load configuration
-	cfg := config.ReadConfig()
+	if len(args) > 0 {
+		switch args[0] {
+		case "now":
+			return runNow(args[1:])
+		case "status":
+			return runStatus(args[1:], os.Stdout, os.Stderr)
+		case "config":
+			return runConfig(args[1:])
+		case "location":
+			return runLocation(args[1:])
+		case "key":
+			return runKey(args[1:])
+		case "history":
+			return runHistory(args[1:], os.Stdout, os.Stderr)
+		case "help":
+			printUsage(os.Stdout)
+			return exitOK
+		}
+	}
+	return runTUI(args)
+}
 
-	// Override config with command line flags
-	if *location != "" {
-		cfg.Location = *location
+// runTUI implements "wms", which starts the interactive dashboard.
+func runTUI(args []string) int {
+	fs, flags := newFlagSet("wms", "[options]")
+	fs.Usage = func() { printUsage(fs.Output()) }
+	once := fs.Bool("once", false, "Fetch the weather once, print it and exit (same as \"wms now\")")
+	format := fs.String("format", formatText, "Output format for -once (text, json, line)")
+	if err := fs.Parse(args); err != nil {
+		return parseErrorCode(err)
 	}
-	if *units != "" {
-		cfg.Units = *units
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "wms: unknown command %q\n\n", fs.Arg(0))
+		printUsage(os.Stderr)
+		return exitUsage
 	}
-	if *timeFormat != "" {
-		cfg.TimeFormat = *timeFormat
+
+	// Give first time users of the dashboard a config file to edit, and bring
+	// older files up to date. This happens before the alt screen is entered,
+	// so the messages stay visible after quitting.
+	var ensureErr error
+	if !*once {
+		if flags.ConfigPath != "" {
+			config.SetConfigPath(flags.ConfigPath)
+		}
+		created, err := config.EnsureConfigFile()
+		if created {
+			fmt.Fprintf(os.Stderr, "Config created at %s\n", config.GetConfigPath())
+		}
+		if err == nil {
+			var result config.MigrationResult
+			if result, err = config.MigrateConfigFile(); err == nil && result.Migrated() {
+				printMigration(os.Stderr, result)
+			}
+		}
+		ensureErr = err
 	}
-	if *compact {
-		cfg.Compact = *compact
+
+	// Load configuration
+	cfg, issues := config.Load(flags)
+
+	// Print the weather once and exit instead of starting the TUI
+	if *once {
+		printIssues(os.Stderr, issues)
+		return runOnce(cfg, *format, os.Stdout, os.Stderr)
 	}
-	if *refresh > 0 {
-		cfg.RefreshInterval = *refresh
+	if ensureErr != nil {
+		issues = append(issues, config.Issue{Severity: config.SeverityWarning, Message: ensureErr.Error()})
 	}
 
-	// Initialize the model with configuration
-	m := models.InitialModelWithConfig(cfg)
+	// Ask the terminal for its background before Bubble Tea takes over
+	// input, so switching to the auto theme later does not have to
+	styles.DetectBackground()
+	styles.ApplyConfig(cfg)
+
+	// Initialize the model with configuration. Config issues are shown in the
+	// status area, since anything written to stderr would corrupt the screen.
+	m := models.InitialModelWithConfig(cfg).WithConfigIssues(issues).WithConfigFlags(flags)
 
 	// Create the Bubble Tea program
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
 	// Run the program
 	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error running program: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+		return exitFetch
+	}
+	return exitOK
+}
+
+// parseConfigOption removes a leading "-config FILE" (or "--config=FILE") from
+// the arguments and selects that config file. It returns false if the file
+// name is missing.
+func parseConfigOption(args []string) ([]string, bool) {
+	if len(args) == 0 {
+		return args, true
+	}
+
+	name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+	if !strings.HasPrefix(args[0], "-") || name != "config" {
+		return args, true
+	}
+	if hasValue {
+		config.SetConfigPath(value)
+		return args[1:], true
+	}
+	if len(args) < 2 {
+		return args, false
+	}
+	config.SetConfigPath(args[1])
+	return args[2:], true
+}
+
+// newFlagSet creates the flag set for a command, with the shared configuration
+// flags already registered. The usage line is printed before the defaults.
+func newFlagSet(name, usage string) (*flag.FlagSet, *config.Flags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s\n\nOptions:\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs, config.RegisterFlags(fs)
+}
+
+// parseErrorCode maps a flag parsing error to an exit code. Asking for help
+// is not an error.
+func parseErrorCode(err error) int {
+	if err == flag.ErrHelp {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// printIssues prints configuration issues, one per line.
+func printIssues(w io.Writer, issues []config.Issue) {
+	for _, issue := range issues {
+		fmt.Fprintf(w, "wms: config %s: %s\n", issue.Severity, issue)
 	}
-}
\ No newline at end of file
+}
+
+// printUsage prints the top-level help, including the keyboard shortcuts of
+// the TUI.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, strings.Join([]string{
+		"Usage: wms [-config file] [command] [options]",
+		"",
+		"Weather Management System (WMS) - A comprehensive weather dashboard",
+		"",
+		"Commands:",
+		"  (none)                     Start the interactive dashboard",
+		"  now                        Print the current weather once and exit",
+		"  status                     Print a status line summary from the cache",
+		"  config get|set|show|path|edit|validate|migrate",
+		"                             Inspect and change wms.toml",
+		"  location add|list|remove   Manage saved locations",
+		"  key set                    Save the WeatherAPI key",
+		"  history                    Export recorded observations as CSV or JSON,",
+		"                             or past weather with -date",
+		"",
+		"Options (accepted by every command):",
+	}, "\n"))
+
+	// Print the shared flags from the same definitions the commands use
+	fs := flag.NewFlagSet("wms", flag.ContinueOnError)
+	fs.SetOutput(w)
+	config.RegisterFlags(fs)
+	fs.PrintDefaults()
+
+	fmt.Fprintln(w, strings.Join([]string{
+		"",
+		"Settings are applied in order of precedence: defaults < config file < profile < environment < flags.",
+		"Config file is located at: " + config.GetConfigPath() + " (change with -config or $" + config.EnvConfigPath + ")",
+		"",
+		"Keyboard shortcuts:",
+		"  [1-5] - Switch between Weather/Moon/Solar/Locations/History tabs",
+		"  [←/→] - Select a day in the History tab ([↑/↓] switches the place)",
+		"  [A] - Look up a past day in the History tab (date picker: ←/→ day, ↑/↓ week, [/] month)",
+		"  [Tab/Shift+Tab] - Navigate tabs",
+		"  [D] - Toggle the dashboard layout (Weather, Moon and Solar together)",
+		"  [U] - Cycle units/time (Metric 24h → Metric 12h → Imperial 24h → Imperial 12h)",
+		"  [T] - Toggle time format only",
+		"  [R] - Refresh data",
+		"  [S] - Settings menu",
+		"  [Q] - Quit",
+	}, "\n"))
+}
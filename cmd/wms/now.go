@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"wms/internal/cache"
+	"wms/internal/config"
+	"wms/internal/history"
+	"wms/internal/ui/styles"
+	"wms/internal/weather"
+)
+
+// Output formats supported by the one-shot mode.
+const (
+	formatText = "text" // Two-column card with the ASCII art icon
+	formatJSON = "json" // The normalized weather.Weather model
+	formatLine = "line" // A single-line summary
+)
+
+// runNow implements "wms now", which prints the weather once and exits.
+func runNow(args []string) int {
+	fs, flags := newFlagSet("wms now", "[options]")
+	format := fs.String("format", formatText, "Output format (text, json, line)")
+	if err := fs.Parse(args); err != nil {
+		return parseErrorCode(err)
+	}
+
+	cfg, issues := config.Load(flags)
+	printIssues(os.Stderr, issues)
+	return runOnce(cfg, *format, os.Stdout, os.Stderr)
+}
+
+// runOnce fetches the weather a single time and prints it in the requested
+// format instead of starting the TUI. It returns the process exit code.
+func runOnce(cfg config.Config, format string, stdout, stderr io.Writer) int {
+	switch format {
+	case formatText, formatJSON, formatLine:
+	default:
+		fmt.Fprintf(stderr, "wms: unknown format %q (use %s, %s or %s)\n", format, formatText, formatJSON, formatLine)
+		return exitUsage
+	}
+
+	w, source, err := weather.FetchWithConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "wms: %v\n", err)
+		return exitFetch
+	}
+	cache.Save(cfg, w, source)
+	history.Record(w, cfg.WeatherProvider, cfg.History)
+
+	styles.ApplyConfig(cfg)
+
+	switch format {
+	case formatJSON:
+		data, err := json.MarshalIndent(w, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stderr, "wms: failed to encode weather: %v\n", err)
+			return exitFetch
+		}
+		fmt.Fprintln(stdout, string(data))
+	case formatLine:
+		fmt.Fprintln(stdout, weather.RenderWeatherLine(w, cfg))
+	default:
+		fmt.Fprintln(stdout, weather.RenderWeatherCompact(w, cfg))
+	}
+
+	return exitOK
+}
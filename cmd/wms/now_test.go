@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"wms/internal/config"
+)
+
+// weatherAPIResponse is a WeatherAPI forecast answer for Berlin.
+const weatherAPIResponse = `{
+	"location": {"name": "Berlin", "country": "Germany", "lat": 52.52, "lon": 13.4},
+	"current": {"temp_c": 4.5, "temp_f": 40.1, "is_day": 1, "condition": {"text": "Overcast"}, "wind_kph": 12, "wind_dir": "W", "humidity": 81}
+}`
+
+// fakeWeatherAPI answers every HTTP request made by the weather providers
+// with the given status and body, instead of going to the network.
+func fakeWeatherAPI(t *testing.T, status int, body string) {
+	t.Helper()
+	original := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(*http.Request) *http.Response {
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header)}
+	})
+	t.Cleanup(func() { http.DefaultTransport = original })
+}
+
+// roundTripFunc answers HTTP requests without a network.
+type roundTripFunc func(*http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func TestRunOnceExitCodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     string
+		apiKey     string
+		status     int
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{name: "text", format: formatText, apiKey: "key", status: 200, wantCode: exitOK, wantStdout: "Overcast"},
+		{name: "line", format: formatLine, apiKey: "key", status: 200, wantCode: exitOK, wantStdout: "Berlin"},
+		{name: "json", format: formatJSON, apiKey: "key", status: 200, wantCode: exitOK, wantStdout: `"name": "Berlin"`},
+		{name: "unknown format", format: "xml", apiKey: "key", status: 200, wantCode: exitUsage, wantStderr: "unknown format"},
+		{name: "rejected API key", format: formatLine, apiKey: "key", status: 401, wantCode: exitFetch, wantStderr: "invalid API key"},
+		{name: "server error", format: formatLine, apiKey: "key", status: 500, wantCode: exitFetch, wantStderr: "status code 500"},
+		{name: "missing API key", format: formatLine, status: 200, wantCode: exitFetch, wantStderr: "API key is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+			t.Setenv("XDG_STATE_HOME", t.TempDir())
+			fakeWeatherAPI(t, tt.status, weatherAPIResponse)
+
+			cfg := config.DefaultConfig()
+			cfg.WeatherProvider = config.ProviderWeatherAPI
+			cfg.WeatherAPIKey = tt.apiKey
+			cfg.LocationMode = "manual"
+			cfg.Location = "Berlin"
+			cfg.UseColors = false
+
+			var stdout, stderr bytes.Buffer
+			if code := runOnce(cfg, tt.format, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("runOnce() = %d, want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantStdout) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+			if tt.wantCode != exitOK && stdout.Len() > 0 {
+				t.Errorf("stdout = %q after a failure, want nothing", stdout.String())
+			}
+			if tt.format == formatJSON && !json.Valid(stdout.Bytes()) {
+				t.Errorf("stdout is not valid JSON: %s", stdout.String())
+			}
+		})
+	}
+}
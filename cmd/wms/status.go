@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"wms/internal/cache"
+	"wms/internal/config"
+	"wms/internal/history"
+	"wms/internal/status"
+	"wms/internal/weather"
+)
+
+// runStatus implements "wms status", which prints a short weather summary for
+// status lines. It only reads the on-disk cache, so it is cheap enough to run
+// every few seconds. With -fetch, it calls the weather API once the cache is
+// older than the refresh interval. It returns the process exit code.
+func runStatus(args []string, stdout, stderr io.Writer) int {
+	fs, flags := newFlagSet("wms status", "[options]")
+	fs.SetOutput(stderr)
+	presetName := fs.String("preset", "plain", "Output preset ("+strings.Join(status.PresetNames(), ", ")+")")
+	text := fs.String("template", "", "Go text/template for the output, overrides the preset's text")
+	fetch := fs.Bool("fetch", false, "Call the weather API when the cached weather is older than the refresh interval (by default only the cache is read)")
+	if err := fs.Parse(args); err != nil {
+		return parseErrorCode(err)
+	}
+
+	preset, ok := status.Presets[*presetName]
+	if !ok {
+		fmt.Fprintf(stderr, "wms: unknown preset %q (use %s)\n", *presetName, strings.Join(status.PresetNames(), ", "))
+		return exitUsage
+	}
+	if *text != "" {
+		preset.Text = *text
+	}
+
+	cfg, issues := config.Load(flags)
+	printIssues(stderr, issues)
+
+	entry, err := cachedWeather(cfg, *fetch)
+	if err != nil {
+		fmt.Fprintf(stderr, "wms: %v\n", err)
+		return exitFetch
+	}
+
+	output, err := status.Render(preset, status.NewData(entry.Weather, entry.FetchedAt, cfg))
+	if err != nil {
+		fmt.Fprintf(stderr, "wms: %v\n", err)
+		return exitUsage
+	}
+
+	fmt.Fprintln(stdout, output)
+	return exitOK
+}
+
+// cachedWeather returns the cached weather for the configuration. With fetch,
+// it is refreshed first when it is older than the refresh interval; failed
+// refreshes are recorded, so the next ones back off rather than calling the
+// API on every run. If the refresh fails, stale data is still better than
+// nothing for a status line.
+func cachedWeather(cfg config.Config, fetch bool) (*cache.Entry, error) {
+	entry, cacheErr := cache.Load(cfg)
+	interval := time.Duration(cfg.RefreshInterval) * time.Minute
+	if cacheErr == nil && (!fetch || time.Now().Before(entry.RetryAt(interval))) {
+		if entry.Weather == nil {
+			return nil, fmt.Errorf("no cached weather yet, the last fetch failed: %s", entry.LastError)
+		}
+		return entry, nil
+	}
+	if !fetch {
+		return nil, fmt.Errorf("%w, start wms or run \"wms status -fetch\"", cacheErr)
+	}
+
+	w, source, err := weather.FetchWithConfig(cfg)
+	if err != nil {
+		cache.RecordFailure(cfg, err)
+		if cacheErr == nil && entry.Weather != nil {
+			return entry, nil
+		}
+		return nil, err
+	}
+
+	cache.Save(cfg, w, source)
+	history.Record(w, cfg.WeatherProvider, cfg.History)
+	return &cache.Entry{Weather: w, LocationSource: source, FetchedAt: time.Now()}, nil
+}
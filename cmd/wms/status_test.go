@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunStatusFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStderr string
+	}{
+		{"cache only by default", nil, exitFetch, `run "wms status -fetch"`},
+		{"no -offline flag", []string{"-offline"}, exitUsage, "-offline"},
+		{"unknown preset", []string{"-preset", "xml"}, exitUsage, "unknown preset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+			var stdout, stderr strings.Builder
+			if code := runStatus(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("runStatus(%q) = %d, want %d (stderr: %s)", tt.args, code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
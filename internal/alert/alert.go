@@ -0,0 +1,164 @@
+// Package alert checks the alert rules of the configuration against the
+// current weather. A Tracker remembers which alerts are active between
+// checks, so each alert is reported once when it is raised rather than on
+// every refresh, and only clears once the value has moved back past the
+// threshold by the rule's hysteresis.
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"wms/internal/config"
+	"wms/internal/weather"
+)
+
+// Active is an alert whose condition holds.
+type Active struct {
+	Name      string
+	Condition config.AlertCondition
+	Value     float64   // The value at the last check
+	Location  string    // Where the weather was observed
+	Since     time.Time // When the alert was raised
+}
+
+// Message describes the alert, e.g. "frost: temp_c < 0 (now -1.5)".
+func (a Active) Message() string {
+	return fmt.Sprintf("%s: %s (now %s)", a.Name, a.Condition, strconv.FormatFloat(a.Value, 'f', -1, 64))
+}
+
+// Tracker keeps the active alerts between checks. The zero value has no
+// active alerts.
+type Tracker struct {
+	active map[string]Active
+}
+
+// Check evaluates the rules against the weather and returns the alerts that
+// were raised by it, in alphabetical order. Alerts that were already active
+// are not returned again; alerts whose rule was removed or changed are
+// cleared.
+func (t *Tracker) Check(rules map[string]config.Alert, w *weather.Weather, now time.Time) []Active {
+	if w == nil {
+		return nil
+	}
+	if t.active == nil {
+		t.active = make(map[string]Active)
+	}
+	for name := range t.active {
+		if _, ok := rules[name]; !ok {
+			delete(t.active, name)
+		}
+	}
+
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var raised []Active
+	for _, name := range names {
+		rule := rules[name]
+		condition, err := config.ParseAlertCondition(rule.When)
+		if err != nil {
+			continue // Reported by config validation
+		}
+		value := Value(w.Current, condition.Field)
+
+		previous, active := t.active[name]
+		if active && previous.Condition != condition {
+			active = false // The rule was edited, start over
+		}
+		if !holds(condition, value, active, rule.Hysteresis) {
+			delete(t.active, name)
+			continue
+		}
+		if active {
+			previous.Value = value
+			t.active[name] = previous
+			continue
+		}
+
+		a := Active{Name: name, Condition: condition, Value: value, Location: w.Location.Name, Since: now}
+		t.active[name] = a
+		raised = append(raised, a)
+	}
+	return raised
+}
+
+// Active returns the active alerts in alphabetical order.
+func (t *Tracker) Active() []Active {
+	alerts := make([]Active, 0, len(t.active))
+	for _, a := range t.active {
+		alerts = append(alerts, a)
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].Name < alerts[j].Name })
+	return alerts
+}
+
+// holds reports whether the condition is met by value. While an alert is
+// active, its threshold is moved back by the hysteresis, so the alert stays
+// active until the value has clearly recovered.
+func holds(c config.AlertCondition, value float64, active bool, hysteresis float64) bool {
+	threshold := c.Threshold
+	if active {
+		switch c.Operator {
+		case "<", "<=":
+			threshold += hysteresis
+		case ">", ">=":
+			threshold -= hysteresis
+		}
+	}
+
+	switch c.Operator {
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "==":
+		return value == threshold
+	case "!=":
+		return value != threshold
+	}
+	return false
+}
+
+// Value returns the value of one of config.AlertFields in the current
+// conditions.
+func Value(c weather.Current, field string) float64 {
+	switch field {
+	case "temp_c":
+		return c.TempC
+	case "temp_f":
+		return c.TempF
+	case "feelslike_c":
+		return c.FeelslikeC
+	case "feelslike_f":
+		return c.FeelslikeF
+	case "wind_kph":
+		return c.WindKph
+	case "wind_mph":
+		return c.WindMph
+	case "humidity":
+		return float64(c.Humidity)
+	case "precip_mm":
+		return c.PrecipMm
+	case "pressure_mb":
+		return c.PressureMb
+	case "cloud":
+		return float64(c.Cloud)
+	case "vis_km":
+		return c.Visibility
+	case "uv":
+		return c.UV
+	case "is_day":
+		return float64(c.IsDay)
+	}
+	return 0
+}
@@ -0,0 +1,170 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"wms/internal/config"
+	"wms/internal/weather"
+)
+
+// weatherAt returns weather in Berlin with the given temperature and wind.
+func weatherAt(tempC, windKph float64) *weather.Weather {
+	w := &weather.Weather{}
+	w.Location.Name = "Berlin"
+	w.Current.TempC = tempC
+	w.Current.WindKph = windKph
+	return w
+}
+
+// names returns the names of the alerts.
+func names(alerts []Active) []string {
+	var names []string
+	for _, a := range alerts {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+func TestHolds(t *testing.T) {
+	tests := []struct {
+		when       string
+		value      float64
+		active     bool
+		hysteresis float64
+		want       bool
+	}{
+		{"temp_c < 0", -1, false, 0, true},
+		{"temp_c < 0", 0, false, 0, false},
+		{"temp_c <= 0", 0, false, 0, true},
+		{"temp_c < 0", 0.5, true, 1, true},
+		{"temp_c < 0", 1, true, 1, false},
+		{"temp_c < 0", 0.5, false, 1, false},
+		{"wind_kph > 50", 51, false, 0, true},
+		{"wind_kph > 50", 45, true, 10, true},
+		{"wind_kph > 50", 40, true, 10, false},
+		{"wind_kph >= 50", 40, true, 10, true},
+		{"is_day == 0", 0, true, 5, true},
+		{"is_day != 0", 1, false, 0, true},
+	}
+	for _, tt := range tests {
+		c, err := config.ParseAlertCondition(tt.when)
+		if err != nil {
+			t.Fatalf("ParseAlertCondition(%q) error = %v", tt.when, err)
+		}
+		if got := holds(c, tt.value, tt.active, tt.hysteresis); got != tt.want {
+			t.Errorf("holds(%q, %v, active %v, hysteresis %v) = %v, want %v", tt.when, tt.value, tt.active, tt.hysteresis, got, tt.want)
+		}
+	}
+}
+
+func TestTrackerCheck(t *testing.T) {
+	frost := map[string]config.Alert{"frost": {When: "temp_c < 0", Hysteresis: 1}}
+	steps := []struct {
+		name       string
+		rules      map[string]config.Alert
+		weather    *weather.Weather
+		wantRaised []string
+		wantActive []string
+	}{
+		{"above the threshold", frost, weatherAt(2, 0), nil, nil},
+		{"raised", frost, weatherAt(-1, 0), []string{"frost"}, []string{"frost"}},
+		{"not raised again", frost, weatherAt(-3, 0), nil, []string{"frost"}},
+		{"kept within the hysteresis", frost, weatherAt(0.5, 0), nil, []string{"frost"}},
+		{"cleared once recovered", frost, weatherAt(1, 0), nil, nil},
+		{"raised again", frost, weatherAt(-0.5, 0), []string{"frost"}, []string{"frost"}},
+		{"no weather", frost, nil, nil, []string{"frost"}},
+		{"cleared when the rule is removed", map[string]config.Alert{}, weatherAt(-5, 0), nil, nil},
+		{
+			name: "several rules in order, invalid ones skipped",
+			rules: map[string]config.Alert{
+				"storm":  {When: "wind_kph > 50"},
+				"frost":  {When: "temp_c < 0"},
+				"broken": {When: "temp_c <"},
+			},
+			weather:    weatherAt(-5, 80),
+			wantRaised: []string{"frost", "storm"},
+			wantActive: []string{"frost", "storm"},
+		},
+		{
+			name: "edited rule starts over",
+			rules: map[string]config.Alert{
+				"storm": {When: "wind_kph > 60"},
+				"frost": {When: "temp_c < 0"},
+			},
+			weather:    weatherAt(-5, 80),
+			wantRaised: []string{"storm"},
+			wantActive: []string{"frost", "storm"},
+		},
+	}
+
+	var tracker Tracker
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	for i, step := range steps {
+		raised := tracker.Check(step.rules, step.weather, now.Add(time.Duration(i)*time.Minute))
+		if got := names(raised); !equal(got, step.wantRaised) {
+			t.Errorf("%s: raised %v, want %v", step.name, got, step.wantRaised)
+		}
+		if got := names(tracker.Active()); !equal(got, step.wantActive) {
+			t.Errorf("%s: active %v, want %v", step.name, got, step.wantActive)
+		}
+	}
+}
+
+func TestTrackerKeepsRaisedTime(t *testing.T) {
+	rules := map[string]config.Alert{"frost": {When: "temp_c < 0"}}
+	raisedAt := time.Date(2025, 1, 15, 6, 0, 0, 0, time.UTC)
+
+	var tracker Tracker
+	tracker.Check(rules, weatherAt(-1, 0), raisedAt)
+	tracker.Check(rules, weatherAt(-4, 0), raisedAt.Add(time.Hour))
+
+	active := tracker.Active()
+	if len(active) != 1 {
+		t.Fatalf("got %d active alerts, want 1", len(active))
+	}
+	if a := active[0]; !a.Since.Equal(raisedAt) || a.Value != -4 || a.Location != "Berlin" {
+		t.Errorf("active alert = %+v, want raised at %v with the latest value", a, raisedAt)
+	}
+}
+
+func TestValue(t *testing.T) {
+	c := weather.Current{TempC: -1.5, TempF: 29.3, Humidity: 81, Cloud: 75, UV: 2, IsDay: 1}
+	tests := []struct {
+		field string
+		want  float64
+	}{
+		{"temp_c", -1.5},
+		{"temp_f", 29.3},
+		{"humidity", 81},
+		{"cloud", 75},
+		{"uv", 2},
+		{"is_day", 1},
+		{"snow_cm", 0},
+	}
+	for _, tt := range tests {
+		if got := Value(c, tt.field); got != tt.want {
+			t.Errorf("Value(%q) = %v, want %v", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestActiveMessage(t *testing.T) {
+	a := Active{Name: "frost", Condition: config.AlertCondition{Field: "temp_c", Operator: "<", Threshold: 0}, Value: -1.5}
+	if got, want := a.Message(), "frost: temp_c < 0 (now -1.5)"; got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+}
+
+// equal reports whether two lists of names are the same.
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
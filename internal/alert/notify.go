@@ -0,0 +1,81 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commandTimeout is how long an alert command may run before it is stopped.
+const commandTimeout = 30 * time.Second
+
+// RunCommand runs an alert's command with the shell, passing the alert in
+// the environment as WMS_ALERT_NAME, WMS_ALERT_MESSAGE, WMS_ALERT_FIELD,
+// WMS_ALERT_VALUE and WMS_ALERT_LOCATION. Its output is only used to explain
+// a failure, so it never reaches the terminal.
+func RunCommand(command string, a Active) error {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(),
+		"WMS_ALERT_NAME="+a.Name,
+		"WMS_ALERT_MESSAGE="+a.Message(),
+		"WMS_ALERT_FIELD="+a.Condition.Field,
+		"WMS_ALERT_VALUE="+strconv.FormatFloat(a.Value, 'f', -1, 64),
+		"WMS_ALERT_LOCATION="+a.Location,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if detail := strings.TrimSpace(string(output)); detail != "" {
+			return fmt.Errorf("alert command failed: %w: %s", err, detail)
+		}
+		return fmt.Errorf("alert command failed: %w", err)
+	}
+	return nil
+}
+
+// Notify shows a desktop notification for the alert, with notify-send on
+// Linux and the BSDs and osascript on macOS.
+func Notify(a Active) error {
+	title := "WMS alert: " + a.Name
+	message := a.Message()
+	if a.Location != "" {
+		message += " in " + a.Location
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		return fmt.Errorf("desktop notifications are not supported on Windows, use an alert command instead")
+	default:
+		cmd = exec.Command("notify-send", "--app-name=WMS", title, message)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if detail := strings.TrimSpace(string(output)); detail != "" {
+			return fmt.Errorf("failed to show notification: %w: %s", err, detail)
+		}
+		return fmt.Errorf("failed to show notification: %w", err)
+	}
+	return nil
+}
+
+// appleScriptString quotes text as an AppleScript string literal.
+func appleScriptString(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
@@ -0,0 +1,164 @@
+// Package cache stores the most recent weather observations on disk, so
+// short-lived commands such as status-line integrations can show the weather
+// without calling a weather API on every invocation. Observations are kept
+// per provider, location and units, see Key.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"wms/internal/atomicfile"
+	"wms/internal/config"
+	"wms/internal/weather"
+)
+
+// maxRetryDelay is the longest a fetch is put off after repeated failures.
+const maxRetryDelay = time.Hour
+
+// Entry is a cached weather observation together with the time it was
+// fetched, and the failed attempts to fetch it again since then.
+type Entry struct {
+	Weather        *weather.Weather `json:"weather,omitempty"` // Nil when no fetch has succeeded yet
+	LocationSource string           `json:"location_source,omitempty"`
+	FetchedAt      time.Time        `json:"fetched_at"`
+
+	Failures    int       `json:"failures,omitempty"`     // Failed fetches since the last successful one
+	LastAttempt time.Time `json:"last_attempt,omitempty"` // When the last failed fetch was made
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// Age returns how long ago the cached weather was fetched.
+func (e *Entry) Age() time.Duration {
+	return time.Since(e.FetchedAt)
+}
+
+// RetryAt returns when the weather should be fetched again, given the refresh
+// interval: once the cached weather is older than the interval, and after a
+// failure, once the interval has passed since the failed attempt, doubling
+// with every further failure up to maxRetryDelay.
+func (e *Entry) RetryAt(interval time.Duration) time.Time {
+	next := e.FetchedAt.Add(interval)
+	if e.Failures == 0 {
+		return next
+	}
+	delay := interval
+	for i := 1; i < e.Failures && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if retry := e.LastAttempt.Add(min(delay, maxRetryDelay)); retry.After(next) {
+		return retry
+	}
+	return next
+}
+
+// file is the layout of the cache file: the entries by key.
+type file struct {
+	Entries map[string]*Entry `json:"entries"`
+}
+
+// Key identifies the cached weather for a configuration: the provider, the
+// location and the units. Detected locations are not resolved here, since
+// that would need a network request, so all IP-detected weather shares one
+// key, and so does all GPS weather.
+func Key(cfg config.Config) string {
+	location := cfg.LocationMode
+	if cfg.LocationMode == "manual" && cfg.Location != "" {
+		location = "manual:" + strings.ToLower(strings.TrimSpace(cfg.Location))
+	}
+	return strings.Join([]string{cfg.WeatherProvider, location, cfg.Units}, "|")
+}
+
+// GetCachePath determines the path of the weather cache file in the XDG cache
+// directory.
+func GetCachePath() string {
+	dir := config.GetCacheDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "weather.json")
+}
+
+// Load reads the cached entry for the configuration. The entry may record
+// failed attempts without any weather yet.
+func Load(cfg config.Config) (*Entry, error) {
+	f, err := read()
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := f.Entries[Key(cfg)]
+	if !ok {
+		return nil, fmt.Errorf("no cached weather for this location yet")
+	}
+	return entry, nil
+}
+
+// Save writes the weather fetched for the configuration to the cache,
+// clearing any recorded failures.
+func Save(cfg config.Config, w *weather.Weather, locationSource string) error {
+	return update(cfg, func(*Entry) *Entry {
+		return &Entry{Weather: w, LocationSource: locationSource, FetchedAt: time.Now()}
+	})
+}
+
+// RecordFailure remembers a failed fetch for the configuration, so the next
+// attempt can be put off, see Entry.RetryAt. Cached weather is kept.
+func RecordFailure(cfg config.Config, fetchErr error) error {
+	return update(cfg, func(entry *Entry) *Entry {
+		if entry == nil {
+			entry = &Entry{}
+		}
+		entry.Failures++
+		entry.LastAttempt = time.Now()
+		entry.LastError = fetchErr.Error()
+		return entry
+	})
+}
+
+// read reads the cache file. A missing or unreadable file, such as one from
+// an older version, is an empty cache.
+func read() (file, error) {
+	cachePath := GetCachePath()
+	if cachePath == "" {
+		return file{}, fmt.Errorf("could not determine cache path")
+	}
+
+	var f file
+	if data, err := os.ReadFile(cachePath); err == nil {
+		json.Unmarshal(data, &f)
+	}
+	if f.Entries == nil {
+		f.Entries = make(map[string]*Entry)
+	}
+	return f, nil
+}
+
+// update replaces the entry of the configuration with the result of change,
+// which gets the current entry or nil. The file is written to a temporary
+// path and renamed into place, so concurrent readers never see a partial
+// file.
+func update(cfg config.Config, change func(*Entry) *Entry) error {
+	f, err := read()
+	if err != nil {
+		return err
+	}
+	key := Key(cfg)
+	f.Entries[key] = change(f.Entries[key])
+
+	cachePath := GetCachePath()
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+	if err := atomicfile.Write(cachePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
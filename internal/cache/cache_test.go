@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"wms/internal/config"
+	"wms/internal/weather"
+)
+
+// weatherIn returns weather observed at the named place.
+func weatherIn(name string) *weather.Weather {
+	w := &weather.Weather{}
+	w.Location.Name = name
+	return w
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want string
+	}{
+		{
+			name: "manual location",
+			cfg:  config.Config{WeatherProvider: "weatherapi", LocationMode: "manual", Location: " Tokyo ", Units: "metric"},
+			want: "weatherapi|manual:tokyo|metric",
+		},
+		{
+			name: "detected location ignores the configured one",
+			cfg:  config.Config{WeatherProvider: "open-meteo", LocationMode: "auto", Location: "Tokyo", Units: "imperial"},
+			want: "open-meteo|auto|imperial",
+		},
+		{
+			name: "manual mode without a location",
+			cfg:  config.Config{WeatherProvider: "weatherapi", LocationMode: "manual", Units: "metric"},
+			want: "weatherapi|manual|metric",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Key(tt.cfg); got != tt.want {
+				t.Errorf("Key() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveAndLoadPerLocation(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	tokyo := config.Config{WeatherProvider: "weatherapi", LocationMode: "manual", Location: "Tokyo", Units: "metric"}
+	paris := tokyo
+	paris.Location = "Paris"
+	imperial := tokyo
+	imperial.Units = "imperial"
+
+	if err := Save(tokyo, weatherIn("Tokyo"), "manual"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := Save(paris, weatherIn("Paris"), "manual"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	entry, err := Load(tokyo)
+	if err != nil {
+		t.Fatalf("Load(tokyo) error = %v", err)
+	}
+	if entry.Weather.Location.Name != "Tokyo" {
+		t.Errorf("Load(tokyo) = %q, want Tokyo", entry.Weather.Location.Name)
+	}
+	if _, err := Load(imperial); err == nil {
+		t.Error("Load() with other units found an entry, want none")
+	}
+}
+
+func TestRecordFailureKeepsWeather(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cfg := config.Config{WeatherProvider: "weatherapi", LocationMode: "auto", Units: "metric"}
+
+	if err := RecordFailure(cfg, errors.New("offline")); err != nil {
+		t.Fatalf("RecordFailure() error = %v", err)
+	}
+	entry, err := Load(cfg)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if entry.Weather != nil || entry.Failures != 1 || entry.LastError != "offline" {
+		t.Errorf("entry after a failure = %+v, want no weather and one failure", entry)
+	}
+
+	Save(cfg, &weather.Weather{}, "ip")
+	RecordFailure(cfg, errors.New("timeout"))
+	RecordFailure(cfg, errors.New("timeout"))
+	entry, _ = Load(cfg)
+	if entry.Weather == nil || entry.Failures != 2 {
+		t.Errorf("entry after two failures = %+v, want weather and two failures", entry)
+	}
+}
+
+func TestRetryAt(t *testing.T) {
+	fetched := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	interval := 10 * time.Minute
+	tests := []struct {
+		name        string
+		failures    int
+		lastAttempt time.Time
+		want        time.Time
+	}{
+		{"no failures", 0, time.Time{}, fetched.Add(interval)},
+		{"first failure waits one interval", 1, fetched.Add(30 * time.Minute), fetched.Add(40 * time.Minute)},
+		{"second failure doubles", 2, fetched.Add(30 * time.Minute), fetched.Add(50 * time.Minute)},
+		{"third failure doubles again", 3, fetched.Add(30 * time.Minute), fetched.Add(70 * time.Minute)},
+		{"capped at an hour", 20, fetched.Add(30 * time.Minute), fetched.Add(90 * time.Minute)},
+		{"never before the interval", 1, fetched, fetched.Add(interval)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{FetchedAt: fetched, Failures: tt.failures, LastAttempt: tt.lastAttempt}
+			if got := e.RetryAt(interval); !got.Equal(tt.want) {
+				t.Errorf("RetryAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
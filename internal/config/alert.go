@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Alert is a threshold rule stored as [alerts.NAME] in wms.toml, e.g.
+//
+//	[alerts.frost]
+//	when = "temp_c < 0"
+//	hysteresis = 1
+//	notify = true
+//	command = "logger -t wms \"$WMS_ALERT_MESSAGE\""
+//
+// The rule is checked against the current weather after every refresh. It is
+// raised once when its condition starts to hold and cleared when the value has
+// moved back past the threshold by Hysteresis, so a value hovering around the
+// threshold does not raise it again and again.
+type Alert struct {
+	When       string  `toml:"when"`       // Condition such as "wind_kph > 50", see ParseAlertCondition
+	Hysteresis float64 `toml:"hysteresis"` // How far back past the threshold the value must go to clear the alert
+	Notify     bool    `toml:"notify"`     // Whether to show a desktop notification when raised
+	Command    string  `toml:"command"`    // Shell command to run when raised, empty for none
+}
+
+// AlertFields lists the values alert conditions can compare, named like the
+// current conditions in "wms now -format json".
+var AlertFields = []string{
+	"temp_c", "temp_f", "feelslike_c", "feelslike_f",
+	"wind_kph", "wind_mph", "humidity", "precip_mm",
+	"pressure_mb", "cloud", "vis_km", "uv", "is_day",
+}
+
+// alertOperators lists the comparisons of alert conditions. Two-character
+// operators come first, so "<=" is not taken for "<".
+var alertOperators = []string{"<=", ">=", "==", "!=", "<", ">"}
+
+// AlertCondition is a parsed alert condition: a field compared with a
+// threshold.
+type AlertCondition struct {
+	Field     string // One of AlertFields
+	Operator  string // "<", "<=", ">", ">=", "==" or "!="
+	Threshold float64
+}
+
+// String formats the condition the way it is written in wms.toml.
+func (c AlertCondition) String() string {
+	return fmt.Sprintf("%s %s %s", c.Field, c.Operator, strconv.FormatFloat(c.Threshold, 'f', -1, 64))
+}
+
+// ParseAlertCondition parses a condition of the form "FIELD OPERATOR NUMBER",
+// such as "uv >= 8". The spaces are optional.
+func ParseAlertCondition(when string) (AlertCondition, error) {
+	for _, op := range alertOperators {
+		field, threshold, found := strings.Cut(when, op)
+		if !found {
+			continue
+		}
+		c := AlertCondition{Field: strings.TrimSpace(field), Operator: op}
+		if !isAlertField(c.Field) {
+			return c, fmt.Errorf("unknown field %q (available: %s)", c.Field, strings.Join(AlertFields, ", "))
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(threshold), 64)
+		if err != nil {
+			return c, fmt.Errorf("threshold %q is not a number", strings.TrimSpace(threshold))
+		}
+		c.Threshold = value
+		return c, nil
+	}
+	return AlertCondition{}, fmt.Errorf("%q is not a condition such as \"temp_c < 0\" (operators: %s)", when, strings.Join(alertOperators, " "))
+}
+
+// isAlertField reports whether name is one of AlertFields.
+func isAlertField(name string) bool {
+	for _, field := range AlertFields {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// AlertNames returns the names of the configured alerts in alphabetical
+// order.
+func (c Config) AlertNames() []string {
+	names := make([]string, 0, len(c.Alerts))
+	for name := range c.Alerts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateAlerts checks the conditions of the alerts. Alerts whose condition
+// cannot be parsed are dropped; a negative hysteresis is reset to none. The
+// remaining alerts go into a new map, since the map of the given
+// configuration may be shared with copies of it.
+func validateAlerts(config *Config) []Issue {
+	var issues []Issue
+	var alerts map[string]Alert
+	if config.Alerts != nil {
+		alerts = make(map[string]Alert, len(config.Alerts))
+	}
+	for _, name := range config.AlertNames() {
+		alert := config.Alerts[name]
+		if _, err := ParseAlertCondition(alert.When); err != nil {
+			issues = append(issues, Issue{
+				Key:      "alerts." + name + ".when",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("%v, ignoring the alert", err),
+			})
+			continue
+		}
+		if alert.Hysteresis < 0 {
+			issues = append(issues, Issue{
+				Key:      "alerts." + name + ".hysteresis",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("invalid value %q, using 0", fmt.Sprint(alert.Hysteresis)),
+			})
+			alert.Hysteresis = 0
+		}
+		alerts[name] = alert
+	}
+	config.Alerts = alerts
+	return issues
+}
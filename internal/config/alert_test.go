@@ -0,0 +1,82 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateAlertsKeepsSharedMap(t *testing.T) {
+	config := DefaultConfig()
+	config.Alerts = map[string]Alert{
+		"frost":  {When: "temp_c < 0", Hysteresis: -1},
+		"broken": {When: "temp_c <"},
+	}
+	shared := config // A copy of the configuration sharing the map
+
+	issues := validateAlerts(&config)
+	if len(issues) != 2 {
+		t.Errorf("got %d issues, want 2: %v", len(issues), issues)
+	}
+	if len(shared.Alerts) != 2 || shared.Alerts["frost"].Hysteresis != -1 {
+		t.Errorf("validation changed the shared alerts: %v", shared.Alerts)
+	}
+	if _, ok := config.Alerts["broken"]; ok || config.Alerts["frost"].Hysteresis != 0 {
+		t.Errorf("validated alerts = %v, want only frost without hysteresis", config.Alerts)
+	}
+}
+
+func TestParseAlertCondition(t *testing.T) {
+	tests := []struct {
+		when    string
+		want    AlertCondition
+		wantErr string
+	}{
+		{when: "temp_c < 0", want: AlertCondition{Field: "temp_c", Operator: "<", Threshold: 0}},
+		{when: "uv>=8", want: AlertCondition{Field: "uv", Operator: ">=", Threshold: 8}},
+		{when: " wind_kph > 50.5 ", want: AlertCondition{Field: "wind_kph", Operator: ">", Threshold: 50.5}},
+		{when: "temp_c <= -10", want: AlertCondition{Field: "temp_c", Operator: "<=", Threshold: -10}},
+		{when: "is_day == 0", want: AlertCondition{Field: "is_day", Operator: "==", Threshold: 0}},
+		{when: "cloud != 100", want: AlertCondition{Field: "cloud", Operator: "!=", Threshold: 100}},
+		{when: "snow_cm > 5", wantErr: `unknown field "snow_cm"`},
+		{when: "temp_c < freezing", wantErr: `threshold "freezing" is not a number`},
+		{when: "temp_c <", wantErr: `threshold "" is not a number`},
+		{when: "temp_c is cold", wantErr: "is not a condition"},
+		{when: "", wantErr: "is not a condition"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.when, func(t *testing.T) {
+			got, err := ParseAlertCondition(tt.when)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseAlertCondition(%q) error = %v, want one containing %q", tt.when, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAlertCondition(%q) error = %v", tt.when, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAlertCondition(%q) = %+v, want %+v", tt.when, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertConditionString(t *testing.T) {
+	tests := []struct {
+		c    AlertCondition
+		want string
+	}{
+		{AlertCondition{Field: "temp_c", Operator: "<", Threshold: 0}, "temp_c < 0"},
+		{AlertCondition{Field: "wind_kph", Operator: ">=", Threshold: 50.5}, "wind_kph >= 50.5"},
+		{AlertCondition{Field: "temp_f", Operator: "<=", Threshold: -4}, "temp_f <= -4"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+		if parsed, err := ParseAlertCondition(tt.c.String()); err != nil || parsed != tt.c {
+			t.Errorf("ParseAlertCondition(%q) = %+v, %v, want %+v", tt.want, parsed, err, tt.c)
+		}
+	}
+}
@@ -1,40 +1,97 @@
 // Package config handles the configuration management for the WMS application.
 // It supports loading settings from a TOML file and overriding them with
-// command-line flags. It also manages environment variables for API keys.
+// environment variables and command-line flags, in that order of precedence.
+// It also manages environment variables for API keys.
 package config
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
+	"reflect"
 	"strings"
+	"sync"
+
+	"wms/internal/atomicfile"
 
 	"github.com/BurntSushi/toml"
 	"github.com/joho/godotenv"
 )
 
 // Config holds all the user-configurable settings for the application.
-// Tags are used to map fields to the TOML configuration file.
+// Tags are used to map fields to the TOML configuration file. Every tagged
+// field can also be set with a WMS_* environment variable derived from its
+// key; the optional "env" tag lists additional variable names.
 type Config struct {
 	// Weather settings
-	WeatherProvider string `toml:"weather_provider"` // The weather API provider to use (e.g., "WeatherAPI")
-	Location        string `toml:"location"`         // The default location for weather data
-	LocationMode    string `toml:"location_mode"`    // How the location is determined ("ip" or "manual")
+	WeatherProvider string   `toml:"weather_provider" env:"WMS_PROVIDER"` // The weather API provider to use (e.g., "WeatherAPI")
+	Location        string   `toml:"location"`                            // The default location for weather data
+	LocationMode    string   `toml:"location_mode"`                       // How the location is determined ("ip", "manual" or "gps")
+	Locations       []string `toml:"locations"`                           // Saved locations shown side-by-side in the Locations tab
+
+	// IP geolocation settings, used when LocationMode is "ip"
+	IPGeoProviders []string       `toml:"ip_geo_providers"` // Geolocation backends to try, in order
+	StaticLocation StaticLocation `toml:"static_location"`  // Fixed location returned by the "static" backend
+
+	// GPS settings, used when LocationMode is "gps"
+	GPSDAddress      string  `toml:"gpsd_address"`        // Address of the gpsd daemon
+	GPSMinDistanceKm float64 `toml:"gps_min_distance_km"` // How far the position must move before refetching
 
 	// Display settings
 	Units        string `toml:"units"`          // The unit system for temperature and speed ("metric" or "imperial")
 	TimeFormat   string `toml:"time_format"`    // The time format ("12" or "24")
 	UseColors    bool   `toml:"use_colors"`     // Whether to use colors in the TUI
 	Compact      bool   `toml:"compact"`        // Whether to use a compact display mode
+	Layout       string `toml:"layout"`         // How the panels are arranged ("tabs" or "dashboard")
+	Theme        string `toml:"theme"`          // Color theme: "auto", a built-in theme or a name from Themes
 	ShowCityName bool   `toml:"show_city_name"` // Whether to show the city name in the display
 
+	// Custom color themes, stored as [themes.NAME]
+	Themes map[string]CustomTheme `toml:"themes"`
+
 	// Update settings
 	RefreshInterval int `toml:"refresh_interval"` // The refresh interval in minutes
 
+	// Recording of observations, stored as [history]
+	History History `toml:"history"`
+
+	// Threshold rules checked after every refresh, stored as [alerts.NAME]
+	Alerts map[string]Alert `toml:"alerts"`
+
+	// Profile settings
+	Profile  string             `toml:"profile"`  // Name of the profile to apply, empty for none
+	Profiles map[string]Profile `toml:"profiles"` // Named sets of overrides, stored as [profiles.NAME]
+
 	// API Keys are loaded from a .env file and are not stored in the TOML config.
 	WeatherAPIKey string `toml:"-"`
+	IPInfoToken   string `toml:"-"` // Optional token that raises the ipinfo.io rate limit
+}
+
+// StaticLocation is a fixed position used in place of an IP lookup, for
+// example when a VPN makes the IP address resolve to the wrong city.
+type StaticLocation struct {
+	Name      string  `toml:"name"`
+	Latitude  float64 `toml:"latitude"`
+	Longitude float64 `toml:"longitude"`
+	Timezone  string  `toml:"timezone"`
+}
+
+// History controls the recorder that keeps every successful observation in
+// the state directory, so conditions can be looked back on with "wms history".
+type History struct {
+	Enabled       bool `toml:"enabled"`        // Whether observations are recorded
+	RetentionDays int  `toml:"retention_days"` // How long observations are kept, 0 keeps them forever
+}
+
+// DefaultRetentionDays is how long recorded observations are kept unless
+// configured otherwise.
+const DefaultRetentionDays = 90
+
+// IsSet reports whether a static location has been configured.
+func (s StaticLocation) IsSet() bool {
+	return s.Latitude != 0 || s.Longitude != 0
 }
 
 // Flags represents the command-line flags that can be used to override the configuration.
@@ -44,8 +101,12 @@ type Flags struct {
 	Units           string
 	TimeFormat      string
 	Compact         bool
-	Help            bool
 	RefreshInterval int
+	Profile         string // Profile from [profiles.NAME] to apply
+	ConfigPath      string // Config file to use instead of the default one
+
+	fs              *flag.FlagSet // The flag set the flags were registered on
+	profileSelected bool          // A profile was picked at runtime, see WithProfile
 }
 
 // Constants for the supported weather providers.
@@ -55,275 +116,440 @@ const (
 	ProviderIPGeo      = "IPGeolocation"
 )
 
+// Constants for the supported panel layouts.
+const (
+	LayoutTabs      = "tabs"      // One panel at a time, switched with tabs
+	LayoutDashboard = "dashboard" // Weather, moon and solar panels together
+)
+
+// Constants for the supported IP geolocation backends. ProviderIPGeo names the
+// chain as a whole; these name the individual services within it.
+const (
+	IPGeoStatic  = "static"
+	IPGeoIPInfo  = "ipinfo"
+	IPGeoIPAPICo = "ipapi.co"
+	IPGeoIPAPI   = "ip-api"
+)
+
+// DefaultIPGeoProviders lists the geolocation backends in the order they are
+// tried by default. The HTTPS services come first; ip-api.com only offers
+// plain HTTP on its free tier.
+func DefaultIPGeoProviders() []string {
+	return []string{IPGeoStatic, IPGeoIPInfo, IPGeoIPAPICo, IPGeoIPAPI}
+}
+
 // DefaultConfig returns a new Config with sensible default values.
 func DefaultConfig() Config {
 	return Config{
-		WeatherProvider: ProviderWeatherAPI,
-		Location:        "", // Empty so IP detection is used
-		LocationMode:    "ip",
-		Units:           "metric",
-		TimeFormat:      "24",
-		UseColors:       true,
-		Compact:         false,
-		ShowCityName:    true,
-		RefreshInterval: 5,
+		WeatherProvider:  ProviderWeatherAPI,
+		Location:         "", // Empty so IP detection is used
+		LocationMode:     "ip",
+		Locations:        []string{},
+		IPGeoProviders:   DefaultIPGeoProviders(),
+		GPSDAddress:      "localhost:2947",
+		GPSMinDistanceKm: 2,
+		Units:            "metric",
+		TimeFormat:       "24",
+		UseColors:        true,
+		Compact:          false,
+		Layout:           LayoutTabs,
+		Theme:            ThemeAuto,
+		ShowCityName:     true,
+		RefreshInterval:  5,
+		History:          History{Enabled: false, RetentionDays: DefaultRetentionDays},
 	}
 }
 
-// GetConfigPath determines the appropriate path for the configuration file based
-// on the user's operating system.
-func GetConfigPath() string {
-	var configDir string
+// dotenvVars remembers which variables LoadEnv set from a .env file, so a
+// later call can update or remove them without touching variables that were
+// set in the real environment. It is guarded by dotenvMu, since the TUI
+// reloads the configuration from the config watcher as well as the UI.
+var (
+	dotenvVars = make(map[string]bool)
+	dotenvMu   sync.Mutex
+)
 
-	if runtime.GOOS == "windows" {
-		dir, err := os.UserConfigDir()
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "Failed to get config directory:", err)
-			dir, err = os.UserHomeDir()
-			if err != nil {
-				fmt.Fprintln(os.Stderr, "Failed to get home directory:", err)
-				return ""
-			}
-			return filepath.Join(dir, "wms", "wms.toml")
-		}
-		configDir = filepath.Join(dir, "wms")
-	} else {
-		dir, err := os.UserHomeDir()
+// LoadEnv loads environment variables from a .env file.
+// It first tries the config directory, then the current directory.
+// It is safe to call even if the file does not exist, and can be called again
+// to pick up changes to the file. Variables already set in the environment
+// take precedence over the file.
+func LoadEnv() {
+	dotenvMu.Lock()
+	defer dotenvMu.Unlock()
+
+	// Try loading from config directory first
+	values, err := godotenv.Read(GetEnvPath())
+	if err != nil {
+		// If not found in config dir, try current directory
+		values, err = godotenv.Read()
 		if err != nil {
-			fmt.Fprintln(os.Stderr, "Failed to get home directory:", err)
-			return ""
+			// It's okay if the .env file doesn't exist, we'll just use env vars
+			values = nil
 		}
-		configDir = filepath.Join(dir, ".config", "wms")
 	}
 
-	return filepath.Join(configDir, "wms.toml")
-}
-
-// ValidateConfig checks the configuration for valid values and sets defaults
-// if any are invalid.
-func ValidateConfig(config *Config) {
-	// Validate weather provider
-	if config.WeatherProvider != ProviderWeatherAPI && config.WeatherProvider != ProviderOpenMeteo {
-		fmt.Fprintln(os.Stderr, "Warning: Invalid weather provider in config. Using 'WeatherAPI' as default.")
-		config.WeatherProvider = ProviderWeatherAPI
+	// Forget values that were removed from the file
+	for name := range dotenvVars {
+		if _, ok := values[name]; !ok {
+			os.Unsetenv(name)
+			delete(dotenvVars, name)
+		}
 	}
 
-	// Validate location mode
-	if config.LocationMode != "ip" && config.LocationMode != "manual" {
-		fmt.Fprintln(os.Stderr, "Warning: Invalid location mode in config. Using 'ip' as default.")
-		config.LocationMode = "ip"
+	for name, value := range values {
+		if _, set := os.LookupEnv(name); set && !dotenvVars[name] {
+			continue
+		}
+		os.Setenv(name, value)
+		dotenvVars[name] = true
 	}
+}
 
-	// Validate units
-	validUnits := map[string]bool{
-		"metric":   true,
-		"imperial": true,
-	}
+// Load builds the effective configuration. Each source overrides the one
+// before it: built-in defaults, the TOML file, environment variables and
+// finally command-line flags. Only flags given explicitly on the command line
+// take part; pass nil to skip flags entirely.
+//
+// Problems are returned as issues instead of being printed. Invalid values are
+// replaced with their defaults, and a file that cannot be parsed is ignored,
+// so the returned configuration is always usable.
+func Load(flags *Flags) (Config, []Issue) {
+	config, _, issues := LoadWithOrigins(flags)
+	return config, issues
+}
 
-	if !validUnits[config.Units] {
-		fmt.Fprintln(os.Stderr, "Warning: Invalid units in config. Using 'metric' as default.")
-		config.Units = "metric"
-	}
+// ReadConfig reads the configuration from the TOML file and the environment
+// without any command-line overrides.
+func ReadConfig() (Config, []Issue) {
+	return Load(nil)
+}
 
-	// Validate time format
-	validTimeFormats := map[string]bool{
-		"12": true,
-		"24": true,
+// ReadConfigFile reads the settings stored in the TOML file on top of the
+// defaults, so keys missing from the file keep their default values. Files
+// from older versions are migrated in memory. A missing file is not an error
+// and yields the defaults; nothing is created on disk. Environment variables
+// are not applied, which makes the result safe to modify and write back. The
+// profile selected in the file is applied, so WriteConfig saves changes to its
+// settings back to the profile. An error is returned if the file cannot be
+// read or parsed.
+func ReadConfigFile() (Config, error) {
+	configPath := GetConfigPath()
+	if configPath == "" {
+		return DefaultConfig(), nil
 	}
 
-	if !validTimeFormats[config.TimeFormat] {
-		fmt.Fprintln(os.Stderr, "Warning: Invalid time format in config. Using '24' as default.")
-		config.TimeFormat = "24"
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return DefaultConfig(), nil
 	}
-
-	// Validate refresh interval
-	if config.RefreshInterval < 1 || config.RefreshInterval > 60 {
-		fmt.Fprintln(os.Stderr, "Warning: Invalid refresh interval in config. Using 5 minutes as default.")
-		config.RefreshInterval = 5
+	if err != nil {
+		return DefaultConfig(), fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Validate API key requirement
-	if config.WeatherProvider == ProviderWeatherAPI && config.WeatherAPIKey == "" {
-		fmt.Fprintln(os.Stderr, "Warning: 'weather_api_key' is required for WeatherAPI provider.")
+	config, _, err := decodeConfig(data)
+	if err != nil {
+		return config, err
 	}
+	applyProfile(&config, nil, make(Origins))
+	return config, nil
 }
 
-// LoadEnv loads environment variables from a .env file.
-// It first tries the config directory, then the current directory.
-// It is safe to call even if the file does not exist.
-func LoadEnv() {
-	// Try loading from config directory first
-	envPath := GetEnvPath()
-	err := godotenv.Load(envPath)
+// decodeConfig migrates a wms.toml document to the current version and
+// decodes it on top of the defaults.
+func decodeConfig(data []byte) (Config, MigrationResult, error) {
+	migrated, result, err := migrate(data)
 	if err != nil {
-		// If not found in config dir, try current directory
-		err = godotenv.Load()
-		if err != nil {
-			// It's okay if the .env file doesn't exist, we'll just use env vars
-		}
+		return DefaultConfig(), result, err
 	}
-}
 
-// ReadConfig reads the configuration from the TOML file. If the file does not
-// exist, it creates a default one. It also loads API keys from the environment.
-func ReadConfig() Config {
-	LoadEnv() // Load .env file first
+	config := DefaultConfig()
+	if err := toml.Unmarshal(migrated, &config); err != nil {
+		return DefaultConfig(), result, err
+	}
+	return config, result, nil
+}
 
+// EnsureConfigFile writes a default config file if none exists yet, so first
+// time users have a file to edit. It reports whether a file was created. Only
+// interactive and editing commands call it; read-only commands never create
+// files.
+func EnsureConfigFile() (bool, error) {
 	configPath := GetConfigPath()
 	if configPath == "" {
-		return DefaultConfig()
+		return false, fmt.Errorf("could not determine config path")
 	}
 
-	// Create directory if it doesn't exist
-	configDir := filepath.Dir(configPath)
-	if _, err := os.Stat(configDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(configDir, 0755); err != nil {
-			fmt.Fprintln(os.Stderr, "Failed to create config directory:", err)
-			return DefaultConfig()
-		}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		return false, nil
 	}
+	if err := WriteConfig(DefaultConfig()); err != nil {
+		return false, fmt.Errorf("failed to write default config: %w", err)
+	}
+	return true, nil
+}
 
-	// Check if config file exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		// Create default config
-		defaultConfig := DefaultConfig()
-		file, err := os.Create(configPath)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "Failed to create config file:", err)
-			return defaultConfig
-		}
-		defer file.Close()
-
-		encoder := toml.NewEncoder(file)
-		if err := encoder.Encode(defaultConfig); err != nil {
-			fmt.Fprintln(os.Stderr, "Failed to write default config:", err)
-			return defaultConfig
-		}
-
-		fmt.Printf("Config created at %s\n", configPath)
-		return defaultConfig
+// ValidateFile checks the TOML file for syntax errors and unknown keys, then
+// validates the configuration it describes together with the environment.
+// Flags are not applied, so every issue refers to the file or the environment.
+// A missing default file is validated as an empty one. An error is returned
+// only if the file cannot be read.
+func ValidateFile() ([]Issue, error) {
+	configPath := GetConfigPath()
+	if configPath == "" {
+		return nil, fmt.Errorf("could not determine config path")
 	}
 
-	// Read existing config
-	var config Config
 	data, err := os.ReadFile(configPath)
+	if err != nil && !(os.IsNotExist(err) && !isConfigPathExplicit()) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	migrated, result, err := migrate(data)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to read config file:", err)
-		return DefaultConfig()
+		return []Issue{parseIssue(err)}, nil
 	}
 
-	if err := toml.Unmarshal(data, &config); err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to parse config file, using defaults.", err)
-		return DefaultConfig()
+	fromFile := DefaultConfig()
+	meta, err := toml.Decode(string(migrated), &fromFile)
+	if err != nil {
+		return []Issue{parseIssue(err)}, nil
+	}
+	issues := unknownKeyIssues(meta, data)
+	if issue := versionIssue(result); issue != nil {
+		issues = append(issues, *issue)
 	}
 
-	// Load API keys from environment variables and clean them
-	config.WeatherAPIKey = os.Getenv("WEATHER_API_KEY")
-	// Remove quotes, brackets, and trim whitespace from API key
-	config.WeatherAPIKey = strings.TrimSpace(config.WeatherAPIKey)
-	config.WeatherAPIKey = strings.Trim(config.WeatherAPIKey, "\"'[]")
+	LoadEnv()
+	applySecrets(result)
+	_, _, resolved := resolve(fromFile, data, nil)
+	issues = append(issues, resolved...)
 
-	// Validate configuration
-	ValidateConfig(&config)
+	return issues, nil
+}
 
-	return config
+// ApplyEnv applies settings from environment variables, overriding any values
+// that were set in the configuration file. Every setting can be overridden by
+// a WMS_* variable named after its TOML key, e.g. WMS_UNITS or
+// WMS_STATIC_LOCATION_LATITUDE. Values that cannot be parsed are ignored and
+// returned as issues.
+func ApplyEnv(config *Config) []Issue {
+	return applyEnv(config, make(Origins))
 }
 
-// ParseFlags parses the command-line flags and returns them in a Flags struct.
-func ParseFlags() Flags {
-	flags := Flags{}
-
-	flag.StringVar(&flags.Location, "location", "", "Location to get weather for")
-	flag.StringVar(&flags.LocationMode, "location-mode", "", "Location mode (ip, manual)")
-	flag.StringVar(&flags.Units, "units", "", "Units (metric, imperial)")
-	flag.StringVar(&flags.TimeFormat, "time", "", "Time format (12, 24)")
-	flag.BoolVar(&flags.Compact, "compact", false, "Compact display mode")
-	flag.BoolVar(&flags.Help, "help", false, "Show help")
-	flag.IntVar(&flags.RefreshInterval, "refresh", 0, "Refresh interval in minutes")
-
-	// Add usage information
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
-		fmt.Fprintln(os.Stderr, "Weather Management System (WMS) - A comprehensive weather dashboard")
-		fmt.Fprintln(os.Stderr, "\nOptions:")
-		flag.PrintDefaults()
-		fmt.Fprintln(os.Stderr, "\nConfig file is located at:", GetConfigPath())
-		fmt.Fprintln(os.Stderr, "\nKeyboard shortcuts:")
-		fmt.Fprintln(os.Stderr, "  [1-3] - Switch between Weather/Moon/Solar tabs")
-		fmt.Fprintln(os.Stderr, "  [Tab/Shift+Tab] - Navigate tabs")
-		fmt.Fprintln(os.Stderr, "  [U] - Cycle units/time (Metric 24h → Metric 12h → Imperial 24h → Imperial 12h)")
-		fmt.Fprintln(os.Stderr, "  [T] - Toggle time format only")
-		fmt.Fprintln(os.Stderr, "  [R] - Refresh data")
-		fmt.Fprintln(os.Stderr, "  [S] - Settings menu")
-		fmt.Fprintln(os.Stderr, "  [Q] - Quit")
-	}
-
-	flag.Parse()
-
-	if flags.Help {
-		flag.Usage()
-		os.Exit(0)
-	}
+// cleanSecret removes quotes, brackets, and surrounding whitespace that often
+// sneak into pasted API keys.
+func cleanSecret(value string) string {
+	value = strings.TrimSpace(value)
+	return strings.Trim(value, "\"'[]")
+}
+
+// RegisterFlags defines the configuration flags on the given flag set, so that
+// every command accepts the same overrides.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{fs: fs}
+
+	fs.StringVar(&flags.Location, "location", "", "Location to get weather for (implies -location-mode manual)")
+	fs.StringVar(&flags.LocationMode, "location-mode", "", "Location mode (ip, manual, gps)")
+	fs.StringVar(&flags.Units, "units", "", "Units (metric, imperial)")
+	fs.StringVar(&flags.TimeFormat, "time", "", "Time format (12, 24)")
+	fs.BoolVar(&flags.Compact, "compact", false, "Compact display mode")
+	fs.IntVar(&flags.RefreshInterval, "refresh", 0, "Refresh interval in minutes")
+	fs.StringVar(&flags.Profile, "profile", "", "Profile to apply, from [profiles.NAME] in the config file")
+	fs.StringVar(&flags.ConfigPath, "config", "", "Config file to use (default $"+EnvConfigPath+" or wms.toml in the XDG config directory)")
 
 	return flags
 }
 
+// WithProfile returns a copy of the flags that switches to another profile,
+// or to none with an empty name. The choice overrides the -profile flag and
+// every other source. The flags themselves are left unchanged, so they can
+// still be used by a concurrent Load.
+func (f *Flags) WithProfile(name string) *Flags {
+	selected := f.Clone()
+	selected.Profile = name
+	selected.profileSelected = true
+	return selected
+}
+
+// Clone returns a copy of the flags, or empty flags for nil. The copy shares
+// the parsed flag set, which is only read after parsing.
+func (f *Flags) Clone() *Flags {
+	if f == nil {
+		return &Flags{}
+	}
+	clone := *f
+	return &clone
+}
+
+// isSet reports whether the named flag was given on the command line.
+func (f *Flags) isSet(name string) bool {
+	if name == "profile" && f.profileSelected {
+		return true
+	}
+	if f.fs == nil {
+		return false
+	}
+	set := false
+	f.fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
 // ApplyFlags applies the command-line flags to the Config struct, overriding
-// any values that were set in the configuration file.
-func ApplyFlags(config *Config, flags Flags) {
-	if flags.Location != "" {
+// any values that were set in the configuration file or environment. Flags
+// that were not given explicitly leave the configuration untouched.
+func ApplyFlags(config *Config, flags *Flags) {
+	if flags.isSet("location") {
 		config.Location = flags.Location
+		// An explicit location only makes sense in manual mode
+		if !flags.isSet("location-mode") {
+			config.LocationMode = "manual"
+		}
 	}
-	if flags.LocationMode != "" {
+	if flags.isSet("location-mode") {
 		config.LocationMode = flags.LocationMode
-		ValidateConfig(config)
 	}
-	if flags.Units != "" {
+	if flags.isSet("units") {
 		config.Units = flags.Units
-		ValidateConfig(config)
 	}
-	if flags.TimeFormat != "" {
+	if flags.isSet("time") {
 		config.TimeFormat = flags.TimeFormat
-		ValidateConfig(config)
 	}
-	if flags.Compact {
-		config.Compact = true
+	if flags.isSet("compact") {
+		config.Compact = flags.Compact
 	}
-	if flags.RefreshInterval > 0 {
+	if flags.isSet("refresh") {
 		config.RefreshInterval = flags.RefreshInterval
-		ValidateConfig(config)
+	}
+	if flags.isSet("profile") {
+		config.Profile = flags.Profile
 	}
 }
 
 // WriteConfig saves the provided Config struct to the TOML configuration file.
+// An existing file is updated in place: only settings whose value changed are
+// rewritten, and comments, formatting and unknown keys are kept. A file from an
+// older version is migrated first, keeping a backup. Settings missing from the
+// file are only added when they differ from the default. Settings overridden
+// by the active profile are saved to that profile instead.
 func WriteConfig(config Config) error {
 	configPath := GetConfigPath()
 	if configPath == "" {
 		return fmt.Errorf("could not determine config path")
 	}
 
-	file, err := os.Create(configPath)
+	if _, err := MigrateConfigFile(); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return writeConfigFile(configPath, newConfigFile(config))
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create config file: %w", err)
+		return fmt.Errorf("failed to read config file: %w", err)
 	}
-	defer file.Close()
 
-	encoder := toml.NewEncoder(file)
-	if err := encoder.Encode(config); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
+	existing := DefaultConfig()
+	if err := toml.Unmarshal(data, &existing); err != nil {
+		return fmt.Errorf("failed to parse config file, fix it before saving: %w", err)
 	}
 
-	return nil
+	doc := parseDocument(data)
+	defaults := DefaultConfig()
+	overrides := existing.Profiles[config.Profile].settings()
+	for _, f := range fields() {
+		value, _ := GetField(config, f.key)
+		reflected := reflect.ValueOf(config).FieldByIndex(f.index).Interface()
+
+		// Keep the shared value intact, so switching profiles restores it
+		if override, ok := overrides[f.key]; ok && config.Profile != "" {
+			if value == override {
+				continue
+			}
+			if err := doc.set(profileKey(config.Profile, f.key), reflected); err != nil {
+				return fmt.Errorf("failed to write config: %w", err)
+			}
+			continue
+		}
+
+		old, _ := GetField(existing, f.key)
+		def, _ := GetField(defaults, f.key)
+
+		present := doc.has(f.key)
+		if (present && value == old) || (!present && value == def) {
+			continue
+		}
+		if err := doc.set(f.key, reflected); err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
+		}
+	}
+
+	return writeConfigFile(configPath, doc.bytes())
 }
 
-// GetEnvPath determines the appropriate path for the .env file based on the config directory.
-func GetEnvPath() string {
-	configPath := GetConfigPath()
-	if configPath == "" {
-		return ".env"
+// SaveSettings writes the settings with the given keys from config to the
+// TOML file. Every other setting keeps the value stored in the file, so values
+// that came from environment variables or flags are never persisted. This is
+// what the TUI uses to save the settings that were changed in it.
+func SaveSettings(config Config, keys []string) error {
+	stored := DefaultConfig()
+	if configPath := GetConfigPath(); configPath != "" {
+		data, err := os.ReadFile(configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err == nil {
+			if stored, _, err = decodeConfig(data); err != nil {
+				return fmt.Errorf("failed to parse config file, fix it before saving: %w", err)
+			}
+		}
+	}
+
+	// A newly selected profile must be applied before the other settings,
+	// so the ones it overrides are saved to it rather than to the file
+	for _, key := range keys {
+		if key == "profile" {
+			stored.Profile = config.Profile
+		}
+	}
+	applyProfile(&stored, nil, make(Origins))
+
+	for _, key := range keys {
+		value, err := GetField(config, key)
+		if err != nil {
+			return err
+		}
+		if err := SetField(&stored, key, value); err != nil {
+			return err
+		}
 	}
-	configDir := filepath.Dir(configPath)
-	return filepath.Join(configDir, ".env")
+	return WriteConfig(stored)
+}
+
+// newConfigFile renders a complete config file listing every setting, used
+// when there is no file to update yet.
+func newConfigFile(config Config) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "version = %d\n\n", CurrentVersion)
+	toml.NewEncoder(&buf).Encode(config)
+	return buf.Bytes()
+}
+
+// writeConfigFile atomically replaces the config file with the given content,
+// creating the config directory if needed. The file keeps its permissions.
+func writeConfigFile(configPath string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := atomicfile.Write(configPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
 }
 
 // SaveAPIKey saves the API key to a .env file in the config directory.
@@ -331,65 +557,65 @@ func GetEnvPath() string {
 // Returns the cleaned API key.
 func SaveAPIKey(apiKey string) (string, error) {
 	// Clean the API key - remove quotes, brackets, and trim whitespace
-	apiKey = strings.TrimSpace(apiKey)
-	apiKey = strings.Trim(apiKey, "\"'[]")
+	apiKey = cleanSecret(apiKey)
+
+	if err := saveEnvValue("WEATHER_API_KEY", apiKey); err != nil {
+		return "", err
+	}
+	return apiKey, nil
+}
 
+// saveEnvValue sets a variable in the .env file in the config directory. The
+// file is updated line by line, so the order of the other variables and any
+// comments are kept, and it is replaced atomically. A new file is created with
+// owner-only permissions; an existing one keeps its permissions.
+func saveEnvValue(name, value string) error {
 	envPath := GetEnvPath()
 
 	// Ensure the directory exists
 	configDir := filepath.Dir(envPath)
 	if _, err := os.Stat(configDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(configDir, 0700); err != nil {
-			return "", fmt.Errorf("failed to create config directory: %w", err)
+			return fmt.Errorf("failed to create config directory: %w", err)
 		}
 	}
 
-	// Read existing .env file if it exists
-	envVars := make(map[string]string)
-	if data, err := os.ReadFile(envPath); err == nil {
-		// Parse existing env file
-		lines := string(data)
-		for _, line := range strings.Split(lines, "\n") {
-			line = strings.TrimSpace(line)
-			if line == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				value := strings.TrimSpace(parts[1])
-				envVars[key] = value
-			}
-		}
+	// Start new files with a warning not to commit them
+	lines := []string{
+		"# WMS Environment Variables",
+		"# This file contains sensitive API keys - do not commit to version control",
+		"",
 	}
-
-	// Update or add the API key
-	envVars["WEATHER_API_KEY"] = apiKey
-
-	// Write back to file with secure permissions (0600 = read/write for owner only)
-	file, err := os.OpenFile(envPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
-	if err != nil {
-		return "", fmt.Errorf("failed to open .env file: %w", err)
+	data, err := os.ReadFile(envPath)
+	if err == nil {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read .env file: %w", err)
 	}
-	defer file.Close()
 
-	// Write header comment
-	_, err = file.WriteString("# WMS Environment Variables\n")
-	if err != nil {
-		return "", fmt.Errorf("failed to write to .env file: %w", err)
+	// Update the variable where it is, or add it at the end
+	found := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		prefix := ""
+		if strings.HasPrefix(trimmed, "export ") {
+			prefix = "export "
+			trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "export "))
+		}
+		key, _, ok := strings.Cut(trimmed, "=")
+		if !ok || strings.HasPrefix(trimmed, "#") || strings.TrimSpace(key) != name {
+			continue
+		}
+		lines[i] = prefix + name + "=" + value
+		found = true
 	}
-	_, err = file.WriteString("# This file contains sensitive API keys - do not commit to version control\n\n")
-	if err != nil {
-		return "", fmt.Errorf("failed to write to .env file: %w", err)
+	if !found {
+		lines = append(lines, name+"="+value)
 	}
 
-	// Write all environment variables
-	for key, value := range envVars {
-		_, err = file.WriteString(fmt.Sprintf("%s=%s\n", key, value))
-		if err != nil {
-			return "", fmt.Errorf("failed to write to .env file: %w", err)
-		}
+	// 0600 = read/write for owner only
+	if err := atomicfile.Write(envPath, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write .env file: %w", err)
 	}
-
-	return apiKey, nil
+	return nil
 }
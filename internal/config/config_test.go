@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSaveSettingsKeepsEnvironmentOutOfFile(t *testing.T) {
+	dir := useTempConfigDir(t)
+	path := filepath.Join(dir, "wms.toml")
+	writeFile(t, path, "version = 2\n# Display\nunits = \"metric\"\n")
+	t.Setenv("WMS_UNITS", "imperial")
+	t.Setenv("WMS_REFRESH_INTERVAL", "30")
+
+	cfg, _ := ReadConfig()
+	if cfg.Units != "imperial" || cfg.RefreshInterval != 30 {
+		t.Fatalf("environment not applied: units %q, refresh %d", cfg.Units, cfg.RefreshInterval)
+	}
+	cfg.Theme = ThemeLight
+	if err := SaveSettings(cfg, []string{"theme"}); err != nil {
+		t.Fatalf("SaveSettings: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{"# Display", `units = "metric"`, `theme = "light"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("wms.toml lacks %s:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"imperial", "refresh_interval"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("wms.toml picked up %s from the environment:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestSaveSettingsProfile(t *testing.T) {
+	dir := useTempConfigDir(t)
+	path := filepath.Join(dir, "wms.toml")
+	writeFile(t, path, "version = 2\nunits = \"metric\"\n\n[profiles.travel]\nunits = \"imperial\"\n")
+
+	cfg, _ := ReadConfig()
+	cfg.Profile = "travel"
+	cfg.Units = "imperial"
+	if err := SaveSettings(cfg, []string{"profile", "units"}); err != nil {
+		t.Fatalf("SaveSettings: %v", err)
+	}
+
+	stored, err := ReadConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Profile != "travel" || stored.Units != "imperial" {
+		t.Errorf("stored profile %q units %q, want travel and imperial", stored.Profile, stored.Units)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "units = \"metric\"\n") {
+		t.Errorf("the shared units were overwritten:\n%s", data)
+	}
+}
+
+func TestWithProfileLeavesFlagsUnchanged(t *testing.T) {
+	flags := &Flags{Units: "metric"}
+	travel := flags.WithProfile("travel")
+	if flags.Profile != "" || flags.isSet("profile") {
+		t.Errorf("WithProfile changed the original flags: %+v", flags)
+	}
+	if travel.Profile != "travel" || !travel.isSet("profile") || travel.Units != "metric" {
+		t.Errorf("WithProfile() = %+v, want the travel profile selected", travel)
+	}
+}
+
+func TestLoadConcurrently(t *testing.T) {
+	dir := useTempConfigDir(t)
+	writeFile(t, filepath.Join(dir, "wms.toml"), "version = 2\n\n[profiles.travel]\nunits = \"imperial\"\n")
+	writeFile(t, filepath.Join(dir, ".env"), "WEATHER_API_KEY=from-dotenv\n")
+	t.Cleanup(func() {
+		os.Unsetenv("WEATHER_API_KEY")
+		dotenvMu.Lock()
+		clear(dotenvVars)
+		dotenvMu.Unlock()
+	})
+
+	flags := &Flags{}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			profile := ""
+			if i%2 == 0 {
+				profile = "travel"
+			}
+			cfg, _ := Load(flags.WithProfile(profile))
+			if cfg.Profile != profile || cfg.WeatherAPIKey != "from-dotenv" {
+				t.Errorf("Load() = profile %q key %q, want profile %q and the .env key", cfg.Profile, cfg.WeatherAPIKey, profile)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestEnvLocationImpliesManualMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		wantMode string
+	}{
+		{"location only", map[string]string{"WMS_LOCATION": "Berlin"}, "manual"},
+		{"explicit mode wins", map[string]string{"WMS_LOCATION": "Berlin", "WMS_LOCATION_MODE": "ip"}, "ip"},
+		{"no location", map[string]string{"WMS_UNITS": "imperial"}, "gps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := useTempConfigDir(t)
+			writeFile(t, filepath.Join(dir, "wms.toml"), "version = 2\nlocation_mode = \"gps\"\n")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, origins, _ := LoadWithOrigins(nil)
+			if cfg.LocationMode != tt.wantMode {
+				t.Errorf("location mode = %q, want %q", cfg.LocationMode, tt.wantMode)
+			}
+			if tt.wantMode != "gps" && origins["location_mode"].Source != SourceEnv {
+				t.Errorf("location mode origin = %v, want the environment", origins["location_mode"])
+			}
+		})
+	}
+}
@@ -0,0 +1,546 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// document is a TOML file kept as plain lines, so individual settings can be
+// changed without losing the user's comments, formatting or keys WMS does not
+// know about. It understands just enough TOML to find "key = value" entries,
+// including values that span several lines, dotted keys and keys inside inline
+// tables such as static_location = { latitude = 1.5, longitude = 2.5 }.
+type document struct {
+	lines []string
+}
+
+// entry is a "key = value" pair found in a document.
+type entry struct {
+	key   string // Full dotted key, including the table it is in
+	table string // The [table] the entry is in, empty for the top level
+	start int    // First line of the entry
+	end   int    // One past the last line of the entry
+}
+
+// parseDocument splits a TOML file into lines.
+func parseDocument(data []byte) *document {
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return &document{}
+	}
+	return &document{lines: strings.Split(text, "\n")}
+}
+
+// bytes joins the lines into a file ending with a newline.
+func (d *document) bytes() []byte {
+	if len(d.lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(d.lines, "\n") + "\n")
+}
+
+// entries returns every key/value pair in the document in file order.
+func (d *document) entries() []entry {
+	var entries []entry
+	table := ""
+	header := ""
+
+	for i := 0; i < len(d.lines); i++ {
+		code, _ := splitComment(d.lines[i])
+		code = strings.TrimSpace(code)
+		switch {
+		case code == "":
+			continue
+		case strings.HasPrefix(code, "["):
+			header = strings.TrimSpace(strings.Trim(code, "[]"))
+			table = header + "."
+			continue
+		}
+
+		name, value, found := strings.Cut(code, "=")
+		if !found {
+			continue
+		}
+
+		// Follow values that continue on the next lines, such as arrays
+		// split one item per line or multi-line strings
+		end := i + 1
+		state := scanValue(value, valueState{})
+		for state.open() && end < len(d.lines) {
+			state = scanValue(d.lines[end], state)
+			end++
+		}
+
+		entries = append(entries, entry{key: table + unquoteKey(name), table: header, start: i, end: end})
+		i = end - 1
+	}
+	return entries
+}
+
+// find returns the entry for the given dotted key.
+func (d *document) find(key string) (entry, bool) {
+	for _, e := range d.entries() {
+		if e.key == key {
+			return e, true
+		}
+	}
+	return entry{}, false
+}
+
+// parent returns the entry whose value holds key, such as
+// static_location = { latitude = 1.5 } for "static_location.latitude".
+func (d *document) parent(key string) (entry, bool) {
+	for _, e := range d.entries() {
+		if strings.HasPrefix(key, e.key+".") {
+			return e, true
+		}
+	}
+	return entry{}, false
+}
+
+// has reports whether the document sets a key, either in its own entry or
+// inside an inline table.
+func (d *document) has(key string) bool {
+	if _, ok := d.find(key); ok {
+		return true
+	}
+	e, ok := d.parent(key)
+	if !ok {
+		return false
+	}
+	table, err := d.inlineTable(e)
+	if err != nil {
+		return false
+	}
+	_, found := table.lookup(strings.Split(strings.TrimPrefix(key, e.key+"."), "."))
+	return found
+}
+
+// set changes the value of a key, encoding it as TOML. An existing entry is
+// replaced in place and keeps its trailing comment, and keys inside an inline
+// table are changed within it. A new key is added at the end of its table,
+// next to its siblings when the table is made of dotted keys, and otherwise
+// under a new [table] header if needed.
+func (d *document) set(key string, value interface{}) error {
+	encoded, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	table, name := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		table, name = key[:i], key[i+1:]
+	}
+
+	if e, ok := d.find(key); ok {
+		d.rewrite(e, encoded)
+		return nil
+	}
+
+	if e, ok := d.parent(key); ok {
+		inline, err := d.inlineTable(e)
+		if err != nil {
+			return fmt.Errorf("cannot set %s: %w", key, err)
+		}
+		if err := inline.set(strings.Split(strings.TrimPrefix(key, e.key+"."), "."), value); err != nil {
+			return fmt.Errorf("cannot set %s: %w", key, err)
+		}
+		encoded, err := inline.encode()
+		if err != nil {
+			return fmt.Errorf("cannot set %s: %w", key, err)
+		}
+		d.rewrite(e, encoded)
+		return nil
+	}
+
+	if _, header := d.tables()[table]; !header && table != "" {
+		// A table made of dotted keys, such as static_location.latitude = 1.5,
+		// must not get a [static_location] header as well
+		if e, ok := d.lastUnder(table); ok {
+			line := strings.TrimPrefix(key, e.table+".") + " = " + encoded
+			d.insert(e.end, line)
+			return nil
+		}
+	}
+
+	line := name + " = " + encoded
+	if table == "" {
+		// Top-level keys must come before the first table
+		d.insert(d.tableStart(""), line)
+		return nil
+	}
+	if _, exists := d.tables()[table]; !exists {
+		if len(d.lines) > 0 && strings.TrimSpace(d.lines[len(d.lines)-1]) != "" {
+			d.lines = append(d.lines, "")
+		}
+		d.lines = append(d.lines, "["+table+"]")
+	}
+	d.insert(d.tableStart(table), line)
+	return nil
+}
+
+// rewrite replaces the value of an entry with an encoded one, keeping the
+// indentation, the spelling of the key and the trailing comment.
+func (d *document) rewrite(e entry, encoded string) {
+	first := d.lines[e.start]
+	indent := first[:len(first)-len(strings.TrimLeft(first, " \t"))]
+	keyText, _, _ := strings.Cut(strings.TrimSpace(first), "=")
+	_, comment := splitComment(d.lines[e.end-1])
+
+	line := indent + strings.TrimSpace(keyText) + " = " + encoded
+	if comment != "" {
+		line += " " + comment
+	}
+	d.replace(e.start, e.end, line)
+}
+
+// lastUnder returns the last entry whose key is inside the given table.
+func (d *document) lastUnder(table string) (entry, bool) {
+	var last entry
+	found := false
+	for _, e := range d.entries() {
+		if strings.HasPrefix(e.key, table+".") {
+			last, found = e, true
+		}
+	}
+	return last, found
+}
+
+// prepend adds a line at the very top of the document.
+func (d *document) prepend(line string) {
+	d.lines = append([]string{line}, d.lines...)
+}
+
+// delete removes a key and its value, also from inside an inline table. It
+// reports whether the key existed.
+func (d *document) delete(key string) bool {
+	if e, ok := d.find(key); ok {
+		d.replace(e.start, e.end)
+		return true
+	}
+
+	e, ok := d.parent(key)
+	if !ok {
+		return false
+	}
+	inline, err := d.inlineTable(e)
+	if err != nil || !inline.delete(strings.Split(strings.TrimPrefix(key, e.key+"."), ".")) {
+		return false
+	}
+	encoded, err := inline.encode()
+	if err != nil {
+		return false
+	}
+	d.rewrite(e, encoded)
+	return true
+}
+
+// replace swaps the lines in [start, end) for the given ones.
+func (d *document) replace(start, end int, lines ...string) {
+	rest := append(lines, d.lines[end:]...)
+	d.lines = append(d.lines[:start], rest...)
+}
+
+// insert adds a line before the given index.
+func (d *document) insert(at int, line string) {
+	d.replace(at, at, line)
+}
+
+// tables maps the name of every [table] header to its line.
+func (d *document) tables() map[string]int {
+	tables := make(map[string]int)
+	for i, line := range d.lines {
+		code, _ := splitComment(line)
+		code = strings.TrimSpace(code)
+		if strings.HasPrefix(code, "[") && !strings.HasPrefix(code, "[[") {
+			tables[strings.TrimSpace(strings.Trim(code, "[]"))] = i
+		}
+	}
+	return tables
+}
+
+// tableStart returns the line after the last entry of a table, where a new key
+// can be added. For the top-level table it is the line after the last entry
+// before the first header, so comments belonging to the header stay with it.
+func (d *document) tableStart(table string) int {
+	prefix := ""
+	if table != "" {
+		prefix = table + "."
+	}
+
+	at := -1
+	for _, e := range d.entries() {
+		if strings.HasPrefix(e.key, prefix) && !strings.Contains(e.key[len(prefix):], ".") {
+			at = e.end
+		}
+	}
+	if at >= 0 {
+		return at
+	}
+
+	// An empty table: right after its header, or at the top of the file
+	if line, ok := d.tables()[table]; ok {
+		return line + 1
+	}
+	return 0
+}
+
+// valueState tracks brackets and multi-line strings while scanning a value.
+type valueState struct {
+	depth     int    // Open [ or { brackets
+	multiline string // The delimiter of an open multi-line string, if any
+}
+
+// open reports whether the value continues on the next line.
+func (s valueState) open() bool {
+	return s.depth > 0 || s.multiline != ""
+}
+
+// scanValue advances the state over one line of a value, ignoring brackets
+// inside strings and anything after a comment.
+func scanValue(line string, state valueState) valueState {
+	for i := 0; i < len(line); i++ {
+		if state.multiline != "" {
+			if strings.HasPrefix(line[i:], state.multiline) {
+				i += len(state.multiline) - 1
+				state.multiline = ""
+			}
+			continue
+		}
+
+		switch c := line[i]; c {
+		case '#':
+			return state
+		case '[', '{':
+			state.depth++
+		case ']', '}':
+			state.depth--
+		case '"', '\'':
+			delimiter := string(c)
+			if strings.HasPrefix(line[i:], strings.Repeat(delimiter, 3)) {
+				delimiter = strings.Repeat(delimiter, 3)
+			}
+			closing := indexUnescaped(line[i+len(delimiter):], delimiter, c == '"')
+			if closing < 0 {
+				if len(delimiter) == 3 {
+					state.multiline = delimiter
+				}
+				return state
+			}
+			i += len(delimiter) + closing + len(delimiter) - 1
+		}
+	}
+	return state
+}
+
+// indexUnescaped finds the first delimiter in s that is not escaped with a
+// backslash. Literal strings have no escapes.
+func indexUnescaped(s, delimiter string, escapes bool) int {
+	for i := 0; i < len(s); i++ {
+		if escapes && s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], delimiter) {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitComment splits a line into its code and its trailing "# comment".
+func splitComment(line string) (string, string) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return strings.TrimRight(line[:i], " \t"), line[i:]
+		}
+	}
+	return line, ""
+}
+
+// unquoteKey normalizes a bare or quoted key, e.g. ` "units" ` to "units".
+func unquoteKey(key string) string {
+	var parts []string
+	for _, part := range strings.Split(strings.TrimSpace(key), ".") {
+		parts = append(parts, strings.Trim(strings.TrimSpace(part), `"'`))
+	}
+	return strings.Join(parts, ".")
+}
+
+// encodeValue formats a single value as TOML.
+func encodeValue(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(map[string]interface{}{"v": value}); err != nil {
+		return "", fmt.Errorf("failed to encode value: %w", err)
+	}
+	if !strings.HasPrefix(buf.String(), "v = ") {
+		return "", fmt.Errorf("failed to encode value: %v cannot be written on one line", value)
+	}
+	return strings.TrimSpace(strings.TrimPrefix(buf.String(), "v = ")), nil
+}
+
+// inlineTable is the decoded value of an inline table entry, with its keys in
+// the order they were written.
+type inlineTable struct {
+	values map[string]interface{}
+	order  []toml.Key // Keys below the table, relative to it
+}
+
+// inlineTable decodes the value of an entry that is an inline table.
+func (d *document) inlineTable(e entry) (*inlineTable, error) {
+	first, _ := splitComment(d.lines[e.start])
+	_, value, _ := strings.Cut(first, "=")
+	text := strings.Join(append([]string{"v = " + value}, d.lines[e.start+1:e.end]...), "\n")
+
+	var decoded map[string]interface{}
+	meta, err := toml.Decode(text, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", e.key, err)
+	}
+	values, ok := decoded["v"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is a %T, not a table", e.key, decoded["v"])
+	}
+
+	t := &inlineTable{values: values}
+	for _, key := range meta.Keys() {
+		if len(key) > 1 {
+			t.order = append(t.order, key[1:])
+		}
+	}
+	return t, nil
+}
+
+// lookup returns the value at a path of keys.
+func (t *inlineTable) lookup(path []string) (interface{}, bool) {
+	var value interface{} = t.values
+	for _, name := range path {
+		table, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = table[name]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// set changes the value at a path of keys, creating nested tables as needed.
+func (t *inlineTable) set(path []string, value interface{}) error {
+	table := t.values
+	for i, name := range path[:len(path)-1] {
+		next, exists := table[name]
+		if !exists {
+			next = make(map[string]interface{})
+			table[name] = next
+			t.order = append(t.order, append(toml.Key{}, path[:i+1]...))
+		}
+		nested, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is a %T, not a table", strings.Join(path[:i+1], "."), next)
+		}
+		table = nested
+	}
+
+	name := path[len(path)-1]
+	if _, exists := table[name]; !exists {
+		t.order = append(t.order, append(toml.Key{}, path...))
+	}
+	table[name] = value
+	return nil
+}
+
+// delete removes the value at a path of keys and reports whether it existed.
+func (t *inlineTable) delete(path []string) bool {
+	parent, ok := t.lookup(path[:len(path)-1])
+	if !ok {
+		return false
+	}
+	table, ok := parent.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if _, ok := table[path[len(path)-1]]; !ok {
+		return false
+	}
+	delete(table, path[len(path)-1])
+	return true
+}
+
+// encode formats the table as a one-line inline table.
+func (t *inlineTable) encode() (string, error) {
+	return t.encodeTable(t.values, nil)
+}
+
+// encodeTable formats one level of the table, keeping the written order of
+// its keys. Keys the order does not know come last, alphabetically.
+func (t *inlineTable) encodeTable(table map[string]interface{}, path toml.Key) (string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, key := range t.order {
+		if len(key) != len(path)+1 || key[:len(path)].String() != path.String() {
+			continue
+		}
+		name := key[len(key)-1]
+		if _, ok := table[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+	var rest []string
+	for name := range table {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	names = append(names, rest...)
+
+	if len(names) == 0 {
+		return "{}", nil
+	}
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		var encoded string
+		var err error
+		if nested, ok := table[name].(map[string]interface{}); ok {
+			encoded, err = t.encodeTable(nested, append(append(toml.Key{}, path...), name))
+		} else {
+			encoded, err = encodeValue(table[name])
+		}
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, quoteKey(name)+" = "+encoded)
+	}
+	return "{ " + strings.Join(parts, ", ") + " }", nil
+}
+
+// bareKey matches keys that need no quotes in TOML.
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// quoteKey formats a single key, quoting it if needed.
+func quoteKey(name string) string {
+	if bareKey.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDocumentSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		key   string
+		value interface{}
+		want  string
+	}{
+		{
+			name:  "replace keeps the comment and indentation",
+			in:    "# Display\n  units = \"metric\" # or imperial\n",
+			key:   "units",
+			value: "imperial",
+			want:  "# Display\n  units = \"imperial\" # or imperial\n",
+		},
+		{
+			name:  "quoted key",
+			in:    "\"units\" = \"metric\"\n",
+			key:   "units",
+			value: "imperial",
+			want:  "\"units\" = \"imperial\"\n",
+		},
+		{
+			name:  "new top-level key goes before the first table",
+			in:    "units = \"metric\"\n\n# History\n[history]\nenabled = true\n",
+			key:   "theme",
+			value: "dark",
+			want:  "units = \"metric\"\ntheme = \"dark\"\n\n# History\n[history]\nenabled = true\n",
+		},
+		{
+			name:  "new key in an existing table",
+			in:    "[history]\nenabled = true\n\n[themes.ocean]\nbase = \"dark\"\n",
+			key:   "history.retention_days",
+			value: 30,
+			want:  "[history]\nenabled = true\nretention_days = 30\n\n[themes.ocean]\nbase = \"dark\"\n",
+		},
+		{
+			name:  "new table",
+			in:    "units = \"metric\"\n",
+			key:   "history.enabled",
+			value: true,
+			want:  "units = \"metric\"\n\n[history]\nenabled = true\n",
+		},
+		{
+			name:  "multi-line array is replaced as a whole",
+			in:    "locations = [\n  \"Tokyo\",\n  \"Berlin\", # Home\n]\nunits = \"metric\"\n",
+			key:   "locations",
+			value: []string{"Paris"},
+			want:  "locations = [\"Paris\"]\nunits = \"metric\"\n",
+		},
+		{
+			name:  "brackets and hashes in strings",
+			in:    "location = \"[x] #1\" # note\nunits = \"metric\"\n",
+			key:   "units",
+			value: "imperial",
+			want:  "location = \"[x] #1\" # note\nunits = \"imperial\"\n",
+		},
+		{
+			name:  "empty document",
+			in:    "",
+			key:   "units",
+			value: "metric",
+			want:  "units = \"metric\"\n",
+		},
+		{
+			name:  "key in an inline table",
+			in:    "static_location = { latitude = 1.5, longitude = 2.5 } # Home\n",
+			key:   "static_location.latitude",
+			value: 3.25,
+			want:  "static_location = { latitude = 3.25, longitude = 2.5 } # Home\n",
+		},
+		{
+			name:  "new key in an inline table",
+			in:    "static_location = { latitude = 1.5, longitude = 2.5 }\nunits = \"metric\"\n",
+			key:   "static_location.name",
+			value: "Home base",
+			want:  "static_location = { latitude = 1.5, longitude = 2.5, name = \"Home base\" }\nunits = \"metric\"\n",
+		},
+		{
+			name:  "nested inline table",
+			in:    "[themes]\nocean = { base = \"dark\", colors = { accent = \"#00f\" } }\n",
+			key:   "themes.ocean.colors.accent",
+			value: "#0ff",
+			want:  "[themes]\nocean = { base = \"dark\", colors = { accent = \"#0ff\" } }\n",
+		},
+		{
+			name:  "dotted key",
+			in:    "static_location.latitude = 1.5\nstatic_location.longitude = 2.5\n",
+			key:   "static_location.longitude",
+			value: 4.0,
+			want:  "static_location.latitude = 1.5\nstatic_location.longitude = 4.0\n",
+		},
+		{
+			name:  "new key next to dotted keys",
+			in:    "static_location.latitude = 1.5\nunits = \"metric\"\n\n[history]\nenabled = true\n",
+			key:   "static_location.name",
+			value: "Home",
+			want:  "static_location.latitude = 1.5\nstatic_location.name = \"Home\"\nunits = \"metric\"\n\n[history]\nenabled = true\n",
+		},
+		{
+			name:  "new key next to dotted keys in a table",
+			in:    "[themes]\nocean.base = \"dark\"\n",
+			key:   "themes.ocean.accent",
+			value: "#00f",
+			want:  "[themes]\nocean.base = \"dark\"\nocean.accent = \"#00f\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parseDocument([]byte(tt.in))
+			if err := doc.set(tt.key, tt.value); err != nil {
+				t.Fatalf("set() error = %v", err)
+			}
+			if got := string(doc.bytes()); got != tt.want {
+				t.Errorf("set() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentSetRefusesNonTables(t *testing.T) {
+	doc := parseDocument([]byte("static_location = \"home\"\n"))
+	err := doc.set("static_location.latitude", 1.5)
+	if err == nil || !strings.Contains(err.Error(), "not a table") {
+		t.Errorf("set() error = %v, want one saying static_location is not a table", err)
+	}
+	if got := string(doc.bytes()); got != "static_location = \"home\"\n" {
+		t.Errorf("set() changed the document to\n%s", got)
+	}
+}
+
+func TestWriteConfigInlineTable(t *testing.T) {
+	dir := useTempConfigDir(t)
+	path := filepath.Join(dir, "wms.toml")
+	writeFile(t, path, fmt.Sprintf("version = %d\nstatic_location = { latitude = 1.5, longitude = 2.5 }\n", CurrentVersion))
+
+	config, err := ReadConfigFile()
+	if err != nil {
+		t.Fatalf("ReadConfigFile() error = %v", err)
+	}
+	config.StaticLocation.Latitude = 3.5
+	config.Units = "imperial"
+	if err := WriteConfig(config); err != nil {
+		t.Fatalf("WriteConfig() error = %v", err)
+	}
+
+	reloaded, err := ReadConfigFile()
+	if err != nil {
+		data, _ := os.ReadFile(path)
+		t.Fatalf("ReadConfigFile() after saving error = %v\n%s", err, data)
+	}
+	if reloaded.StaticLocation.Latitude != 3.5 || reloaded.StaticLocation.Longitude != 2.5 || reloaded.Units != "imperial" {
+		t.Errorf("reloaded config = %+v, want latitude 3.5, longitude 2.5 and imperial units", reloaded)
+	}
+	if data, _ := os.ReadFile(path); strings.Count(string(data), "static_location") != 1 {
+		t.Errorf("wms.toml =\n%s\nwant the inline table updated in place", data)
+	}
+}
+
+func TestDocumentDelete(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		key       string
+		want      string
+		wantFound bool
+	}{
+		{"top-level key", "units = \"metric\"\ntheme = \"dark\"\n", "units", "theme = \"dark\"\n", true},
+		{"key in a table", "[history]\nenabled = true\nretention_days = 30\n", "history.enabled", "[history]\nretention_days = 30\n", true},
+		{"multi-line string", "note = \"\"\"\nline [\n\"\"\"\nunits = \"metric\"\n", "note", "units = \"metric\"\n", true},
+		{"missing key", "units = \"metric\"\n", "theme", "units = \"metric\"\n", false},
+		{"same name in another table", "[history]\nenabled = true\n", "enabled", "[history]\nenabled = true\n", false},
+		{"key in an inline table", "static_location = { latitude = 1.5, longitude = 2.5 }\n", "static_location.latitude", "static_location = { longitude = 2.5 }\n", true},
+		{"missing key in an inline table", "static_location = { latitude = 1.5 }\n", "static_location.name", "static_location = { latitude = 1.5 }\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parseDocument([]byte(tt.in))
+			if found := doc.delete(tt.key); found != tt.wantFound {
+				t.Errorf("delete() = %v, want %v", found, tt.wantFound)
+			}
+			if got := string(doc.bytes()); got != tt.want {
+				t.Errorf("delete() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveEnvValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // Empty for no file
+		want     string
+	}{
+		{
+			name: "new file",
+			want: "# WMS Environment Variables\n# This file contains sensitive API keys - do not commit to version control\n\nWEATHER_API_KEY=new\n",
+		},
+		{
+			name:     "update in place",
+			existing: "# Keys\nIPINFO_TOKEN=token\nWEATHER_API_KEY=old\nOTHER=1\n",
+			want:     "# Keys\nIPINFO_TOKEN=token\nWEATHER_API_KEY=new\nOTHER=1\n",
+		},
+		{
+			name:     "export prefix is kept",
+			existing: "export WEATHER_API_KEY = old\n",
+			want:     "export WEATHER_API_KEY=new\n",
+		},
+		{
+			name:     "commented out and similar names are left alone",
+			existing: "# WEATHER_API_KEY=example\nWEATHER_API_KEY_OLD=old\n",
+			want:     "# WEATHER_API_KEY=example\nWEATHER_API_KEY_OLD=old\nWEATHER_API_KEY=new\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := useTempConfigDir(t)
+			path := filepath.Join(dir, ".env")
+			if tt.existing != "" {
+				writeFile(t, path, tt.existing)
+				os.Chmod(path, 0640)
+			}
+
+			if err := saveEnvValue("WEATHER_API_KEY", "new"); err != nil {
+				t.Fatalf("saveEnvValue() error = %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf(".env =\n%s\nwant\n%s", data, tt.want)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantPerm := os.FileMode(0600)
+			if tt.existing != "" {
+				wantPerm = 0640
+			}
+			if info.Mode().Perm() != wantPerm {
+				t.Errorf("permissions = %v, want %v", info.Mode().Perm(), wantPerm)
+			}
+		})
+	}
+}
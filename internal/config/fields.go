@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// field is a single configuration setting addressed by its TOML key. Keys of
+// nested tables are joined with dots, e.g. "static_location.latitude".
+type field struct {
+	key     string
+	index   []int    // Path to the struct field, for reflect.Value.FieldByIndex
+	aliases []string // Extra environment variables from the "env" tag
+}
+
+// envNames returns the environment variables that override the setting, in
+// order of precedence. The first one is derived from the key, e.g.
+// "static_location.latitude" becomes WMS_STATIC_LOCATION_LATITUDE.
+func (f field) envNames() []string {
+	name := "WMS_" + strings.ToUpper(strings.ReplaceAll(f.key, ".", "_"))
+	return append([]string{name}, f.aliases...)
+}
+
+// fields lists every setting that can be stored in the TOML file, derived
+// from the struct tags so new settings are picked up automatically.
+func fields() []field {
+	var result []field
+	collectFields(reflect.TypeOf(Config{}), "", nil, &result)
+	return result
+}
+
+// collectFields walks a struct type and appends a field for every tagged
+// setting, descending into nested tables.
+func collectFields(t reflect.Type, prefix string, index []int, result *[]field) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("toml"), ",")[0]
+		if name == "" || name == "-" || !sf.IsExported() {
+			continue
+		}
+
+		key := prefix + name
+		path := append(append([]int{}, index...), i)
+		if sf.Type.Kind() == reflect.Map {
+			continue // Tables of named entries, such as profiles, are not settings
+		}
+		if sf.Type.Kind() == reflect.Struct {
+			collectFields(sf.Type, key+".", path, result)
+			continue
+		}
+		var aliases []string
+		if env := sf.Tag.Get("env"); env != "" {
+			aliases = strings.Split(env, ",")
+		}
+		*result = append(*result, field{key: key, index: path, aliases: aliases})
+	}
+}
+
+// lookupField finds the setting with the given TOML key.
+func lookupField(key string) (field, error) {
+	for _, f := range fields() {
+		if f.key == key {
+			return f, nil
+		}
+	}
+	return field{}, fmt.Errorf("unknown config key %q", key)
+}
+
+// Keys returns the TOML keys of all settings in alphabetical order.
+func Keys() []string {
+	var keys []string
+	for _, f := range fields() {
+		keys = append(keys, f.key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetField returns the value of a setting formatted as a string. Lists are
+// formatted as TOML arrays, e.g. ["Portland, OR", "Tokyo"], which SetField
+// accepts again.
+func GetField(config Config, key string) (string, error) {
+	f, err := lookupField(key)
+	if err != nil {
+		return "", err
+	}
+
+	v := reflect.ValueOf(config).FieldByIndex(f.index)
+	switch v.Kind() {
+	case reflect.Slice:
+		return encodeValue(v.Interface())
+	default:
+		return fmt.Sprint(v.Interface()), nil
+	}
+}
+
+// SetField parses value according to the type of the setting and stores it.
+// Lists are given as TOML arrays such as ["Portland, OR", "Tokyo"], since
+// place names contain commas; any other value is a list of that one item, and
+// an empty value clears the list.
+func SetField(config *Config, key, value string) error {
+	f, err := lookupField(key)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(config).Elem().FieldByIndex(f.index)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false", key)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a whole number", key)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s must be a number", key)
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		items, err := parseList(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a list such as [\"Portland, OR\", \"Tokyo\"]: %w", key, err)
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("%s cannot be set from the command line", key)
+	}
+
+	return nil
+}
+
+// parseList parses the value of a list setting: a TOML array of strings, a
+// single item, or nothing for an empty list.
+func parseList(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(value, "[") {
+		return []string{value}, nil
+	}
+
+	var list struct {
+		Items []string `toml:"items"`
+	}
+	if _, err := toml.Decode("items = "+value, &list); err != nil {
+		return nil, err
+	}
+	items := []string{}
+	for _, item := range list.Items {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetFieldList(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{value: `["Portland, OR", "Tokyo"]`, want: []string{"Portland, OR", "Tokyo"}},
+		{value: `[ 'Berlin' , " Paris " ]`, want: []string{"Berlin", "Paris"}},
+		{value: "Portland, OR", want: []string{"Portland, OR"}},
+		{value: "[]", want: []string{}},
+		{value: "", want: []string{}},
+		{value: `["Tokyo", 5]`, wantErr: true},
+		{value: `["Tokyo"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cfg := DefaultConfig()
+			err := SetField(&cfg, "locations", tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("SetField(%q) = %q, want an error", tt.value, cfg.Locations)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetField(%q) error = %v", tt.value, err)
+			}
+			if strings.Join(cfg.Locations, "|") != strings.Join(tt.want, "|") || len(cfg.Locations) != len(tt.want) {
+				t.Errorf("SetField(%q) = %q, want %q", tt.value, cfg.Locations, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFieldListRoundTrip(t *testing.T) {
+	tests := []struct {
+		locations []string
+		want      string
+	}{
+		{[]string{"Portland, OR", "Tokyo"}, `["Portland, OR", "Tokyo"]`},
+		{[]string{`Say "hi"`}, `["Say \"hi\""]`},
+		{[]string{}, "[]"},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.Locations = tt.locations
+		got, err := GetField(cfg, "locations")
+		if err != nil || got != tt.want {
+			t.Errorf("GetField() = %s, %v, want %s", got, err, tt.want)
+		}
+
+		var parsed Config
+		if err := SetField(&parsed, "locations", got); err != nil || strings.Join(parsed.Locations, "|") != strings.Join(tt.locations, "|") {
+			t.Errorf("SetField(%s) = %q, %v, want %q", got, parsed.Locations, err, tt.locations)
+		}
+	}
+}
+
+func TestEnvListWithComma(t *testing.T) {
+	dir := useTempConfigDir(t)
+	writeFile(t, filepath.Join(dir, "wms.toml"), "version = 2\n")
+	t.Setenv("WMS_LOCATIONS", `["Portland, OR", "Tokyo"]`)
+
+	cfg, origins, issues := LoadWithOrigins(nil)
+	if strings.Join(cfg.Locations, "|") != "Portland, OR|Tokyo" {
+		t.Errorf("locations = %q, want Portland, OR and Tokyo", cfg.Locations)
+	}
+	if origins["locations"].Source != SourceEnv {
+		t.Errorf("locations origin = %v, want the environment", origins["locations"])
+	}
+
+	t.Setenv("WMS_LOCATIONS", `["Portland, OR"`)
+	cfg, _, issues = LoadWithOrigins(nil)
+	found := false
+	for _, issue := range issues {
+		found = found || (issue.Key == "locations" && strings.Contains(issue.Message, "WMS_LOCATIONS"))
+	}
+	if !found || len(cfg.Locations) != 0 {
+		t.Errorf("locations = %q with issues %v, want none and an issue for WMS_LOCATIONS", cfg.Locations, issues)
+	}
+}
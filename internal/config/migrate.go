@@ -0,0 +1,216 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+)
+
+// CurrentVersion is the version of the wms.toml format written by this
+// build. Files without a version key are version 1, the format used by WMS
+// 1.x.
+const CurrentVersion = 2
+
+// migration upgrades a config document to the given version.
+type migration struct {
+	version     int
+	description string
+	apply       func(m *migrationState) error
+}
+
+// migrationState is what a migration works on: the document to edit, its
+// decoded values for reading, and secrets that belong in .env instead.
+type migrationState struct {
+	doc     *document
+	values  map[string]interface{}
+	secrets map[string]string // Environment variable name to value
+}
+
+// migrations lists every upgrade step in order. To change the format, bump
+// CurrentVersion and append a migration to the new version.
+var migrations = []migration{
+	{
+		version:     2,
+		description: "move API keys from wms.toml to .env",
+		apply: func(m *migrationState) error {
+			// The warning for a missing key used to name weather_api_key,
+			// so some users put it into wms.toml, where it was ignored.
+			for key, env := range map[string]string{
+				"weather_api_key": "WEATHER_API_KEY",
+				"ipinfo_token":    "IPINFO_TOKEN",
+			} {
+				if value, ok := m.values[key].(string); ok && value != "" {
+					m.secrets[env] = value
+				}
+				m.doc.delete(key)
+			}
+			return nil
+		},
+	},
+}
+
+// MigrationResult describes an upgrade of wms.toml.
+type MigrationResult struct {
+	From, To int
+	Applied  []string          // Descriptions of the migrations that ran
+	Backup   string            // Copy of the file before migrating, if it was rewritten
+	secrets  map[string]string // Values moved to .env
+}
+
+// Migrated reports whether any migration was necessary.
+func (r MigrationResult) Migrated() bool {
+	return r.From < r.To
+}
+
+// fileVersion returns the version key of a decoded document.
+func fileVersion(values map[string]interface{}) int {
+	if version, ok := values["version"].(int64); ok {
+		return int(version)
+	}
+	return 1
+}
+
+// migrate upgrades a wms.toml document to the current version in memory and
+// stamps the version key. Documents from a newer version are left alone.
+func migrate(data []byte) ([]byte, MigrationResult, error) {
+	values := make(map[string]interface{})
+	if _, err := toml.Decode(string(data), &values); err != nil {
+		return data, MigrationResult{}, err
+	}
+
+	result := MigrationResult{From: fileVersion(values), To: CurrentVersion, secrets: make(map[string]string)}
+	if result.From >= CurrentVersion {
+		result.To = result.From
+		return data, result, nil
+	}
+
+	state := &migrationState{doc: parseDocument(data), values: values, secrets: result.secrets}
+	for _, m := range migrations {
+		if m.version <= result.From {
+			continue
+		}
+		if err := m.apply(state); err != nil {
+			return data, result, fmt.Errorf("failed to migrate config to version %d: %w", m.version, err)
+		}
+		result.Applied = append(result.Applied, m.description)
+	}
+
+	// Keep the version at the top where people look for it
+	state.doc.delete("version")
+	state.doc.prepend(fmt.Sprintf("version = %d", CurrentVersion))
+	return state.doc.bytes(), result, nil
+}
+
+// MigrateConfigFile upgrades wms.toml to the current version on disk. The old
+// file is kept as wms.toml.v<N>.bak and API keys found in it are moved to
+// .env. Nothing happens if the file is missing or already up to date.
+func MigrateConfigFile() (MigrationResult, error) {
+	configPath := GetConfigPath()
+	if configPath == "" {
+		return MigrationResult{}, fmt.Errorf("could not determine config path")
+	}
+
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return MigrationResult{From: CurrentVersion, To: CurrentVersion}, nil
+	}
+	if err != nil {
+		return MigrationResult{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	migrated, result, err := migrate(data)
+	if err != nil || !result.Migrated() {
+		return result, err
+	}
+
+	result.Backup = fmt.Sprintf("%s.v%d.bak", configPath, result.From)
+	if err := os.WriteFile(result.Backup, data, 0600); err != nil {
+		return result, fmt.Errorf("failed to back up config file: %w", err)
+	}
+
+	// Save secrets first, so they are never lost if writing the config fails.
+	// Migration can run before LoadEnv, so .env is read here rather than
+	// relying on the environment.
+	existing, err := godotenv.Read(GetEnvPath())
+	if err != nil && !os.IsNotExist(err) {
+		return result, fmt.Errorf("failed to read .env file: %w", err)
+	}
+	for name, value := range result.secrets {
+		if _, ok := existing[name]; ok || os.Getenv(name) != "" {
+			continue // .env or the environment already has a value, which wins
+		}
+		if err := saveEnvValue(name, value); err != nil {
+			return result, err
+		}
+	}
+
+	if err := writeConfigFile(configPath, migrated); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// applySecrets makes secrets found in an old wms.toml available as if they
+// came from .env, unless the environment already has a value.
+func applySecrets(result MigrationResult) {
+	for name, value := range result.secrets {
+		if os.Getenv(name) == "" {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+// versionIssue returns a warning when the file needs migrating or was written
+// by a newer version of WMS.
+func versionIssue(result MigrationResult) *Issue {
+	switch {
+	case result.Migrated():
+		return &Issue{
+			Key:      "version",
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("config file uses format version %d, run \"wms config migrate\" to upgrade it to %d (%s)", result.From, result.To, strings.Join(result.Applied, ", ")),
+		}
+	case result.From > CurrentVersion:
+		return &Issue{
+			Key:      "version",
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("config file was written by a newer WMS (format version %d), some settings may be ignored", result.From),
+		}
+	}
+	return nil
+}
+
+// salvage removes lines with syntax errors from a document until the rest
+// parses, so one typo does not throw away every other setting. Removed lines
+// are blanked to keep the line numbers of the remaining keys. It returns an
+// issue for every removed line and whether a valid document was reached.
+func salvage(data []byte) ([]byte, []Issue, bool) {
+	doc := parseDocument(data)
+	var issues []Issue
+
+	for attempt := 0; attempt < 20; attempt++ {
+		var values map[string]interface{}
+		_, err := toml.Decode(string(doc.bytes()), &values)
+		if err == nil {
+			return doc.bytes(), issues, true
+		}
+
+		var parseErr toml.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Position.Line < 1 || parseErr.Position.Line > len(doc.lines) {
+			break
+		}
+		line := parseErr.Position.Line
+		issues = append(issues, Issue{
+			Line:     line,
+			Severity: SeverityError,
+			Message:  parseErr.Message + ", ignoring this line",
+		})
+		doc.lines[line-1] = ""
+	}
+
+	return data, issues, false
+}
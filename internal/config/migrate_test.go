@@ -0,0 +1,223 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joho/godotenv"
+)
+
+// useTempConfigDir points the config directory at a fresh temporary
+// directory and clears the variables that migration could pick up from the
+// environment.
+func useTempConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(EnvConfigPath, "")
+	for _, name := range []string{"WEATHER_API_KEY", "IPINFO_TOKEN"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "wms"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "wms")
+}
+
+func TestMigrateConfigFileKeepsExistingEnvKey(t *testing.T) {
+	dir := useTempConfigDir(t)
+	writeFile(t, filepath.Join(dir, ".env"), "WEATHER_API_KEY=GOODKEY\n")
+	writeFile(t, filepath.Join(dir, "wms.toml"), "weather_api_key = \"OLDKEY\"\nipinfo_token = \"TOKEN\"\nunits = \"imperial\"\n")
+
+	result, err := MigrateConfigFile()
+	if err != nil {
+		t.Fatalf("MigrateConfigFile: %v", err)
+	}
+	if !result.Migrated() {
+		t.Fatal("expected the file to be migrated")
+	}
+
+	env, err := godotenv.Read(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := env["WEATHER_API_KEY"]; got != "GOODKEY" {
+		t.Errorf("WEATHER_API_KEY = %q, want the existing GOODKEY", got)
+	}
+	if got := env["IPINFO_TOKEN"]; got != "TOKEN" {
+		t.Errorf("IPINFO_TOKEN = %q, want TOKEN moved from wms.toml", got)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "wms.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"OLDKEY", "TOKEN"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("wms.toml still contains %s:\n%s", secret, data)
+		}
+	}
+	if !strings.HasPrefix(string(data), "version = 2\n") {
+		t.Errorf("wms.toml does not start with the version:\n%s", data)
+	}
+	if _, err := os.Stat(result.Backup); err != nil {
+		t.Errorf("backup missing: %v", err)
+	}
+}
+
+func TestMigrateConfigFileMovesKeyToNewEnv(t *testing.T) {
+	dir := useTempConfigDir(t)
+	writeFile(t, filepath.Join(dir, "wms.toml"), "weather_api_key = \"OLDKEY\"\n")
+
+	if _, err := MigrateConfigFile(); err != nil {
+		t.Fatalf("MigrateConfigFile: %v", err)
+	}
+	env, err := godotenv.Read(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := env["WEATHER_API_KEY"]; got != "OLDKEY" {
+		t.Errorf("WEATHER_API_KEY = %q, want OLDKEY", got)
+	}
+}
+
+func TestMigrateCurrentVersionUnchanged(t *testing.T) {
+	data := []byte("version = 2\nunits = \"metric\"\n")
+	migrated, result, err := migrate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Migrated() || string(migrated) != string(data) {
+		t.Errorf("migrate changed a current file: %+v\n%s", result, migrated)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		want        string
+		wantFrom    int
+		wantSecrets map[string]string
+		wantErr     bool
+	}{
+		{
+			name:     "version 1 without secrets",
+			in:       "# My settings\nunits = \"metric\"\n",
+			want:     "version = 2\n# My settings\nunits = \"metric\"\n",
+			wantFrom: 1,
+		},
+		{
+			name:        "version 1 with API keys",
+			in:          "units = \"metric\"\nweather_api_key = \"abc\" # From the website\nipinfo_token = \"\"\n",
+			want:        "version = 2\nunits = \"metric\"\n",
+			wantFrom:    1,
+			wantSecrets: map[string]string{"WEATHER_API_KEY": "abc"},
+		},
+		{
+			name:     "misplaced version key moves to the top",
+			in:       "units = \"metric\"\nversion = 1\n",
+			want:     "version = 2\nunits = \"metric\"\n",
+			wantFrom: 1,
+		},
+		{
+			name:     "newer version is left alone",
+			in:       "version = 3\nunits = \"metric\"\n",
+			want:     "version = 3\nunits = \"metric\"\n",
+			wantFrom: 3,
+		},
+		{
+			name:    "syntax error",
+			in:      "units = \n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, result, err := migrate([]byte(tt.in))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("migrate() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("migrate() error = %v", err)
+			}
+			if string(migrated) != tt.want {
+				t.Errorf("migrate() =\n%s\nwant\n%s", migrated, tt.want)
+			}
+			if result.From != tt.wantFrom {
+				t.Errorf("From = %d, want %d", result.From, tt.wantFrom)
+			}
+			if len(result.secrets) != len(tt.wantSecrets) {
+				t.Errorf("secrets = %v, want %v", result.secrets, tt.wantSecrets)
+			}
+			for name, value := range tt.wantSecrets {
+				if result.secrets[name] != value {
+					t.Errorf("secret %s = %q, want %q", name, result.secrets[name], value)
+				}
+			}
+		})
+	}
+}
+
+func TestVersionIssue(t *testing.T) {
+	tests := []struct {
+		name   string
+		result MigrationResult
+		want   string
+	}{
+		{"current", MigrationResult{From: 2, To: 2}, ""},
+		{"older", MigrationResult{From: 1, To: 2, Applied: []string{"move API keys"}}, "wms config migrate"},
+		{"newer", MigrationResult{From: 3, To: 3}, "newer WMS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue := versionIssue(tt.result)
+			switch {
+			case tt.want == "" && issue != nil:
+				t.Errorf("versionIssue() = %q, want none", issue)
+			case tt.want != "" && (issue == nil || !strings.Contains(issue.Message, tt.want)):
+				t.Errorf("versionIssue() = %v, want one containing %q", issue, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateConfigFileKeepsBackup(t *testing.T) {
+	dir := useTempConfigDir(t)
+	path := filepath.Join(dir, "wms.toml")
+	original := "units = \"imperial\"\n"
+	writeFile(t, path, original)
+
+	result, err := MigrateConfigFile()
+	if err != nil {
+		t.Fatalf("MigrateConfigFile() error = %v", err)
+	}
+	if result.Backup != path+".v1.bak" {
+		t.Errorf("backup = %q, want %q", result.Backup, path+".v1.bak")
+	}
+	if backup, _ := os.ReadFile(result.Backup); string(backup) != original {
+		t.Errorf("backup = %q, want the original file", backup)
+	}
+	if data, _ := os.ReadFile(path); !strings.HasPrefix(string(data), "version = 2\n") {
+		t.Errorf("wms.toml = %q, want the version stamped", data)
+	}
+
+	// A second run finds nothing to do
+	if result, err := MigrateConfigFile(); err != nil || result.Migrated() || result.Backup != "" {
+		t.Errorf("second MigrateConfigFile() = %+v, %v, want nothing done", result, err)
+	}
+}
+
+// writeFile writes a test file, failing the test on errors.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
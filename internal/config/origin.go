@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// Source names the layer the effective value of a setting came from.
+type Source string
+
+// Sources in increasing order of precedence.
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceProfile Source = "profile"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Origin describes where the effective value of a setting came from.
+type Origin struct {
+	Source   Source
+	Name     string // Path of the config file, profile, environment variable or flag
+	Line     int    // Line in the config file, for SourceFile and SourceProfile
+	Replaced bool   // The value was invalid and validation fell back to the default
+}
+
+// String formats the origin as e.g. "env WMS_UNITS",
+// "file /home/me/.config/wms/wms.toml:3" or "profile travel, line 12".
+func (o Origin) String() string {
+	var text string
+	switch o.Source {
+	case SourceFile:
+		text = fmt.Sprintf("file %s:%d", o.Name, o.Line)
+	case SourceProfile:
+		text = fmt.Sprintf("profile %s, line %d", o.Name, o.Line)
+	case SourceEnv:
+		text = "env " + o.Name
+	case SourceFlag:
+		text = "flag -" + o.Name
+	default:
+		text = string(SourceDefault)
+	}
+	if o.Replaced {
+		text += ", invalid value replaced by default"
+	}
+	return text
+}
+
+// Origins maps the TOML key of every setting to the origin of its value.
+type Origins map[string]Origin
+
+// flagKeys maps the shared command-line flags to the settings they override.
+var flagKeys = map[string]string{
+	"location":      "location",
+	"location-mode": "location_mode",
+	"units":         "units",
+	"time":          "time_format",
+	"compact":       "compact",
+	"refresh":       "refresh_interval",
+	"profile":       "profile",
+}
+
+// LoadWithOrigins works like Load, but also reports where the effective value
+// of every setting came from.
+func LoadWithOrigins(flags *Flags) (Config, Origins, []Issue) {
+	// Set only on the first load, so later reloads from other goroutines
+	// merely read the path
+	if flags != nil && flags.isSet("config") && configPathOverride != flags.ConfigPath {
+		SetConfigPath(flags.ConfigPath)
+	}
+
+	LoadEnv() // Load .env file first
+
+	var issues []Issue
+	fromFile := DefaultConfig()
+	data, err := os.ReadFile(GetConfigPath())
+	switch {
+	case os.IsNotExist(err) && isConfigPathExplicit():
+		// A file the user asked for by name should exist; falling back to
+		// defaults silently would hide a typo.
+		issues = append(issues, Issue{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("config file %s does not exist, using defaults", GetConfigPath()),
+		})
+	case os.IsNotExist(err):
+		// First run, the defaults apply
+	case err != nil:
+		issues = append(issues, Issue{Severity: SeverityError, Message: fmt.Sprintf("failed to read config file: %v", err)})
+	default:
+		config, result, err := decodeConfig(data)
+		if err != nil {
+			// Keep every setting that is still readable instead of falling
+			// back to the defaults for all of them
+			salvaged, salvageIssues, ok := salvage(data)
+			if !ok {
+				issues = append(issues, parseIssue(err))
+				data = nil
+				break
+			}
+			issues = append(issues, salvageIssues...)
+			data = salvaged
+			config, result, _ = decodeConfig(data)
+		}
+
+		fromFile = config
+		applySecrets(result)
+		if issue := versionIssue(result); issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+
+	config, origins, resolved := resolve(fromFile, data, flags)
+	return config, origins, append(issues, resolved...)
+}
+
+// resolve applies the active profile, the environment and flags on top of the
+// settings read from the file, validates the result and tracks the origin of
+// every value. data is the raw file, used to find line numbers.
+func resolve(fromFile Config, data []byte, flags *Flags) (Config, Origins, []Issue) {
+	lines := keyLines(data)
+	origins := make(Origins)
+	for _, f := range fields() {
+		origins[f.key] = Origin{Source: SourceDefault}
+		if line, ok := lines[f.key]; ok {
+			origins[f.key] = Origin{Source: SourceFile, Name: GetConfigPath(), Line: line}
+		}
+	}
+
+	// The profile is picked by the strongest source that names one, but its
+	// settings rank between the file and the environment
+	config := fromFile
+	config.Profile = activeProfile(fromFile, flags)
+	applyProfile(&config, lines, origins)
+	envIssues := applyEnv(&config, origins)
+	if flags != nil {
+		ApplyFlags(&config, flags)
+		for name, key := range flagKeys {
+			if flags.isSet(name) {
+				origins[key] = Origin{Source: SourceFlag, Name: name}
+			}
+		}
+		if flags.isSet("location") && !flags.isSet("location-mode") {
+			origins["location_mode"] = Origin{Source: SourceFlag, Name: "location"}
+		}
+	}
+
+	// Validate configuration and point every issue at the source of the
+	// offending value
+	issues := ValidateConfig(&config)
+	for i, issue := range issues {
+		origin, ok := origins[issue.Key]
+		if !ok {
+			// Keys inside tables of named entries, such as themes, are not
+			// settings, but they still have a line
+			if line, found := lines[issue.Key]; found {
+				issues[i].Line = line
+			}
+			continue
+		}
+
+		if issue.Severity == SeverityError {
+			origin.Replaced = true
+			origins[issue.Key] = origin
+		}
+		switch origin.Source {
+		case SourceFile, SourceProfile:
+			issues[i].Line = origin.Line
+		case SourceEnv, SourceFlag:
+			// Editing the file would not fix these, so say where they came from
+			issues[i].Message += fmt.Sprintf(" (from %s)", Origin{Source: origin.Source, Name: origin.Name})
+		}
+	}
+
+	return config, origins, append(envIssues, issues...)
+}
+
+// applyEnv overrides settings with the WMS_* environment variables and
+// records them in origins. Values that cannot be parsed are ignored and
+// reported as issues. Empty variables count as unset.
+func applyEnv(config *Config, origins Origins) []Issue {
+	var issues []Issue
+	for _, f := range fields() {
+		for _, name := range f.envNames() {
+			value := os.Getenv(name)
+			if value == "" {
+				continue
+			}
+
+			if err := SetField(config, f.key, value); err != nil {
+				issues = append(issues, Issue{
+					Key:      f.key,
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("ignoring %s=%q, %v", name, value, err),
+				})
+			} else {
+				origins[f.key] = Origin{Source: SourceEnv, Name: name}
+			}
+			break
+		}
+	}
+
+	// Like the -location flag, a location from the environment only makes
+	// sense in manual mode, unless the mode is given there as well
+	if location := origins["location"]; location.Source == SourceEnv && origins["location_mode"].Source != SourceEnv {
+		config.LocationMode = "manual"
+		origins["location_mode"] = location
+	}
+
+	// Load API keys from environment variables and clean them
+	config.WeatherAPIKey = cleanSecret(os.Getenv("WEATHER_API_KEY"))
+	config.IPInfoToken = cleanSecret(os.Getenv("IPINFO_TOKEN"))
+
+	return issues
+}
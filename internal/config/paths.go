@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// EnvConfigPath is the environment variable that points WMS at a different
+// config file. The -config flag takes precedence over it.
+const EnvConfigPath = "WMS_CONFIG"
+
+// configPathOverride is the config file given with -config, if any.
+var configPathOverride string
+
+// SetConfigPath makes every following read and write use the given config
+// file instead of the default one. An empty path restores the default.
+func SetConfigPath(path string) {
+	configPathOverride = path
+}
+
+// GetConfigPath determines the path of the configuration file. The -config
+// flag wins over the WMS_CONFIG environment variable, which wins over
+// wms.toml in the config directory.
+func GetConfigPath() string {
+	if configPathOverride != "" {
+		return configPathOverride
+	}
+	if path := os.Getenv(EnvConfigPath); path != "" {
+		return path
+	}
+
+	configDir := GetConfigDir()
+	if configDir == "" {
+		return ""
+	}
+	return filepath.Join(configDir, "wms.toml")
+}
+
+// isConfigPathExplicit reports whether the config file was chosen by the user,
+// in which case a missing file is an error rather than a first run.
+func isConfigPathExplicit() bool {
+	return configPathOverride != "" || os.Getenv(EnvConfigPath) != ""
+}
+
+// GetConfigDir returns the default directory for wms.toml and .env:
+// $XDG_CONFIG_HOME/wms, falling back to ~/.config/wms. On Windows the
+// roaming AppData directory is used.
+func GetConfigDir() string {
+	if runtime.GOOS == "windows" {
+		return windowsDir(os.UserConfigDir)
+	}
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// GetCacheDir returns the directory for data that can be fetched again, such
+// as API responses: $XDG_CACHE_HOME/wms, falling back to ~/.cache/wms. On
+// Windows the local AppData directory is used.
+func GetCacheDir() string {
+	if runtime.GOOS == "windows" {
+		return windowsDir(os.UserCacheDir)
+	}
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+// GetStateDir returns the directory for data that should survive restarts but
+// is not configuration, such as the weather history: $XDG_STATE_HOME/wms,
+// falling back to ~/.local/state/wms. On Windows it lives next to the cache.
+func GetStateDir() string {
+	if runtime.GOOS == "windows" {
+		dir := windowsDir(os.UserCacheDir)
+		if dir == "" {
+			return ""
+		}
+		return filepath.Join(dir, "state")
+	}
+	return xdgDir("XDG_STATE_HOME", ".local", "state")
+}
+
+// xdgDir returns the wms subdirectory of the XDG base directory named by
+// envVar. Following the specification, relative values are ignored and the
+// default below the home directory is used instead.
+func xdgDir(envVar string, fallback ...string) string {
+	if dir := os.Getenv(envVar); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "wms")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to get home directory:", err)
+		return ""
+	}
+	return filepath.Join(append(append([]string{home}, fallback...), "wms")...)
+}
+
+// windowsDir returns the wms subdirectory of a per-user directory, falling
+// back to the home directory.
+func windowsDir(userDir func() (string, error)) string {
+	dir, err := userDir()
+	if err != nil {
+		dir, err = os.UserHomeDir()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to get home directory:", err)
+			return ""
+		}
+	}
+	return filepath.Join(dir, "wms")
+}
+
+// GetEnvPath determines the path of the .env file holding the API keys. It
+// always lives in the default config directory, so secrets are shared by
+// every config file selected with -config or WMS_CONFIG.
+func GetEnvPath() string {
+	configDir := GetConfigDir()
+	if configDir == "" {
+		return ".env"
+	}
+	return filepath.Join(configDir, ".env")
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Profile is a named set of overrides stored as [profiles.NAME] in wms.toml,
+// so one file can serve several machines or situations, e.g.
+//
+//	[profiles.travel]
+//	location_mode = "gps"
+//	units = "imperial"
+//	theme = "light"
+//
+// Empty fields leave the setting from the rest of the file alone.
+type Profile struct {
+	WeatherProvider string `toml:"weather_provider"`
+	Location        string `toml:"location"`
+	LocationMode    string `toml:"location_mode"`
+	Units           string `toml:"units"`
+	Theme           string `toml:"theme"`
+}
+
+// settings returns the settings the profile overrides by TOML key. Like the
+// -location flag, a location without a location mode implies manual mode.
+func (p Profile) settings() map[string]string {
+	settings := make(map[string]string)
+	for key, value := range map[string]string{
+		"weather_provider": p.WeatherProvider,
+		"location":         p.Location,
+		"location_mode":    p.LocationMode,
+		"units":            p.Units,
+		"theme":            p.Theme,
+	} {
+		if value != "" {
+			settings[key] = value
+		}
+	}
+	if p.Location != "" && p.LocationMode == "" {
+		settings["location_mode"] = "manual"
+	}
+	return settings
+}
+
+// ProfileNames returns the names of the configured profiles in alphabetical
+// order.
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileKey returns the dotted key of a setting inside a profile table.
+func profileKey(profile, key string) string {
+	return "profiles." + profile + "." + key
+}
+
+// activeProfile returns the name of the profile to apply. It is chosen by the
+// strongest source that names one: a -profile flag, then WMS_PROFILE, then the
+// profile key in the file.
+func activeProfile(fromFile Config, flags *Flags) string {
+	name := fromFile.Profile
+	if f, err := lookupField("profile"); err == nil {
+		for _, env := range f.envNames() {
+			if value := os.Getenv(env); value != "" {
+				name = value
+				break
+			}
+		}
+	}
+	if flags != nil && flags.isSet("profile") {
+		name = flags.Profile
+	}
+	return name
+}
+
+// applyProfile overrides settings with the values of the active profile and
+// records them in origins. lines maps keys to their lines in the file. An
+// unknown profile is left alone here and reported by ValidateConfig.
+func applyProfile(config *Config, lines map[string]int, origins Origins) {
+	profile, ok := config.Profiles[config.Profile]
+	if !ok {
+		return
+	}
+
+	for key, value := range profile.settings() {
+		if err := SetField(config, key, value); err != nil {
+			continue // Profiles only hold strings, which always fit
+		}
+
+		line, ok := lines[profileKey(config.Profile, key)]
+		if !ok {
+			// An implied location mode points at the location
+			line = lines[profileKey(config.Profile, "location")]
+		}
+		origins[key] = Origin{Source: SourceProfile, Name: config.Profile, Line: line}
+	}
+}
+
+// validateProfile checks that the selected profile exists, falling back to
+// no profile otherwise.
+func validateProfile(config *Config) *Issue {
+	if config.Profile == "" {
+		return nil
+	}
+	if _, ok := config.Profiles[config.Profile]; ok {
+		return nil
+	}
+
+	message := fmt.Sprintf("unknown profile %q, using none", config.Profile)
+	if names := config.ProfileNames(); len(names) > 0 {
+		message += " (available: " + strings.Join(names, ", ") + ")"
+	}
+	config.Profile = ""
+	return &Issue{Key: "profile", Severity: SeverityError, Message: message}
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Constants for the built-in color themes. ThemeAuto picks ThemeDark or
+// ThemeLight from the terminal background.
+const (
+	ThemeAuto         = "auto"
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+	ThemeSolarized    = "solarized"
+)
+
+// BuiltinThemes returns the names of the built-in themes, not counting
+// ThemeAuto.
+func BuiltinThemes() []string {
+	return []string{ThemeDark, ThemeLight, ThemeHighContrast, ThemeSolarized}
+}
+
+// CustomTheme is a color theme stored as [themes.NAME] in wms.toml, e.g.
+//
+//	[themes.ocean]
+//	base = "dark"
+//	primary = "#0EA5E9"
+//	weather = "45"
+//
+// Colors are "#RRGGBB" (or "#RGB") or ANSI color numbers from 0 to 255.
+// Empty colors are taken from the base theme, which defaults to "auto".
+type CustomTheme struct {
+	Base string `toml:"base"`
+
+	Primary   string `toml:"primary"`
+	Secondary string `toml:"secondary"`
+	Success   string `toml:"success"`
+	Warning   string `toml:"warning"`
+	Error     string `toml:"error"`
+	Info      string `toml:"info"`
+
+	Text          string `toml:"text"`
+	TextSecondary string `toml:"text_secondary"`
+	TextMuted     string `toml:"text_muted"`
+	TextInverse   string `toml:"text_inverse"`
+	Border        string `toml:"border"`
+	Subtle        string `toml:"subtle"`
+
+	Weather string `toml:"weather"`
+	Moon    string `toml:"moon"`
+	Sun     string `toml:"sun"`
+	Time    string `toml:"time"`
+
+	IconSun   string `toml:"icon_sun"`
+	IconMoon  string `toml:"icon_moon"`
+	IconCloud string `toml:"icon_cloud"`
+
+	Hot   string `toml:"hot"`
+	Cold  string `toml:"cold"`
+	Storm string `toml:"storm"`
+	Snow  string `toml:"snow"`
+	Rain  string `toml:"rain"`
+	Fog   string `toml:"fog"`
+	Night string `toml:"night"`
+}
+
+// ThemeNames returns the names of every theme that can be selected: auto,
+// the built-in themes and the custom themes in alphabetical order.
+func (c Config) ThemeNames() []string {
+	names := append([]string{ThemeAuto}, BuiltinThemes()...)
+	custom := make([]string, 0, len(c.Themes))
+	for name := range c.Themes {
+		if !isBuiltinTheme(name) {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	return append(names, custom...)
+}
+
+// isBuiltinTheme reports whether name is ThemeAuto or a built-in theme.
+func isBuiltinTheme(name string) bool {
+	if name == ThemeAuto {
+		return true
+	}
+	for _, builtin := range BuiltinThemes() {
+		if name == builtin {
+			return true
+		}
+	}
+	return false
+}
+
+// hexColor matches "#RGB" and "#RRGGBB" colors.
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// isColor reports whether value is a color lipgloss understands: a hex color
+// or an ANSI color number.
+func isColor(value string) bool {
+	if hexColor.MatchString(value) {
+		return true
+	}
+	n, err := strconv.Atoi(value)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// validateThemes checks the selected theme and the colors of the custom
+// themes. An unknown theme falls back to ThemeAuto; invalid colors and bases
+// are cleared, so the base theme fills them in. The corrected themes go into
+// a new map, since the map of the given configuration may be shared with
+// copies of it.
+func validateThemes(config *Config) []Issue {
+	names := make([]string, 0, len(config.Themes))
+	for name := range config.Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var issues []Issue
+	var themes map[string]CustomTheme
+	if config.Themes != nil {
+		themes = make(map[string]CustomTheme, len(config.Themes))
+	}
+	for _, name := range names {
+		theme := config.Themes[name]
+		themes[name] = theme
+		if isBuiltinTheme(name) {
+			issues = append(issues, Issue{
+				Key:      "themes." + name,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("%q is a built-in theme and cannot be redefined, ignoring it", name),
+			})
+			continue
+		}
+
+		if theme.Base != "" && !isBuiltinTheme(theme.Base) {
+			issues = append(issues, Issue{
+				Key:      "themes." + name + ".base",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("unknown base theme %q, using '%s' (available: %s, %s)", theme.Base, ThemeAuto, ThemeAuto, strings.Join(BuiltinThemes(), ", ")),
+			})
+			theme.Base = ""
+		}
+
+		// Every field after Base is a color
+		v := reflect.ValueOf(&theme).Elem()
+		for i := 1; i < v.NumField(); i++ {
+			value := v.Field(i).String()
+			if value == "" || isColor(value) {
+				continue
+			}
+			key := strings.Split(v.Type().Field(i).Tag.Get("toml"), ",")[0]
+			issues = append(issues, Issue{
+				Key:      "themes." + name + "." + key,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("invalid color %q, must be \"#RRGGBB\" or an ANSI color from 0 to 255, using the base theme's", value),
+			})
+			v.Field(i).SetString("")
+		}
+		themes[name] = theme
+	}
+	config.Themes = themes
+
+	if _, custom := config.Themes[config.Theme]; !isBuiltinTheme(config.Theme) && !custom {
+		issues = append(issues, Issue{
+			Key:      "theme",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("unknown theme %q, using '%s' (available: %s)", config.Theme, ThemeAuto, strings.Join(config.ThemeNames(), ", ")),
+		})
+		config.Theme = ThemeAuto
+	}
+	return issues
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateThemesKeepsSharedMap(t *testing.T) {
+	themes := map[string]CustomTheme{
+		"ocean": {Base: "deep", Primary: "#0EA5E9", Weather: "teal"},
+		"dark":  {Primary: "1"},
+	}
+	original := map[string]CustomTheme{}
+	for name, theme := range themes {
+		original[name] = theme
+	}
+	config := DefaultConfig()
+	config.Themes = themes
+	shared := config // A copy of the configuration sharing the map
+
+	issues := validateThemes(&config)
+	if len(issues) != 3 {
+		t.Errorf("got %d issues, want 3 for the base, the color and the built-in name: %v", len(issues), issues)
+	}
+	if !reflect.DeepEqual(shared.Themes, original) {
+		t.Errorf("validation changed the shared themes: %v", shared.Themes)
+	}
+	want := CustomTheme{Primary: "#0EA5E9"}
+	if got := config.Themes["ocean"]; got != want {
+		t.Errorf("validated theme = %+v, want %+v", got, want)
+	}
+}
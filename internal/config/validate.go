@@ -0,0 +1,243 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Severity tells how serious a configuration issue is.
+type Severity int
+
+const (
+	// SeverityWarning marks issues WMS can work around, such as unknown keys
+	// or a missing API key.
+	SeverityWarning Severity = iota
+	// SeverityError marks invalid values. WMS falls back to the default value
+	// for the setting, but the file should be fixed.
+	SeverityError
+)
+
+// String returns the lowercase name of the severity.
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Issue is a single problem found while loading or validating the
+// configuration.
+type Issue struct {
+	Key      string // TOML key of the offending setting, empty if not tied to one
+	Line     int    // Line in wms.toml, 0 if the value did not come from the file
+	Severity Severity
+	Message  string
+}
+
+// String formats the issue as "line 3: units: invalid value ...", leaving out
+// the parts that are unknown.
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", i.Line)
+	}
+	if i.Key != "" {
+		b.WriteString(i.Key + ": ")
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// HasErrors reports whether any of the issues is an error.
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateConfig checks the configuration for valid values and sets defaults
+// if any are invalid. It returns an issue for every problem it found; nothing
+// is printed, so it is safe to call while the TUI owns the terminal.
+func ValidateConfig(config *Config) []Issue {
+	var issues []Issue
+	invalid := func(key string, value interface{}, fallback string) {
+		issues = append(issues, Issue{
+			Key:      key,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("invalid value %q, using %s", fmt.Sprint(value), fallback),
+		})
+	}
+
+	// Validate the profile first, its settings were already applied
+	if issue := validateProfile(config); issue != nil {
+		issues = append(issues, *issue)
+	}
+
+	// Validate weather provider
+	if config.WeatherProvider != ProviderWeatherAPI && config.WeatherProvider != ProviderOpenMeteo {
+		invalid("weather_provider", config.WeatherProvider, "'"+ProviderWeatherAPI+"'")
+		config.WeatherProvider = ProviderWeatherAPI
+	}
+
+	// Validate location mode
+	if config.LocationMode != "ip" && config.LocationMode != "manual" && config.LocationMode != "gps" {
+		invalid("location_mode", config.LocationMode, "'ip'")
+		config.LocationMode = "ip"
+	}
+
+	// Validate IP geolocation backends
+	var ipGeoProviders []string
+	for _, name := range config.IPGeoProviders {
+		switch strings.ToLower(name) {
+		case IPGeoStatic, IPGeoIPInfo, IPGeoIPAPICo, IPGeoIPAPI:
+			ipGeoProviders = append(ipGeoProviders, strings.ToLower(name))
+		default:
+			issues = append(issues, Issue{
+				Key:      "ip_geo_providers",
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("unknown IP geolocation provider %q, ignoring it", name),
+			})
+		}
+	}
+	if len(ipGeoProviders) == 0 {
+		ipGeoProviders = DefaultIPGeoProviders()
+	}
+	config.IPGeoProviders = ipGeoProviders
+
+	// Validate GPS settings
+	if config.GPSDAddress == "" {
+		config.GPSDAddress = "localhost:2947"
+	}
+	if config.GPSMinDistanceKm < 0 {
+		invalid("gps_min_distance_km", config.GPSMinDistanceKm, "2 km")
+	}
+	if config.GPSMinDistanceKm <= 0 {
+		config.GPSMinDistanceKm = 2
+	}
+
+	// Validate units
+	if config.Units != "metric" && config.Units != "imperial" {
+		invalid("units", config.Units, "'metric'")
+		config.Units = "metric"
+	}
+
+	// Validate time format
+	if config.TimeFormat != "12" && config.TimeFormat != "24" {
+		invalid("time_format", config.TimeFormat, "'24'")
+		config.TimeFormat = "24"
+	}
+
+	// Validate layout
+	if config.Layout != LayoutTabs && config.Layout != LayoutDashboard {
+		invalid("layout", config.Layout, "'"+LayoutTabs+"'")
+		config.Layout = LayoutTabs
+	}
+
+	// Validate themes
+	issues = append(issues, validateThemes(config)...)
+
+	// Validate refresh interval
+	if config.RefreshInterval < 1 || config.RefreshInterval > 60 {
+		issues = append(issues, Issue{
+			Key:      "refresh_interval",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("invalid value %d, must be between 1 and 60 minutes, using 5", config.RefreshInterval),
+		})
+		config.RefreshInterval = 5
+	}
+
+	// Validate alert rules
+	issues = append(issues, validateAlerts(config)...)
+
+	// Validate history retention
+	if config.History.RetentionDays < 0 {
+		invalid("history.retention_days", config.History.RetentionDays, fmt.Sprintf("%d days", DefaultRetentionDays))
+		config.History.RetentionDays = DefaultRetentionDays
+	}
+
+	// Validate API key requirement
+	if config.WeatherProvider == ProviderWeatherAPI && config.WeatherAPIKey == "" {
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Message:  "WEATHER_API_KEY is required for the WeatherAPI provider",
+		})
+	}
+
+	return issues
+}
+
+// parseIssue converts a TOML decoding error into an issue, keeping the line
+// number when the decoder reports one.
+func parseIssue(err error) Issue {
+	issue := Issue{Severity: SeverityError, Message: err.Error()}
+
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		issue.Line = parseErr.Position.Line
+		issue.Message = parseErr.Message
+	}
+	return issue
+}
+
+// unknownKeyIssues returns a warning for every key in the file that does not
+// correspond to a setting. The version key describes the file itself and is
+// not a setting.
+func unknownKeyIssues(meta toml.MetaData, data []byte) []Issue {
+	lines := keyLines(data)
+
+	var issues []Issue
+	for _, key := range meta.Undecoded() {
+		if key.String() == "version" {
+			continue
+		}
+		issues = append(issues, Issue{
+			Key:      key.String(),
+			Line:     lines[key.String()],
+			Severity: SeverityWarning,
+			Message:  "unknown key",
+		})
+	}
+	return issues
+}
+
+// keyLines maps the dotted key of every "key = value" line in a TOML document
+// to its line number. Keys inside [table] sections are prefixed with the
+// table name, matching the keys used by GetField.
+func keyLines(data []byte) map[string]int {
+	lines := make(map[string]int)
+	table := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "["):
+			name := strings.Trim(line, "[] ")
+			if i := strings.Index(name, "]"); i >= 0 {
+				name = strings.TrimSpace(name[:i])
+			}
+			table = name + "."
+			continue
+		}
+
+		key, _, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"'`)
+		if _, seen := lines[table+key]; !seen {
+			lines[table+key] = n
+		}
+	}
+	return lines
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeyLines(t *testing.T) {
+	data := `# Settings
+units = "metric"
+"theme" = "dark"
+
+[history]
+enabled = true # Record
+[themes.ocean] # Custom
+primary = "#0EA5E9"
+units = "imperial"
+`
+	want := map[string]int{
+		"units":                2,
+		"theme":                3,
+		"history.enabled":      6,
+		"themes.ocean.primary": 8,
+		"themes.ocean.units":   9,
+	}
+	if got := keyLines([]byte(data)); !reflect.DeepEqual(got, want) {
+		t.Errorf("keyLines() = %v, want %v", got, want)
+	}
+}
+
+func TestIssueLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		env      map[string]string
+		wantKey  string
+		wantLine int
+		wantText string
+	}{
+		{
+			name:     "invalid value",
+			file:     "version = 2\n\nunits = \"kelvin\"\n",
+			wantKey:  "units",
+			wantLine: 3,
+			wantText: "kelvin",
+		},
+		{
+			name:     "unknown key",
+			file:     "version = 2\nunits = \"metric\"\nbogus = 1\n",
+			wantKey:  "bogus",
+			wantLine: 3,
+			wantText: "unknown key",
+		},
+		{
+			name:     "syntax error",
+			file:     "version = 2\nunits = \"metric\"\ntheme = \n",
+			wantLine: 3,
+			wantText: "expected value",
+		},
+		{
+			name:     "invalid value in a profile",
+			file:     "version = 2\nprofile = \"travel\"\n\n[profiles.travel]\nunits = \"kelvin\"\n",
+			wantKey:  "units",
+			wantLine: 5,
+			wantText: "kelvin",
+		},
+		{
+			name:     "invalid alert",
+			file:     "version = 2\n\n[alerts.frost]\nwhen = \"temp_c <\"\n",
+			wantKey:  "alerts.frost.when",
+			wantLine: 4,
+			wantText: "not a number",
+		},
+		{
+			name:     "value from the environment has no line",
+			file:     "version = 2\nunits = \"metric\"\n",
+			env:      map[string]string{"WMS_UNITS": "kelvin"},
+			wantKey:  "units",
+			wantText: "WMS_UNITS",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := useTempConfigDir(t)
+			writeFile(t, filepath.Join(dir, "wms.toml"), tt.file)
+			t.Setenv("WEATHER_API_KEY", "key") // Keep the missing key warning out
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			issues, err := ValidateFile()
+			if err != nil {
+				t.Fatalf("ValidateFile() error = %v", err)
+			}
+			for _, issue := range issues {
+				if issue.Key == tt.wantKey && strings.Contains(issue.Message, tt.wantText) {
+					if issue.Line != tt.wantLine {
+						t.Errorf("issue %q is on line %d, want %d", issue, issue.Line, tt.wantLine)
+					}
+					return
+				}
+			}
+			t.Errorf("no issue for key %q containing %q in %v", tt.wantKey, tt.wantText, issues)
+		})
+	}
+}
+
+func TestIssueString(t *testing.T) {
+	tests := []struct {
+		issue Issue
+		want  string
+	}{
+		{Issue{Key: "units", Line: 3, Message: "invalid value"}, "line 3: units: invalid value"},
+		{Issue{Key: "units", Message: "invalid value"}, "units: invalid value"},
+		{Issue{Line: 7, Message: "expected value"}, "line 7: expected value"},
+	}
+	for _, tt := range tests {
+		if got := tt.issue.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLoadReportsSkippedLines(t *testing.T) {
+	dir := useTempConfigDir(t)
+	writeFile(t, filepath.Join(dir, "wms.toml"), "version = 2\nunits = \"imperial\"\ntheme = \nrefresh_interval = 10\n")
+
+	cfg, issues := ReadConfig()
+	if cfg.Units != "imperial" || cfg.RefreshInterval != 10 {
+		t.Errorf("units %q, refresh %d, want the readable settings kept", cfg.Units, cfg.RefreshInterval)
+	}
+	for _, issue := range issues {
+		if strings.Contains(issue.Message, "ignoring this line") {
+			if issue.Line != 3 || issue.Severity != SeverityError {
+				t.Errorf("issue %q (%s), want an error on line 3", issue, issue.Severity)
+			}
+			return
+		}
+	}
+	t.Errorf("no issue for the broken line in %v", issues)
+}
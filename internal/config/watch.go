@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+)
+
+// fileStamp identifies one version of a file on disk.
+type fileStamp struct {
+	exists  bool
+	size    int64
+	modTime int64 // Unix nanoseconds
+}
+
+// Stamp identifies the current versions of the config file and .env. Two
+// stamps compare equal with == when neither file has changed in between.
+type Stamp struct {
+	config fileStamp
+	env    fileStamp
+}
+
+// CurrentStamp reads the size and modification time of the config file and
+// .env. Polling it is cheap and works on every platform and file system,
+// including editors that replace files instead of writing them in place.
+func CurrentStamp() Stamp {
+	return Stamp{
+		config: stampFile(GetConfigPath()),
+		env:    stampFile(GetEnvPath()),
+	}
+}
+
+// stampFile returns the stamp of a single file.
+func stampFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, size: info.Size(), modTime: info.ModTime().UnixNano()}
+}
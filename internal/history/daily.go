@@ -0,0 +1,116 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// maxPrecipGap is the longest time an observation's precipitation is counted
+// for. Gaps between recordings, such as while WMS was not running, do not
+// count as rain.
+const maxPrecipGap = time.Hour
+
+// Day summarizes the observations of a place on one day. Days follow the
+// local time of the place when the provider reported it, so "last night"
+// means the night at the place rather than where WMS runs. Like
+// Observation.SiteTime, its times are wall-clock times of the place labelled
+// UTC.
+type Day struct {
+	Date time.Time // Midnight at the start of the day
+
+	HighC, HighF float64
+	HighAt       time.Time // When the high was observed
+	LowC, LowF   float64
+	LowAt        time.Time
+
+	// PrecipMm estimates the day's precipitation by treating each
+	// observation's precipitation as an hourly rate until the next one.
+	PrecipMm float64
+
+	PressureMb     float64 // Mean pressure
+	PressureChange float64 // From the first to the last observation of the day
+
+	Observations int
+}
+
+// PrecipIn returns the estimated precipitation in inches.
+func (d Day) PrecipIn() float64 {
+	return d.PrecipMm / 25.4
+}
+
+// SiteTime returns the wall-clock time of the observation at the place: the
+// local time reported by the provider, or the time it was recorded in the
+// local time zone when the provider did not report one. Either way the result
+// is labelled UTC, since the zone of the place is not recorded, so times from
+// both sources fall on the same days.
+func (o Observation) SiteTime() time.Time {
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.Parse(layout, o.LocalTime); err == nil {
+			return t
+		}
+	}
+	local := o.Time.Local()
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+}
+
+// Daily summarizes observations of a single place by day, oldest first. Use
+// Places and Observation.Place to pick the observations of one place.
+func Daily(observations []Observation) []Day {
+	sorted := append([]Observation(nil), observations...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	var days []Day
+	var pressureSum float64
+	var firstPressure, lastPressure float64
+	for i, o := range sorted {
+		local := o.SiteTime()
+		date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			days = append(days, Day{Date: date, HighC: o.TempC, HighF: o.TempF, HighAt: local, LowC: o.TempC, LowF: o.TempF, LowAt: local})
+			pressureSum, firstPressure = 0, o.PressureMb
+		}
+		day := &days[len(days)-1]
+
+		if o.TempC > day.HighC {
+			day.HighC, day.HighF, day.HighAt = o.TempC, o.TempF, local
+		}
+		if o.TempC < day.LowC {
+			day.LowC, day.LowF, day.LowAt = o.TempC, o.TempF, local
+		}
+
+		if i+1 < len(sorted) {
+			gap := min(sorted[i+1].Time.Sub(o.Time), maxPrecipGap)
+			day.PrecipMm += o.PrecipMm * gap.Hours()
+		}
+
+		day.Observations++
+		pressureSum += o.PressureMb
+		lastPressure = o.PressureMb
+		day.PressureMb = pressureSum / float64(day.Observations)
+		day.PressureChange = lastPressure - firstPressure
+	}
+	return days
+}
+
+// Places returns the places in the observations, the most recently observed
+// first.
+func Places(observations []Observation) []string {
+	latest := make(map[string]time.Time)
+	for _, o := range observations {
+		if place := o.Place(); o.Time.After(latest[place]) {
+			latest[place] = o.Time
+		}
+	}
+
+	places := make([]string, 0, len(latest))
+	for place := range latest {
+		places = append(places, place)
+	}
+	sort.Slice(places, func(i, j int) bool {
+		if !latest[places[i]].Equal(latest[places[j]]) {
+			return latest[places[i]].After(latest[places[j]])
+		}
+		return places[i] < places[j]
+	})
+	return places
+}
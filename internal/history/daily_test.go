@@ -0,0 +1,205 @@
+package history
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"wms/internal/weather"
+)
+
+// useLocalZone sets the local time zone for the duration of the test.
+func useLocalZone(t *testing.T, zone *time.Location) {
+	t.Helper()
+	original := time.Local
+	time.Local = zone
+	t.Cleanup(func() { time.Local = original })
+}
+
+// observed returns an observation recorded at the given time in UTC, with
+// the place's local time if localTime is not empty.
+func observed(at string, localTime string, current weather.Current) Observation {
+	t, err := time.Parse("2006-01-02 15:04", at)
+	if err != nil {
+		panic(err)
+	}
+	return Observation{Time: t, Location: "Berlin", LocalTime: localTime, Current: current}
+}
+
+// date returns midnight at the start of the given day, labelled UTC.
+func date(value string) time.Time {
+	t, _ := time.Parse("2006-01-02", value)
+	return t
+}
+
+// closeTo reports whether two estimates agree up to rounding.
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestSiteTime(t *testing.T) {
+	useLocalZone(t, time.FixedZone("UTC+2", 2*60*60))
+	recorded := time.Date(2025, 1, 15, 22, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		localTime string
+		want      string
+	}{
+		{"reported local time", "2025-01-16 07:30", "2025-01-16 07:30"},
+		{"ISO local time", "2025-01-16T07:30", "2025-01-16 07:30"},
+		{"no local time uses the local zone", "", "2025-01-16 00:30"},
+		{"damaged local time uses the local zone", "tomorrow", "2025-01-16 00:30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Observation{Time: recorded, LocalTime: tt.localTime}.SiteTime()
+			if got.Format("2006-01-02 15:04") != tt.want || got.Location() != time.UTC {
+				t.Errorf("SiteTime() = %v, want %s labelled UTC", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDailyBucketsBySiteTime(t *testing.T) {
+	// In Tokyo both observations were made on the 15th in UTC, but the
+	// second one was already on the next day at the place
+	days := Daily([]Observation{
+		observed("2025-01-15 14:00", "2025-01-15 23:00", weather.Current{TempC: 2}),
+		observed("2025-01-15 15:30", "2025-01-16 00:30", weather.Current{TempC: 1}),
+	})
+	if len(days) != 2 {
+		t.Fatalf("got %d days, want 2", len(days))
+	}
+	if !days[0].Date.Equal(date("2025-01-15")) || !days[1].Date.Equal(date("2025-01-16")) {
+		t.Errorf("days = %v and %v, want the 15th and the 16th", days[0].Date, days[1].Date)
+	}
+}
+
+func TestDailyMixedLocalTimes(t *testing.T) {
+	useLocalZone(t, time.FixedZone("UTC+2", 2*60*60))
+	days := Daily([]Observation{
+		observed("2025-01-15 08:00", "2025-01-15 10:00", weather.Current{TempC: 3}),
+		observed("2025-01-15 09:00", "", weather.Current{TempC: 5}), // 11:00 locally
+		observed("2025-01-15 23:00", "", weather.Current{TempC: 0}), // 01:00 on the 16th
+	})
+	if len(days) != 2 {
+		t.Fatalf("got %d days, want 2: %+v", len(days), days)
+	}
+	if days[0].Observations != 2 || !days[0].Date.Equal(date("2025-01-15")) {
+		t.Errorf("first day = %v with %d observations, want the 15th with 2", days[0].Date, days[0].Observations)
+	}
+	if days[0].HighAt.Format("15:04") != "11:00" {
+		t.Errorf("high at %v, want 11:00 at the place", days[0].HighAt)
+	}
+}
+
+func TestDailyHighsAndLows(t *testing.T) {
+	days := Daily([]Observation{
+		observed("2025-01-15 12:00", "2025-01-15 13:00", weather.Current{TempC: 6, TempF: 42.8}),
+		observed("2025-01-15 03:00", "2025-01-15 04:00", weather.Current{TempC: -2, TempF: 28.4}),
+		observed("2025-01-15 05:00", "2025-01-15 06:00", weather.Current{TempC: -2, TempF: 28.4}),
+		observed("2025-01-15 14:00", "2025-01-15 15:00", weather.Current{TempC: 6, TempF: 42.8}),
+		observed("2025-01-15 09:00", "2025-01-15 10:00", weather.Current{TempC: 3, TempF: 37.4}),
+	})
+	if len(days) != 1 {
+		t.Fatalf("got %d days, want 1", len(days))
+	}
+	day := days[0]
+	if day.LowC != -2 || day.LowF != 28.4 || day.LowAt.Format("15:04") != "04:00" {
+		t.Errorf("low = %v°C / %v°F at %v, want -2°C / 28.4°F at 04:00, the first time it was reached", day.LowC, day.LowF, day.LowAt)
+	}
+	if day.HighC != 6 || day.HighF != 42.8 || day.HighAt.Format("15:04") != "13:00" {
+		t.Errorf("high = %v°C / %v°F at %v, want 6°C / 42.8°F at 13:00, the first time it was reached", day.HighC, day.HighF, day.HighAt)
+	}
+	if day.Observations != 5 {
+		t.Errorf("observations = %d, want 5", day.Observations)
+	}
+}
+
+func TestDailyPrecipitation(t *testing.T) {
+	tests := []struct {
+		name         string
+		observations []Observation
+		want         []float64 // Per day
+	}{
+		{
+			name: "rate until the next observation",
+			observations: []Observation{
+				observed("2025-01-15 10:00", "2025-01-15 10:00", weather.Current{PrecipMm: 2}),
+				observed("2025-01-15 10:30", "2025-01-15 10:30", weather.Current{PrecipMm: 1}),
+				observed("2025-01-15 10:45", "2025-01-15 10:45", weather.Current{PrecipMm: 0}),
+			},
+			want: []float64{1.25},
+		},
+		{
+			name: "gaps count for an hour at most",
+			observations: []Observation{
+				observed("2025-01-15 10:00", "2025-01-15 10:00", weather.Current{PrecipMm: 2}),
+				observed("2025-01-15 15:00", "2025-01-15 15:00", weather.Current{PrecipMm: 0}),
+			},
+			want: []float64{2},
+		},
+		{
+			name: "the last observation counts for nothing",
+			observations: []Observation{
+				observed("2025-01-15 10:00", "2025-01-15 10:00", weather.Current{PrecipMm: 5}),
+			},
+			want: []float64{0},
+		},
+		{
+			name: "counted on the day of the observation",
+			observations: []Observation{
+				observed("2025-01-15 23:30", "2025-01-15 23:30", weather.Current{PrecipMm: 4}),
+				observed("2025-01-16 00:30", "2025-01-16 00:30", weather.Current{PrecipMm: 1}),
+				observed("2025-01-16 01:00", "2025-01-16 01:00", weather.Current{}),
+			},
+			want: []float64{4, 0.5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := Daily(tt.observations)
+			if len(days) != len(tt.want) {
+				t.Fatalf("got %d days, want %d", len(days), len(tt.want))
+			}
+			for i, day := range days {
+				if !closeTo(day.PrecipMm, tt.want[i]) {
+					t.Errorf("day %d precipitation = %v mm, want %v", i, day.PrecipMm, tt.want[i])
+				}
+				if !closeTo(day.PrecipIn(), tt.want[i]/25.4) {
+					t.Errorf("day %d precipitation = %v in, want %v", i, day.PrecipIn(), tt.want[i]/25.4)
+				}
+			}
+		})
+	}
+}
+
+func TestDailyPressure(t *testing.T) {
+	days := Daily([]Observation{
+		observed("2025-01-15 06:00", "2025-01-15 06:00", weather.Current{PressureMb: 1010}),
+		observed("2025-01-15 12:00", "2025-01-15 12:00", weather.Current{PressureMb: 1014}),
+		observed("2025-01-15 18:00", "2025-01-15 18:00", weather.Current{PressureMb: 1018}),
+		observed("2025-01-16 06:00", "2025-01-16 06:00", weather.Current{PressureMb: 1016}),
+		observed("2025-01-16 18:00", "2025-01-16 18:00", weather.Current{PressureMb: 1008}),
+	})
+	if len(days) != 2 {
+		t.Fatalf("got %d days, want 2", len(days))
+	}
+	tests := []struct {
+		mean, change float64
+	}{
+		{1014, 8},
+		{1012, -8},
+	}
+	for i, want := range tests {
+		if !closeTo(days[i].PressureMb, want.mean) || !closeTo(days[i].PressureChange, want.change) {
+			t.Errorf("day %d pressure = %v mb, change %v, want %v mb, change %v", i, days[i].PressureMb, days[i].PressureChange, want.mean, want.change)
+		}
+	}
+}
+
+func TestDailyEmpty(t *testing.T) {
+	if days := Daily(nil); len(days) != 0 {
+		t.Errorf("Daily(nil) = %v, want no days", days)
+	}
+}
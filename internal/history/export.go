@@ -0,0 +1,59 @@
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvHeader names the columns written by WriteCSV. They match the keys of
+// the JSON export.
+var csvHeader = []string{
+	"time", "location", "region", "country", "lat", "lon", "localtime", "provider",
+	"temp_c", "temp_f", "feelslike_c", "feelslike_f", "is_day", "condition",
+	"wind_kph", "wind_mph", "wind_dir", "humidity", "precip_mm", "pressure_mb",
+	"cloud", "vis_km", "uv",
+}
+
+// WriteCSV writes the observations as CSV with a header row. Times are in
+// RFC 3339 format with the offset they were recorded in.
+func WriteCSV(w io.Writer, observations []Observation) error {
+	out := csv.NewWriter(w)
+	out.Write(csvHeader)
+
+	number := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	for _, o := range observations {
+		c := o.Current
+		out.Write([]string{
+			o.Time.Format(time.RFC3339), o.Location, o.Region, o.Country,
+			number(o.Lat), number(o.Lon), o.LocalTime, o.Provider,
+			number(c.TempC), number(c.TempF), number(c.FeelslikeC), number(c.FeelslikeF),
+			strconv.Itoa(c.IsDay), c.Condition,
+			number(c.WindKph), number(c.WindMph), c.WindDir, strconv.Itoa(c.Humidity),
+			number(c.PrecipMm), number(c.PressureMb), strconv.Itoa(c.Cloud),
+			number(c.Visibility), number(c.UV),
+		})
+	}
+
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// WriteJSON writes the observations as an indented JSON array.
+func WriteJSON(w io.Writer, observations []Observation) error {
+	if observations == nil {
+		observations = []Observation{}
+	}
+	data, err := json.MarshalIndent(observations, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
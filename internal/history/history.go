@@ -0,0 +1,237 @@
+// Package history records every successful weather observation in the state
+// directory and reads them back, so conditions at a location can be looked at
+// over weeks. Observations are stored one JSON object per line, which lets
+// several WMS processes append to the same file and keeps it readable with
+// standard tools.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"wms/internal/atomicfile"
+	"wms/internal/config"
+	"wms/internal/weather"
+)
+
+// Observation is a recorded weather observation: when and where it was made,
+// the provider that reported it and the conditions at the time.
+type Observation struct {
+	Time      time.Time `json:"time"`
+	Location  string    `json:"location"`
+	Region    string    `json:"region,omitempty"`
+	Country   string    `json:"country,omitempty"`
+	Lat       float64   `json:"lat"`
+	Lon       float64   `json:"lon"`
+	LocalTime string    `json:"localtime,omitempty"` // Local time at the location, as reported by the provider
+	Provider  string    `json:"provider"`
+
+	weather.Current
+}
+
+// Place returns the location as "Name, Region, Country", leaving out the
+// parts that are unknown.
+func (o Observation) Place() string {
+	var parts []string
+	for _, part := range []string{o.Location, o.Region, o.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Filter selects observations. Zero values select everything.
+type Filter struct {
+	Location string    // Case-insensitive part of the place, see Observation.Place
+	Since    time.Time // First time included
+	Until    time.Time // First time no longer included
+}
+
+// Match reports whether the observation passes the filter.
+func (f Filter) Match(o Observation) bool {
+	if !f.Since.IsZero() && o.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !o.Time.Before(f.Until) {
+		return false
+	}
+	if f.Location != "" && !strings.Contains(strings.ToLower(o.Place()), strings.ToLower(f.Location)) {
+		return false
+	}
+	return true
+}
+
+// FromWeather makes an observation of weather that was observed at time t.
+func FromWeather(w *weather.Weather, provider string, t time.Time) Observation {
+	return Observation{
+		Time:      t,
+		Location:  w.Location.Name,
+		Region:    w.Location.Region,
+		Country:   w.Location.Country,
+		Lat:       w.Location.Lat,
+		Lon:       w.Location.Lon,
+		LocalTime: w.Location.LocalTime,
+		Provider:  provider,
+		Current:   w.Current,
+	}
+}
+
+// FromArchive makes an observation of every hour of an archive lookup, so
+// archived weather can be exported and summarized like recorded weather.
+func FromArchive(archive *weather.Archive) []Observation {
+	observations := make([]Observation, 0, len(archive.Hours))
+	for i, w := range archive.Hours {
+		observations = append(observations, FromWeather(w, weather.ProviderOpenMeteo, archive.Times[i]))
+	}
+	return observations
+}
+
+// mu serializes the writes of one process. Other processes are kept out by
+// the lock file, see lock.
+var mu sync.Mutex
+
+// pruneSlack is how far past the retention period the oldest observation may
+// be before the history is rewritten, so it is pruned about once a day
+// rather than on every recording.
+const pruneSlack = 24 * time.Hour
+
+// GetHistoryPath determines the path of the history file in the XDG state
+// directory.
+func GetHistoryPath() string {
+	dir := config.GetStateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "history.jsonl")
+}
+
+// Record appends the weather to the history if recording is enabled. Once
+// the oldest observation is a day past the retention period, observations
+// older than the retention period are dropped first. The history is locked
+// meanwhile, so recordings of other WMS processes are neither interleaved nor
+// lost to a concurrent prune.
+func Record(w *weather.Weather, provider string, settings config.History) error {
+	if !settings.Enabled || w == nil {
+		return nil
+	}
+
+	historyPath := GetHistoryPath()
+	if historyPath == "" {
+		return fmt.Errorf("could not determine history path")
+	}
+
+	line, err := json.Marshal(FromWeather(w, provider, time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to encode observation: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(historyPath), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	unlock, err := lock(historyPath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if settings.RetentionDays > 0 {
+		if err := prune(historyPath, time.Now().AddDate(0, 0, -settings.RetentionDays)); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// prune rewrites the history without the observations made before cutoff.
+// Observations are appended in order, so the file is only rewritten when its
+// first one is older than cutoff by pruneSlack, which keeps recording cheap.
+func prune(historyPath string, cutoff time.Time) error {
+	file, err := os.Open(historyPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	first, err := bufio.NewReader(file).ReadBytes('\n')
+	file.Close()
+	var o Observation
+	if len(first) == 0 || (json.Unmarshal(first, &o) == nil && !o.Time.Before(cutoff.Add(-pruneSlack))) {
+		return nil
+	}
+
+	data, err := os.ReadFile(historyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	var kept bytes.Buffer
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if err := json.Unmarshal(line, &o); err != nil || o.Time.Before(cutoff) {
+			continue // Damaged lines are dropped along with old ones
+		}
+		kept.Write(line)
+	}
+	if err := atomicfile.Write(historyPath, kept.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Load reads the observations that pass the filter, oldest first. Lines that
+// cannot be parsed, such as one cut short by a crash, are skipped. A missing
+// history is empty.
+func Load(filter Filter) ([]Observation, error) {
+	historyPath := GetHistoryPath()
+	if historyPath == "" {
+		return nil, fmt.Errorf("could not determine history path")
+	}
+
+	file, err := os.Open(historyPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer file.Close()
+
+	var observations []Observation
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var o Observation
+		if err := json.Unmarshal(scanner.Bytes(), &o); err != nil {
+			continue
+		}
+		if filter.Match(o) {
+			observations = append(observations, o)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return observations, nil
+}
@@ -0,0 +1,236 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"wms/internal/config"
+	"wms/internal/weather"
+)
+
+// useTempStateDir points the state directory at a fresh temporary directory
+// and returns the history path in it.
+func useTempStateDir(t *testing.T) string {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	return GetHistoryPath()
+}
+
+// writeObservations writes observations made at the given times to the
+// history file.
+func writeObservations(t *testing.T, path string, times ...time.Time) {
+	t.Helper()
+	var lines []string
+	for _, at := range times {
+		line, err := json.Marshal(Observation{Time: at, Location: "Berlin"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(line)+"\n")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "")), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordPrunesOncePastSlack(t *testing.T) {
+	now := time.Now()
+	settings := config.History{Enabled: true, RetentionDays: 7}
+	tests := []struct {
+		name   string
+		oldest time.Time
+		want   int // Observations after recording
+	}{
+		{"within retention", now.AddDate(0, 0, -6), 3},
+		{"just past retention", now.AddDate(0, 0, -7).Add(-time.Hour), 3},
+		{"a day past retention", now.AddDate(0, 0, -8).Add(-time.Hour), 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useTempStateDir(t)
+			writeObservations(t, path, tt.oldest, now.Add(-time.Hour))
+
+			if err := Record(&weather.Weather{}, "test", settings); err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+			observations, err := Load(Filter{})
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(observations) != tt.want {
+				t.Errorf("kept %d observations, want %d", len(observations), tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordConcurrently(t *testing.T) {
+	path := useTempStateDir(t)
+	now := time.Now()
+	writeObservations(t, path, now.AddDate(0, 0, -30), now.Add(-time.Hour))
+	settings := config.History{Enabled: true, RetentionDays: 7}
+
+	const records = 20
+	var wg sync.WaitGroup
+	for i := 0; i < records; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := &weather.Weather{}
+			w.Location.Name = fmt.Sprint("place ", i)
+			if err := Record(w, "test", settings); err != nil {
+				t.Errorf("Record() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	observations, err := Load(Filter{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(observations) != records+1 {
+		t.Errorf("got %d observations, want the %d recorded ones and the recent one", len(observations), records)
+	}
+}
+
+func TestLockExcludesOtherHolders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl.lock")
+	unlock, err := lock(path)
+	if err != nil {
+		t.Fatalf("lock() error = %v", err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		second, err := lock(path)
+		if err != nil {
+			t.Errorf("second lock() error = %v", err)
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second lock was acquired while the first was held")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case second := <-acquired:
+		if second != nil {
+			second()
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second lock was not acquired after the first was released")
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	at := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	o := Observation{Time: at, Location: "Berlin", Region: "Berlin", Country: "Germany"}
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"no filter", Filter{}, true},
+		{"since is included", Filter{Since: at}, true},
+		{"before since", Filter{Since: at.Add(time.Second)}, false},
+		{"until is excluded", Filter{Until: at}, false},
+		{"before until", Filter{Until: at.Add(time.Second)}, true},
+		{"part of the place, any case", Filter{Location: "germ"}, true},
+		{"other place", Filter{Location: "Tokyo"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(o); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlace(t *testing.T) {
+	tests := []struct {
+		o    Observation
+		want string
+	}{
+		{Observation{Location: "Berlin", Region: "Berlin", Country: "Germany"}, "Berlin, Berlin, Germany"},
+		{Observation{Location: "Tokyo", Country: "Japan"}, "Tokyo, Japan"},
+		{Observation{}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.o.Place(); got != tt.want {
+			t.Errorf("Place() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLoadSkipsDamagedLines(t *testing.T) {
+	path := useTempStateDir(t)
+	now := time.Now()
+	writeObservations(t, path, now.Add(-2*time.Hour), now.Add(-time.Hour))
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("{\"time\": \"2025-01-01T00:00:00Z\", \"loc") // Cut short by a crash
+	file.Close()
+
+	observations, err := Load(Filter{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(observations) != 2 {
+		t.Errorf("Load() = %d observations, want the 2 intact ones", len(observations))
+	}
+}
+
+func TestWriteCSVAndJSON(t *testing.T) {
+	o := Observation{
+		Time:     time.Date(2025, 3, 10, 12, 0, 0, 0, time.FixedZone("", 3600)),
+		Location: "Berlin, Mitte",
+		Lat:      52.52,
+		Provider: "OpenMeteo",
+	}
+	o.TempC = 4.5
+	o.Humidity = 81
+
+	var csvOut strings.Builder
+	if err := WriteCSV(&csvOut, []Observation{o}); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "time,location,") {
+		t.Fatalf("WriteCSV() = %q, want a header and one row", csvOut.String())
+	}
+	if !strings.HasPrefix(lines[1], `2025-03-10T12:00:00+01:00,"Berlin, Mitte",,,52.52,0,,OpenMeteo,4.5,`) {
+		t.Errorf("WriteCSV() row = %q", lines[1])
+	}
+
+	var jsonOut strings.Builder
+	if err := WriteJSON(&jsonOut, nil); err != nil || strings.TrimSpace(jsonOut.String()) != "[]" {
+		t.Errorf("WriteJSON(nil) = %q, %v, want an empty array", jsonOut.String(), err)
+	}
+	jsonOut.Reset()
+	if err := WriteJSON(&jsonOut, []Observation{o}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var decoded []Observation
+	if err := json.Unmarshal([]byte(jsonOut.String()), &decoded); err != nil || len(decoded) != 1 {
+		t.Fatalf("WriteJSON() = %q, %v", jsonOut.String(), err)
+	}
+	if decoded[0].Location != o.Location || decoded[0].TempC != 4.5 || decoded[0].Humidity != 81 {
+		t.Errorf("WriteJSON() round trip = %+v", decoded[0])
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !unix
+
+package history
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// lockTimeout is how long lock waits for another process.
+const lockTimeout = 5 * time.Second
+
+// staleLockAge is the age after which a lock file is assumed to be left over
+// from a process that crashed.
+const staleLockAge = 30 * time.Second
+
+// lock takes an exclusive lock by creating the lock file at path, waiting
+// for other processes to remove theirs, and returns the function that
+// releases it. A separate lock file is used because pruning replaces the
+// history file.
+func lock(path string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock history: %w", err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to lock history: %s is held by another process", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
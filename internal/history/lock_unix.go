@@ -0,0 +1,27 @@
+//go:build unix
+
+package history
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lock takes an exclusive lock on the lock file at path, waiting for other
+// processes to release it, and returns the function that releases it. A
+// separate lock file is used because pruning replaces the history file.
+func lock(path string) (func(), error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history lock: %w", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock history: %w", err)
+	}
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
@@ -0,0 +1,190 @@
+// Package status renders short, templated weather summaries for status lines
+// such as tmux, Starship, i3bar and Waybar.
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"wms/internal/config"
+	"wms/internal/ui/components"
+	"wms/internal/ui/icons"
+	"wms/internal/weather"
+)
+
+// Output formats a preset can produce.
+const (
+	FormatPlain  = "plain"  // The rendered text as is
+	FormatI3bar  = "i3bar"  // A single i3bar protocol block (also used by i3blocks)
+	FormatWaybar = "waybar" // A Waybar custom module object ("return-type": "json")
+)
+
+// Preset is a built-in combination of templates and output format.
+type Preset struct {
+	Text    string // Template for the main text
+	Tooltip string // Template for the tooltip or short text, used by JSON formats
+	Format  string
+}
+
+// Presets holds the built-in status line presets, keyed by name.
+var Presets = map[string]Preset{
+	"plain": {
+		Text:   `{{.Icon}} {{.Temperature}} {{.Condition}}`,
+		Format: FormatPlain,
+	},
+	"tmux": {
+		Text:   `#[fg=colour45]{{.Icon}} {{.Temperature}}#[default] {{.Condition}} #[fg=colour141]{{.Moon.Icon}}#[default]`,
+		Format: FormatPlain,
+	},
+	"starship": {
+		Text:   `{{.Icon}} {{.Temperature}}`,
+		Format: FormatPlain,
+	},
+	"i3bar": {
+		Text:    `{{.Icon}} {{.Temperature}} {{.Condition}}`,
+		Tooltip: `{{.Icon}} {{.Temperature}}`,
+		Format:  FormatI3bar,
+	},
+	"waybar": {
+		Text:    `{{.Icon}} {{.Temperature}}`,
+		Tooltip: `{{.Location}}: {{.Condition}}, feels like {{.FeelsLike}}` + "\n" + `Wind {{.Wind}}, humidity {{.Humidity}}` + "\n" + `{{.Moon.Icon}} {{.Moon.Phase}} · ☀ {{.Sunrise}} – {{.Sunset}}` + "\n" + `Updated {{.UpdatedAt.Format "15:04"}}`,
+		Format:  FormatWaybar,
+	},
+}
+
+// PresetNames returns the names of all built-in presets in alphabetical order.
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Data is the value passed to status templates. The pre-formatted fields
+// follow the configured units and time format; the raw models are available
+// for anything else.
+type Data struct {
+	Icon        string // Weather emoji
+	IconName    string // Standardized icon name, e.g. "LightRain"
+	Location    string
+	Condition   string
+	Temperature string
+	FeelsLike   string
+	Wind        string
+	Humidity    string
+	UV          string
+	Sunrise     string
+	Sunset      string
+	UpdatedAt   time.Time
+	Stale       bool // Whether the weather is older than the refresh interval
+
+	Weather *weather.Weather
+	Moon    components.Moon
+	Sun     components.Sun
+}
+
+// NewData builds the template data from a weather observation.
+func NewData(w *weather.Weather, updatedAt time.Time, cfg config.Config) Data {
+	display := weather.FormatWeatherDisplay(w, cfg)
+	sun := components.NewSun()
+
+	timeLayout := "15:04"
+	if cfg.TimeFormat == "12" {
+		timeLayout = "3:04 PM"
+	}
+
+	return Data{
+		Icon:        icons.GetWeatherEmoji(w.Current.Condition, w.Current.IsDay == 1),
+		IconName:    icons.GetIconName(w.Current.Condition, w.Current.IsDay == 1),
+		Location:    w.Location.Name,
+		Condition:   display.Condition,
+		Temperature: display.Temperature,
+		FeelsLike:   display.FeelsLike,
+		Wind:        display.Wind,
+		Humidity:    display.Humidity,
+		UV:          display.UV,
+		Sunrise:     sun.Sunrise.Format(timeLayout),
+		Sunset:      sun.Sunset.Format(timeLayout),
+		UpdatedAt:   updatedAt.Local(),
+		Stale:       time.Since(updatedAt) > time.Duration(cfg.RefreshInterval)*time.Minute,
+		Weather:     w,
+		Moon:        components.NewLocalMoon(),
+		Sun:         sun,
+	}
+}
+
+// Render executes the preset's templates against data and encodes the result
+// in the preset's output format.
+func Render(preset Preset, data Data) (string, error) {
+	text, err := execute("text", preset.Text, data)
+	if err != nil {
+		return "", err
+	}
+	tooltip, err := execute("tooltip", preset.Tooltip, data)
+	if err != nil {
+		return "", err
+	}
+
+	switch preset.Format {
+	case FormatI3bar:
+		block := map[string]string{
+			"name":      "wms",
+			"full_text": text,
+		}
+		if tooltip != "" {
+			block["short_text"] = tooltip
+		}
+		if data.Stale {
+			block["color"] = "#6B7280"
+		}
+		return encodeJSON(block)
+	case FormatWaybar:
+		classes := []string{strings.ToLower(data.IconName)}
+		if data.Stale {
+			classes = append(classes, "stale")
+		}
+		return encodeJSON(struct {
+			Text    string   `json:"text"`
+			Tooltip string   `json:"tooltip,omitempty"`
+			Alt     string   `json:"alt"`
+			Class   []string `json:"class"`
+		}{text, tooltip, data.IconName, classes})
+	default:
+		return text, nil
+	}
+}
+
+// execute parses and runs a single template. An empty template renders to an
+// empty string.
+func execute(name, text string, data Data) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// encodeJSON encodes v as a single line of JSON.
+func encodeJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode status: %w", err)
+	}
+	return string(data), nil
+}
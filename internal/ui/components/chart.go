@@ -0,0 +1,162 @@
+package components
+
+import (
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sparkBlocks are the block characters of a sparkline, from low to high.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// brailleDots are the bits of the dots in a braille character by column and
+// row; each character holds two columns of four dots.
+var brailleDots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// Chart is a series of values that can be drawn in the terminal, either as a
+// sparkline of block characters or as a line of braille dots. Both scale the
+// series to the room they are given.
+type Chart struct {
+	Values []float64
+
+	// Min and Max fix the vertical range, e.g. 0 to 100 for percentages.
+	// When they are equal, the range of the values is used.
+	Min, Max float64
+
+	// Color, if set, colors each cell by the value drawn in it, e.g. with
+	// styles.TemperatureAccent.
+	Color func(value float64) lipgloss.Color
+}
+
+// bounds returns the vertical range of the chart. A flat series gets a range
+// around its value, so it is drawn in the middle.
+func (c Chart) bounds() (lo, hi float64) {
+	if c.Min != c.Max {
+		return c.Min, c.Max
+	}
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, v := range c.Values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if lo == hi {
+		return lo - 1, hi + 1
+	}
+	return lo, hi
+}
+
+// level scales v to a whole number from 0 to steps-1 within the range of the
+// chart.
+func (c Chart) level(v float64, steps int) int {
+	lo, hi := c.bounds()
+	n := int(math.Round((v - lo) / (hi - lo) * float64(steps-1)))
+	return max(0, min(steps-1, n))
+}
+
+// Sparkline renders the chart as a single line of width block characters.
+func (c Chart) Sparkline(width int) string {
+	if len(c.Values) == 0 || width <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, v := range Resample(c.Values, width) {
+		b.WriteString(c.paint(string(sparkBlocks[c.level(v, len(sparkBlocks))]), v))
+	}
+	return b.String()
+}
+
+// Braille renders the chart as a line of braille dots in width×height cells,
+// returned as one string per row from top to bottom. Each cell holds two
+// points side by side and four dots from bottom to top, and steps between
+// neighboring points are filled in so the line stays connected.
+func (c Chart) Braille(width, height int) []string {
+	if len(c.Values) == 0 || width <= 0 || height <= 0 {
+		return nil
+	}
+
+	rows := height * 4
+	cells := make([][]rune, height)
+	for i := range cells {
+		cells[i] = make([]rune, width)
+	}
+	dot := func(x, y int) {
+		y = rows - 1 - y // Row 0 is at the top
+		cells[y/4][x/2] |= brailleDots[x%2][y%4]
+	}
+
+	points := Resample(c.Values, width*2)
+	for x, v := range points {
+		y := c.level(v, rows)
+		dot(x, y)
+		if x == 0 {
+			continue
+		}
+
+		// Fill the step from the previous point, the half next to it in
+		// its column and the rest in this one
+		prev := c.level(points[x-1], rows)
+		mid := (prev + y) / 2
+		for yy := min(prev, y) + 1; yy < max(prev, y); yy++ {
+			if (prev < y) == (yy <= mid) {
+				dot(x-1, yy)
+			} else {
+				dot(x, yy)
+			}
+		}
+	}
+
+	lines := make([]string, height)
+	for i, row := range cells {
+		var b strings.Builder
+		for x, bits := range row {
+			cell := " "
+			if bits != 0 {
+				cell = string(0x2800 + bits)
+			}
+			b.WriteString(c.paint(cell, (points[2*x]+points[2*x+1])/2))
+		}
+		lines[i] = b.String()
+	}
+	return lines
+}
+
+// paint colors a cell with the color of its value, if the chart has colors.
+func (c Chart) paint(cell string, v float64) string {
+	if c.Color == nil || cell == " " {
+		return cell
+	}
+	return lipgloss.NewStyle().Foreground(c.Color(v)).Render(cell)
+}
+
+// Resample stretches or shrinks values to n points by linear interpolation,
+// keeping the first and last value.
+func Resample(values []float64, n int) []float64 {
+	if n <= 0 || len(values) == 0 {
+		return nil
+	}
+	if len(values) == 1 || n == 1 {
+		out := make([]float64, n)
+		for i := range out {
+			out[i] = values[0]
+		}
+		return out
+	}
+
+	out := make([]float64, n)
+	scale := float64(len(values)-1) / float64(n-1)
+	for i := range out {
+		pos := float64(i) * scale
+		j := int(pos)
+		if j >= len(values)-1 {
+			out[i] = values[len(values)-1]
+			continue
+		}
+		frac := pos - float64(j)
+		out[i] = values[j] + (values[j+1]-values[j])*frac
+	}
+	return out
+}
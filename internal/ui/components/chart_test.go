@@ -0,0 +1,85 @@
+package components
+
+import (
+	"math"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestResample(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		n      int
+		want   []float64
+	}{
+		{"same length", []float64{1, 2, 3}, 3, []float64{1, 2, 3}},
+		{"stretch", []float64{0, 10}, 5, []float64{0, 2.5, 5, 7.5, 10}},
+		{"shrink keeps the ends", []float64{0, 1, 2, 3, 4, 5, 6}, 3, []float64{0, 3, 6}},
+		{"shrink interpolates", []float64{0, 10, 20, 30}, 3, []float64{0, 15, 30}},
+		{"single value", []float64{7}, 3, []float64{7, 7, 7}},
+		{"single point", []float64{1, 2, 3}, 1, []float64{1}},
+		{"no values", nil, 3, nil},
+		{"no points", []float64{1, 2}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Resample(tt.values, tt.n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Resample() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Fatalf("Resample() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name  string
+		chart Chart
+		width int
+		want  string
+	}{
+		{"rising", Chart{Values: []float64{0, 7}}, 8, "▁▂▃▄▅▆▇█"},
+		{"fixed range", Chart{Values: []float64{0, 50, 100}, Min: 0, Max: 100}, 3, "▁▅█"},
+		{"flat series in the middle", Chart{Values: []float64{3, 3}}, 2, "▅▅"},
+		{"no room", Chart{Values: []float64{1, 2}}, 0, ""},
+		{"no values", Chart{}, 4, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.chart.Sparkline(tt.width); got != tt.want {
+				t.Errorf("Sparkline() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBraille(t *testing.T) {
+	chart := Chart{Values: []float64{0, 1, 2, 3, 4, 5, 6, 7}}
+	lines := chart.Braille(4, 2)
+	if len(lines) != 2 {
+		t.Fatalf("Braille() returned %d lines, want 2", len(lines))
+	}
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line); n != 4 {
+			t.Errorf("line %q is %d cells wide, want 4", line, n)
+		}
+	}
+	if got := chart.Braille(0, 2); got != nil {
+		t.Errorf("Braille() without room = %q, want nil", got)
+	}
+	if got := (Chart{}).Braille(4, 2); got != nil {
+		t.Errorf("Braille() without values = %q, want nil", got)
+	}
+
+	// A rising line starts at the bottom left and ends at the top right
+	bottom, top := []rune(lines[1]), []rune(lines[0])
+	if bottom[0] == ' ' || top[0] != ' ' || top[3] == ' ' || bottom[3] != ' ' {
+		t.Errorf("Braille() = %q, want a line rising from the bottom left to the top right", lines)
+	}
+}
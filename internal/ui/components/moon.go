@@ -45,9 +45,30 @@ func NewMoon() Moon {
 	}
 }
 
+// NewLocalMoon creates a Moon component from the locally calculated moon
+// phase. It never touches the network, which makes it suitable for commands
+// that must return quickly.
+func NewLocalMoon() Moon {
+	moon := NewMoon()
+	data, err := calculateMoonPhaseLocally()
+	if err != nil {
+		moon.UpdateWithError(err)
+		return moon
+	}
+	moon.UpdateWithData(data)
+	return moon
+}
+
+// Sources of moon data, as reported by FetchMoonData.
+const (
+	MoonSourceFarmsense = "Farmsense"
+	MoonSourceLocal     = "local calculation"
+)
+
 // FetchMoonData fetches the current moon phase data from the Farmsense API.
 // If the API is unavailable, it falls back to calculating moon phase locally.
-func FetchMoonData() (*MoonResponse, error) {
+// It also returns which of the two sources the data came from.
+func FetchMoonData() (*MoonResponse, string, error) {
 	client := &http.Client{Timeout: 5 * time.Second} // Reduced timeout
 	timestamp := time.Now().Unix()
 	url := fmt.Sprintf("https://api.farmsense.net/v1/moonphases/?d=%d", timestamp)
@@ -55,26 +76,33 @@ func FetchMoonData() (*MoonResponse, error) {
 	resp, err := client.Get(url)
 	if err != nil {
 		// Fallback to local calculation if API is unavailable
-		return calculateMoonPhaseLocally()
+		return localMoonData()
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		// Fallback to local calculation if API returns error
-		return calculateMoonPhaseLocally()
+		return localMoonData()
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return calculateMoonPhaseLocally()
+		return localMoonData()
 	}
 
 	var moonData MoonResponse
 	if err := json.Unmarshal(body, &moonData); err != nil {
-		return calculateMoonPhaseLocally()
+		return localMoonData()
 	}
 
-	return &moonData, nil
+	return &moonData, MoonSourceFarmsense, nil
+}
+
+// localMoonData calculates the moon phase locally, for use as a fallback by
+// FetchMoonData.
+func localMoonData() (*MoonResponse, string, error) {
+	data, err := calculateMoonPhaseLocally()
+	return data, MoonSourceLocal, err
 }
 
 // UpdateWithData updates the moon component's state with new data from the API.
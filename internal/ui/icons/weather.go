@@ -2,6 +2,10 @@
 package icons
 
 import (
+	"strings"
+
+	"wms/internal/ui/styles"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -23,6 +27,81 @@ func GetWeatherIcon(condition string, isDay bool, useColors bool) *WeatherIcon {
 	}
 }
 
+// GetTintedWeatherIcon works like GetWeatherIcon with colors, but draws the
+// element that stands for the conditions, such as the sun, the rain or the
+// lightning, in the tint color instead of its theme color.
+func GetTintedWeatherIcon(condition string, isDay bool, tint lipgloss.Color) *WeatherIcon {
+	return &WeatherIcon{
+		Lines:     getColoredIcon(mapConditionToIcon(condition, isDay), tint),
+		UseColors: true,
+	}
+}
+
+// GetSky returns the group of conditions a weather condition belongs to,
+// which decides its accent color. Conditions are matched by keyword, so the
+// wording of every provider is understood.
+func GetSky(condition string) styles.Sky {
+	condition = strings.ToLower(condition)
+	hasAny := func(words ...string) bool {
+		for _, word := range words {
+			if strings.Contains(condition, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case hasAny("thunder"):
+		return styles.SkyStorm
+	case hasAny("snow", "sleet", "ice", "blizzard"):
+		return styles.SkySnow
+	case hasAny("rain", "drizzle", "shower"):
+		return styles.SkyRain
+	case hasAny("fog", "mist"):
+		return styles.SkyFog
+	default:
+		return styles.SkyFair
+	}
+}
+
+// GetWeatherEmoji returns a single emoji for the weather condition, for places
+// where there is no room for the ASCII art, such as status lines.
+func GetWeatherEmoji(condition string, isDay bool) string {
+	switch mapConditionToIcon(condition, isDay) {
+	case "Sunny":
+		return "☀️"
+	case "Clear":
+		return "🌙"
+	case "PartlyCloudy":
+		return "⛅"
+	case "PartlyCloudyNight":
+		return "☁️"
+	case "Cloudy":
+		return "☁️"
+	case "Fog":
+		return "🌫️"
+	case "LightRain":
+		return "🌦️"
+	case "HeavyRain":
+		return "🌧️"
+	case "LightSnow", "HeavySnow":
+		return "🌨️"
+	case "Thunderstorm":
+		return "⛈️"
+	case "Sleet", "IcePellets":
+		return "🌨️"
+	default:
+		return "🌡️"
+	}
+}
+
+// GetIconName returns the standardized icon name for a weather condition,
+// e.g. "LightRain". It is useful as a stable identifier for styling.
+func GetIconName(condition string, isDay bool) string {
+	return mapConditionToIcon(condition, isDay)
+}
+
 // mapConditionToIcon is a helper function that maps a human-readable weather
 // condition string to a standardized icon name.
 func mapConditionToIcon(condition string, isDay bool) string {
@@ -49,7 +128,7 @@ func mapConditionToIcon(condition string, isDay bool) string {
 		return "LightSnow"
 	case "Moderate snow", "Heavy snow", "Patchy heavy snow", "Moderate or heavy snow showers", "Blizzard":
 		return "HeavySnow"
-	case "Thundery outbreaks possible", "Patchy light rain with thunder", "Moderate or heavy rain with thunder":
+	case "Thundery outbreaks possible", "Patchy light rain with thunder", "Moderate or heavy rain with thunder", "Thunderstorm":
 		return "Thunderstorm"
 	case "Patchy sleet possible", "Light sleet", "Moderate or heavy sleet":
 		return "Sleet"
@@ -64,7 +143,7 @@ func mapConditionToIcon(condition string, isDay bool) string {
 // name, returning either a colored or monochrome version based on the useColors flag.
 func getIcon(name string, useColors bool) []string {
 	if useColors {
-		return getColoredIcon(name)
+		return getColoredIcon(name, "")
 	}
 	return getMonochromeIcon(name)
 }
@@ -207,17 +286,35 @@ func getMonochromeIcon(name string) []string {
 }
 
 // getColoredIcon returns a map of all the colored ASCII art icons, using
-// lipgloss for styling.
-func getColoredIcon(name string) []string {
-	// Define colors
-	sunColor := lipgloss.Color("#FCD34D")       // amber-300
-	cloudColor := lipgloss.Color("#9CA3AF")     // gray-400
-	darkCloudColor := lipgloss.Color("#6B7280") // gray-500
-	rainColor := lipgloss.Color("#60A5FA")      // blue-400
-	snowColor := lipgloss.Color("#F3F4F6")      // gray-50
-	thunderColor := lipgloss.Color("#FBBF24")   // amber-400
-	moonColor := lipgloss.Color("#E5E7EB")      // gray-200
-	fogColor := lipgloss.Color("#D1D5DB")       // gray-300
+// lipgloss for styling. A non-empty tint replaces the color of the element
+// that stands for the conditions.
+func getColoredIcon(name string, tint lipgloss.Color) []string {
+	// Colors come from the active theme
+	sunColor := styles.IconSunColor
+	cloudColor := styles.IconCloudColor
+	darkCloudColor := styles.TextMuted
+	rainColor := styles.Primary
+	snowColor := styles.TextPrimary
+	thunderColor := styles.Warning
+	moonColor := styles.IconMoonColor
+	fogColor := styles.TextSecondary
+
+	if tint != "" {
+		switch name {
+		case "Sunny", "PartlyCloudy":
+			sunColor = tint
+		case "Clear", "PartlyCloudyNight":
+			moonColor = tint
+		case "LightRain", "HeavyRain":
+			rainColor = tint
+		case "LightSnow", "HeavySnow", "Sleet", "IcePellets":
+			snowColor = tint
+		case "Thunderstorm":
+			thunderColor = tint
+		case "Fog":
+			fogColor = tint
+		}
+	}
 
 	coloredIcons := map[string][]string{
 		"Sunny": {
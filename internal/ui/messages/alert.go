@@ -0,0 +1,37 @@
+package messages
+
+import (
+	"wms/internal/alert"
+	"wms/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// AlertActionMsg is sent when the desktop notification or the command of a
+// raised alert has finished.
+type AlertActionMsg struct {
+	Name  string
+	Error error
+}
+
+// AlertActionsCmd creates a command that shows the desktop notification and
+// runs the command of a raised alert, as far as its rule asks for them. It
+// returns nil when the rule asks for neither.
+func AlertActionsCmd(rule config.Alert, a alert.Active) tea.Cmd {
+	if !rule.Notify && rule.Command == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		if rule.Notify {
+			if err := alert.Notify(a); err != nil {
+				return AlertActionMsg{Name: a.Name, Error: err}
+			}
+		}
+		if rule.Command != "" {
+			if err := alert.RunCommand(rule.Command, a); err != nil {
+				return AlertActionMsg{Name: a.Name, Error: err}
+			}
+		}
+		return AlertActionMsg{Name: a.Name}
+	}
+}
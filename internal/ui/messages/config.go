@@ -0,0 +1,55 @@
+package messages
+
+import (
+	"time"
+
+	"wms/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// configPollInterval is how often the config file and .env are checked for
+// changes. It is a variable so tests can poll faster.
+var configPollInterval = 2 * time.Second
+
+// configSettleDelay gives editors time to finish writing before the file is
+// read, so a half-saved file is not mistaken for a broken one.
+const configSettleDelay = 200 * time.Millisecond
+
+// ConfigChangedMsg is sent when wms.toml or .env changed on disk. It carries
+// the reloaded configuration, with the command-line flags applied again, and
+// the issues found while validating it.
+type ConfigChangedMsg struct {
+	Config config.Config
+	Issues []config.Issue
+	Flags  *config.Flags // The flags the configuration was loaded with
+	Stamp  config.Stamp  // Pass to the next WatchConfigCmd
+}
+
+// WatchConfigCmd creates a command that waits until the config file or .env
+// differs from the given stamp and then reloads the configuration. The flags
+// are the ones WMS was started with, so they keep overriding the files. Flags
+// received from updates, such as another selected profile, replace them for
+// the following reloads. The watcher keeps its own copy of the flags, so it
+// never shares them with the UI.
+func WatchConfigCmd(flags *config.Flags, since config.Stamp, updates <-chan *config.Flags) tea.Cmd {
+	flags = flags.Clone()
+	return func() tea.Msg {
+		for {
+			select {
+			case latest := <-updates:
+				flags = latest.Clone()
+				continue
+			case <-time.After(configPollInterval):
+			}
+			if config.CurrentStamp() == since {
+				continue
+			}
+
+			time.Sleep(configSettleDelay)
+			stamp := config.CurrentStamp()
+			cfg, issues := config.Load(flags)
+			return ConfigChangedMsg{Config: cfg, Issues: issues, Flags: flags, Stamp: stamp}
+		}
+	}
+}
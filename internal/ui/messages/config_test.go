@@ -0,0 +1,107 @@
+package messages
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"wms/internal/config"
+)
+
+// useTempConfig points the config directory at a temporary one holding a
+// wms.toml with the given settings, and polls it quickly.
+func useTempConfig(t *testing.T, settings string) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WMS_CONFIG", "")
+	interval := configPollInterval
+	configPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { configPollInterval = interval })
+
+	path := config.GetConfigPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, settings)
+}
+
+// writeConfig replaces wms.toml with the given settings.
+func writeConfig(t *testing.T, settings string) {
+	t.Helper()
+	data := fmt.Sprintf("version = %d\n%s", config.CurrentVersion, settings)
+	if err := os.WriteFile(config.GetConfigPath(), []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runWatcher runs a WatchConfigCmd command in the background and returns the
+// channel its message arrives on.
+func runWatcher(flags *config.Flags, since config.Stamp, updates <-chan *config.Flags) <-chan ConfigChangedMsg {
+	msgs := make(chan ConfigChangedMsg, 1)
+	cmd := WatchConfigCmd(flags, since, updates)
+	go func() { msgs <- cmd().(ConfigChangedMsg) }()
+	return msgs
+}
+
+// receive waits for the watcher's message.
+func receive(t *testing.T, msgs <-chan ConfigChangedMsg) ConfigChangedMsg {
+	t.Helper()
+	select {
+	case msg := <-msgs:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("the watcher did not notice the change")
+		return ConfigChangedMsg{}
+	}
+}
+
+func TestWatchConfigCmdReloadsChangedFile(t *testing.T) {
+	useTempConfig(t, "units = \"metric\"\n")
+	since := config.CurrentStamp()
+	fs := flag.NewFlagSet("wms", flag.ContinueOnError)
+	flags := config.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-time", "12"}); err != nil {
+		t.Fatal(err)
+	}
+	msgs := runWatcher(flags, since, nil)
+
+	select {
+	case msg := <-msgs:
+		t.Fatalf("the watcher reloaded an unchanged file: %+v", msg.Config)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	writeConfig(t, "units = \"imperial\"\ntime_format = \"24\"\n")
+	msg := receive(t, msgs)
+	if msg.Config.Units != "imperial" {
+		t.Errorf("reloaded units = %q, want imperial", msg.Config.Units)
+	}
+	if msg.Config.TimeFormat != "12" {
+		t.Errorf("reloaded time format = %q, want the flag to keep overriding the file", msg.Config.TimeFormat)
+	}
+	if msg.Stamp == since || msg.Stamp != config.CurrentStamp() {
+		t.Error("the message does not carry the stamp of the reloaded file")
+	}
+}
+
+func TestWatchConfigCmdUsesFlagUpdates(t *testing.T) {
+	useTempConfig(t, "units = \"metric\"\n\n[profiles.work]\nunits = \"imperial\"\n")
+	flags := &config.Flags{}
+	updates := make(chan *config.Flags, 1)
+	msgs := runWatcher(flags, config.CurrentStamp(), updates)
+
+	updates <- flags.WithProfile("work")
+	time.Sleep(50 * time.Millisecond) // Let the watcher pick up the update first
+	writeConfig(t, "units = \"metric\"\ntime_format = \"12\"\n\n[profiles.work]\nunits = \"imperial\"\n")
+
+	msg := receive(t, msgs)
+	if msg.Flags == nil || msg.Flags.Profile != "work" || msg.Config.Profile != "work" || msg.Config.Units != "imperial" {
+		t.Errorf("reloaded with flags %+v into profile %q and units %q, want the work profile", msg.Flags, msg.Config.Profile, msg.Config.Units)
+	}
+	if flags.Profile != "" {
+		t.Errorf("the watcher changed the caller's flags to profile %q", flags.Profile)
+	}
+}
@@ -0,0 +1,61 @@
+package messages
+
+import (
+	"fmt"
+	"time"
+
+	"wms/internal/config"
+	"wms/internal/history"
+	"wms/internal/weather"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// HistoryMsg is sent when the recorded observations for the History tab have
+// been read.
+type HistoryMsg struct {
+	Observations []history.Observation
+	Error        error
+}
+
+// LoadHistoryCmd creates a command that reads the observations recorded in
+// the given number of days before today.
+func LoadHistoryCmd(days int) tea.Cmd {
+	return func() tea.Msg {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		observations, err := history.Load(history.Filter{Since: today.AddDate(0, 0, -days)})
+		return HistoryMsg{Observations: observations, Error: err}
+	}
+}
+
+// ArchiveMsg is sent when the weather of a past day has been looked up in the
+// archive.
+type ArchiveMsg struct {
+	Date    time.Time
+	Archive *weather.Archive
+	Error   error
+}
+
+// FetchArchiveCmd creates a command that looks up the weather of a past day.
+// With a recorded observation, the day is looked up at its coordinates and
+// named after it; otherwise the location is resolved from the configuration.
+func FetchArchiveCmd(cfg config.Config, place *history.Observation, date time.Time) tea.Cmd {
+	return func() tea.Msg {
+		if place == nil {
+			archive, err := weather.FetchArchiveWithConfig(cfg, date, date)
+			return ArchiveMsg{Date: date, Archive: archive, Error: err}
+		}
+
+		archive, err := weather.FetchArchive(fmt.Sprintf("%.4f,%.4f", place.Lat, place.Lon), date, date)
+		if err != nil {
+			return ArchiveMsg{Date: date, Error: err}
+		}
+		for _, w := range archive.Hours {
+			w.Location.Name = place.Location
+			w.Location.Region = place.Region
+			w.Location.Country = place.Country
+		}
+		return ArchiveMsg{Date: date, Archive: archive}
+	}
+}
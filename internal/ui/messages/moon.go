@@ -6,6 +6,7 @@ import (
 
 // MoonDataMsg is sent when moon phase data is fetched.
 type MoonDataMsg struct {
-	Data  *components.MoonResponse
-	Error error
+	Data   *components.MoonResponse
+	Source string // Where the data came from, see components.MoonSourceFarmsense
+	Error  error
 }
@@ -3,62 +3,126 @@
 package messages
 
 import (
-	"fmt"
-
+	"wms/internal/cache"
 	"wms/internal/config"
+	"wms/internal/history"
 	"wms/internal/weather"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// maxConcurrentFetches limits how many saved locations are fetched at the same
+// time, so a long list of locations does not flood the weather provider.
+const maxConcurrentFetches = 3
+
 // WeatherMsg is a message that is sent when weather data has been fetched. It
 // contains either the weather data or an error if the fetch failed.
 type WeatherMsg struct {
-	Weather *weather.Weather
-	Error   error
+	Weather        *weather.Weather
+	Error          error
+	LocationSource string          // Where the location came from ("gpsd", "ipinfo", ...), empty for manual locations
+	Provider       string          // The weather provider that was asked
+	Fix            *weather.GPSFix // The GPS position the weather is for, nil unless it came from gpsd
+}
+
+// GPSFixMsg is sent when gpsd reports a position that has moved far enough to
+// warrant a refetch, or when the connection to gpsd failed.
+type GPSFixMsg struct {
+	Fix   *weather.GPSFix
+	Error error
+}
+
+// LocationWeatherMsg is sent when the weather for one of the saved locations
+// has been fetched. Index identifies the card in the multi-location view.
+type LocationWeatherMsg struct {
+	Index    int
+	Location string
+	Weather  *weather.Weather
+	Error    error
 }
 
 // FetchWeatherWithConfigCmd creates a Bubble Tea command that fetches weather
 // data using the new provider system. It takes a Config struct and returns a
-// command function that can be executed by the Bubble Tea runtime.
-func FetchWeatherWithConfigCmd(cfg config.Config) tea.Cmd {
+// command function that can be executed by the Bubble Tea runtime. In GPS
+// mode, a fix gpsd already reported is used as the location instead of
+// reading a new one; without one, the fetch asks gpsd itself.
+func FetchWeatherWithConfigCmd(cfg config.Config, fix *weather.GPSFix) tea.Cmd {
 	return func() tea.Msg {
-		// Determine location based on LocationMode setting
-		var location string
-		if cfg.LocationMode == "ip" || cfg.Location == "" {
-			// Attempt to automatically detect the user's location via their IP address.
-			detectedLocation, err := weather.DetectLocationFromIP()
-			if err != nil {
-				return WeatherMsg{
-					Weather: nil,
-					Error:   fmt.Errorf("failed to detect location: %w", err),
-				}
-			}
-			location = detectedLocation
-		} else {
-			// Use the manually specified location
-			location = cfg.Location
-		}
-
-		// Create a weather provider based on the configuration.
-		provider, err := weather.CreateWeatherProvider(cfg.WeatherProvider, cfg.WeatherAPIKey)
+		weatherData, detected, err := weather.FetchAtFix(cfg, fix)
 		if err != nil {
-			return WeatherMsg{Error: fmt.Errorf("failed to create weather provider: %w", err)}
+			return WeatherMsg{Weather: nil, Error: err, Provider: cfg.WeatherProvider}
 		}
 
-		// Fetch the weather data using the provider.
-		weatherData, err := provider.FetchWeather(location)
-		if err != nil {
-			return WeatherMsg{
-				Weather: nil,
-				Error:   fmt.Errorf("failed to fetch weather: %w", err),
+		var source string
+		var used *weather.GPSFix
+		if detected != nil {
+			source = detected.Source
+			if source == "gpsd" {
+				used = &weather.GPSFix{Lat: detected.Lat, Lon: detected.Lon}
 			}
 		}
 
+		// Keep the on-disk cache current for "wms status" and record the
+		// observation. A failed write only affects status lines and the
+		// history, so it is not reported here.
+		cache.Save(cfg, weatherData, source)
+		history.Record(weatherData, cfg.WeatherProvider, cfg.History)
+
 		// Return the weather data in a WeatherMsg.
 		return WeatherMsg{
-			Weather: weatherData,
-			Error:   nil,
+			Weather:        weatherData,
+			Error:          nil,
+			LocationSource: source,
+			Provider:       cfg.WeatherProvider,
+			Fix:            used,
+		}
+	}
+}
+
+// FetchLocationsWeatherCmd creates a command that fetches the weather for every
+// given location. Each location reports back with its own LocationWeatherMsg so
+// cards can be filled in as soon as their data arrives, while a shared
+// semaphore keeps at most maxConcurrentFetches requests in flight.
+func FetchLocationsWeatherCmd(cfg config.Config, locations []string) tea.Cmd {
+	sem := make(chan struct{}, maxConcurrentFetches)
+
+	cmds := make([]tea.Cmd, 0, len(locations))
+	for i, location := range locations {
+		index, location := i, location
+		cmds = append(cmds, func() tea.Msg {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			weatherData, err := weather.FetchLocation(cfg, location)
+			if err == nil {
+				history.Record(weatherData, cfg.WeatherProvider, cfg.History)
+			}
+			return LocationWeatherMsg{
+				Index:    index,
+				Location: location,
+				Weather:  weatherData,
+				Error:    err,
+			}
+		})
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// WatchGPSCmd creates a command that waits for gpsd to report a significant
+// change of position. Without a previous fix it returns the first fix gpsd
+// reports. The command reports an error once the connection to gpsd is lost.
+func WatchGPSCmd(cfg config.Config, from *weather.GPSFix) tea.Cmd {
+	return func() tea.Msg {
+		client := weather.NewGPSDClient(cfg.GPSDAddress)
+
+		var fix *weather.GPSFix
+		var err error
+		if from == nil {
+			fix, err = client.WaitForMove(weather.GPSFix{}, 0)
+		} else {
+			fix, err = client.WaitForMove(*from, cfg.GPSMinDistanceKm)
 		}
+		return GPSFixMsg{Fix: fix, Error: err}
 	}
 }
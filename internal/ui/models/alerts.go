@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"wms/internal/ui/messages"
+	"wms/internal/ui/styles"
+	"wms/internal/weather"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// checkAlerts checks the alert rules against the weather and returns the
+// commands for the notifications and commands of the alerts it raised.
+func (m *Model) checkAlerts(w *weather.Weather) []tea.Cmd {
+	var cmds []tea.Cmd
+	for _, a := range m.alerts.Check(m.config.Alerts, w, time.Now()) {
+		if cmd := messages.AlertActionsCmd(m.config.Alerts[a.Name], a); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+// alertText lists the active alerts, or is empty when there are none.
+func (m Model) alertText() string {
+	active := m.alerts.Active()
+	if len(active) == 0 {
+		return ""
+	}
+	texts := make([]string, 0, len(active))
+	for _, a := range active {
+		texts = append(texts, a.Message())
+	}
+	return "🔔 " + strings.Join(texts, " · ")
+}
+
+// renderAlertBanner renders the active alerts below the header, in every
+// tab, until their conditions no longer hold.
+func (m Model) renderAlertBanner() string {
+	text := m.alertText()
+	if text == "" {
+		return ""
+	}
+	return lipgloss.PlaceHorizontal(m.width, lipgloss.Center, styles.WarningStyle.Copy().MaxWidth(m.width).Render(text))
+}
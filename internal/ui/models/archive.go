@@ -0,0 +1,353 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"wms/internal/history"
+	"wms/internal/ui/components"
+	"wms/internal/ui/messages"
+	"wms/internal/ui/styles"
+	"wms/internal/weather"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// archiveFirstDay is the earliest day the Open-Meteo archive covers.
+var archiveFirstDay = time.Date(1940, time.January, 1, 0, 0, 0, 0, time.Local)
+
+// archiveDay is a past day looked up in the archive, which the History tab
+// shows instead of the recorded days.
+type archiveDay struct {
+	date    time.Time
+	loading bool
+	err     error
+	hours   []history.Observation // Hour by hour, oldest first
+	summary history.Day
+}
+
+// openDatePicker opens the date picker of the History tab on the day shown
+// from the archive, or on yesterday.
+func (m *Model) openDatePicker() {
+	h := &m.history
+	h.picking = true
+	h.pickDate = lastArchiveDay()
+	if h.archive != nil {
+		h.pickDate = h.archive.date
+	}
+}
+
+// lastArchiveDay returns yesterday, the latest day that can be looked up.
+// The archive lags a few days behind, so the last days may not be found yet.
+func lastArchiveDay() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.Local)
+}
+
+// updateDatePicker handles the keys of the date picker: left and right move
+// by a day, up and down by a week, [ and ] by a month. Enter looks up the
+// selected day and Esc closes the picker.
+func (m Model) updateDatePicker(msg tea.KeyMsg) (Model, tea.Cmd) {
+	h := &m.history
+	date := h.pickDate
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		h.picking = false
+		return m, nil
+	case "enter":
+		h.picking = false
+		return m, m.lookUpArchiveDay(date)
+	case "left", "h":
+		date = date.AddDate(0, 0, -1)
+	case "right", "l":
+		date = date.AddDate(0, 0, 1)
+	case "up", "k":
+		date = date.AddDate(0, 0, -7)
+	case "down", "j":
+		date = date.AddDate(0, 0, 7)
+	case "[", "pgup":
+		date = date.AddDate(0, -1, 0)
+	case "]", "pgdown":
+		date = date.AddDate(0, 1, 0)
+	case "home":
+		date = lastArchiveDay()
+	}
+	h.pickDate = clampArchiveDay(date)
+	return m, nil
+}
+
+// clampArchiveDay limits a date to the days the archive can be asked about.
+func clampArchiveDay(date time.Time) time.Time {
+	if last := lastArchiveDay(); date.After(last) {
+		return last
+	}
+	if date.Before(archiveFirstDay) {
+		return archiveFirstDay
+	}
+	return date
+}
+
+// lookUpArchiveDay shows a past day in the History tab and looks up its
+// weather at the place shown. Without recorded weather, the current location
+// is used.
+func (m *Model) lookUpArchiveDay(date time.Time) tea.Cmd {
+	m.history.archive = &archiveDay{date: date, loading: true}
+
+	var place *history.Observation
+	for _, o := range m.history.observations {
+		if o.Place() == m.history.place {
+			o := o
+			place = &o
+		}
+	}
+	if place == nil && m.stormyWeather != nil {
+		o := history.FromWeather(m.stormyWeather, m.config.WeatherProvider, time.Now())
+		place = &o
+	}
+	return messages.FetchArchiveCmd(m.config, place, date)
+}
+
+// updateArchive stores a looked up day, unless another day was asked for in
+// the meantime.
+func (m *Model) updateArchive(msg messages.ArchiveMsg) {
+	a := m.history.archive
+	if a == nil || !a.date.Equal(msg.Date) {
+		return
+	}
+	a.loading = false
+	a.err = msg.Error
+	if msg.Error != nil {
+		return
+	}
+
+	a.hours = history.FromArchive(msg.Archive)
+	if days := history.Daily(a.hours); len(days) > 0 {
+		a.summary = days[0]
+	}
+	// The archive reports what fell in each hour, including the last one
+	a.summary.PrecipMm = 0
+	for _, o := range a.hours {
+		a.summary.PrecipMm += o.PrecipMm
+	}
+}
+
+// updateArchiveView handles the keys of the History tab while it shows a day
+// from the archive: left and right look up the day before and after, Esc
+// returns to the recorded days.
+func (m Model) updateArchiveView(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	date := m.history.archive.date
+	switch msg.String() {
+	case "esc":
+		m.history.archive = nil
+		return m, nil, true
+	case "left", "h":
+		date = date.AddDate(0, 0, -1)
+	case "right", "l":
+		date = date.AddDate(0, 0, 1)
+	default:
+		return m, nil, false
+	}
+	if date = clampArchiveDay(date); date.Equal(m.history.archive.date) {
+		return m, nil, true
+	}
+	return m, m.lookUpArchiveDay(date), true
+}
+
+// renderDatePicker renders a calendar of the month of the selected day, with
+// the days that cannot be looked up dimmed.
+func (m Model) renderDatePicker() string {
+	date := m.history.pickDate
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.Local)
+	last := lastArchiveDay()
+
+	selected := lipgloss.NewStyle().Foreground(styles.TextInverse).Background(styles.Primary)
+	muted := lipgloss.NewStyle().Foreground(styles.TextMuted)
+
+	lines := []string{
+		styles.H3Style.Render("Look up a past day"),
+		"",
+		styles.BodyStyle.Render(first.Format("January 2006")),
+		styles.CaptionStyle.Render("Mo Tu We Th Fr Sa Su"),
+	}
+	// Weeks start on Monday
+	offset := (int(first.Weekday()) + 6) % 7
+	week := strings.Repeat("   ", offset)
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		cell := fmt.Sprintf("%2d", day.Day())
+		switch {
+		case day.Equal(date):
+			cell = selected.Render(cell)
+		case day.After(last) || day.Before(archiveFirstDay):
+			cell = muted.Render(cell)
+		}
+		week += cell
+		if day.Weekday() == time.Sunday {
+			lines = append(lines, week)
+			week = ""
+		} else {
+			week += " "
+		}
+	}
+	if week != "" {
+		lines = append(lines, week+strings.Repeat(" ", 20-lipgloss.Width(week)))
+	}
+
+	lines = append(lines,
+		"",
+		styles.BodyStyle.Render(date.Format("Monday, January 2, 2006")),
+		"",
+		styles.CaptionStyle.Render("←/→ Day   ↑/↓ Week   [/] Month"),
+		styles.CaptionStyle.Render("Enter Look up   Esc Cancel"),
+	)
+	return lipgloss.JoinVertical(lipgloss.Center, lines...)
+}
+
+// createArchivePanelContent renders a day from the archive in width cells:
+// charts of the hourly conditions and a summary of the day.
+func (m Model) createArchivePanelContent(width int) string {
+	a := m.history.archive
+	title := styles.H3Style.Render(a.date.Format("Mon Jan 2, 2006") + " · archive")
+	keys := styles.CaptionStyle.Render("←/→ Day   A Date   Esc Recorded days")
+	switch {
+	case a.loading:
+		return lipgloss.JoinVertical(lipgloss.Center,
+			title, "", styles.LoadingStyle.Render("⏳ Looking up archived weather..."), "", keys)
+	case a.err != nil:
+		return lipgloss.JoinVertical(lipgloss.Center,
+			title, "",
+			styles.ErrorStyle.Render("⚠️ Archived weather unavailable"),
+			styles.CaptionStyle.Render(a.err.Error()),
+			"", keys)
+	}
+
+	title = styles.H3Style.Render(fmt.Sprintf("%s · %s (archive)", a.date.Format("Mon Jan 2, 2006"), a.hours[0].Place()))
+	lines := []string{title, ""}
+	if charts := m.renderArchiveCharts(width); charts != "" {
+		lines = append(lines, charts, "")
+	}
+	lines = append(lines,
+		m.historyDayDetails(a.summary),
+		styles.BodyStyle.Render(fmt.Sprintf("%s of precipitation, mostly %s",
+			m.historyPrecip(a.summary), strings.ToLower(archiveCondition(a.hours)))),
+		"",
+		keys,
+	)
+	return lipgloss.JoinVertical(lipgloss.Center, lines...)
+}
+
+// renderArchiveCharts renders the hours of a day from the archive like the
+// hourly forecast: the temperature as a line, and precipitation, wind and
+// pressure as sparklines, above a time axis. It is empty without room for it.
+func (m Model) renderArchiveCharts(width int) string {
+	hours := m.history.archive.hours
+	chartWidth := min(width-forecastLabelWidth-forecastRangeWidth, 2*len(hours))
+	if chartWidth < 12 || len(hours) < 2 {
+		return ""
+	}
+
+	imperial := m.config.Units == "imperial"
+	tempUnit, rainUnit, windUnit, rainFloor := "°C", " mm", " km/h", 1.0
+	if imperial {
+		tempUnit, rainUnit, windUnit, rainFloor = "°F", " in", " mph", 0.04
+	}
+	var temps, rain, wind, pressure []float64
+	axisHours := make([]weather.HourlyForecast, 0, len(hours))
+	for _, o := range hours {
+		if imperial {
+			temps, rain, wind = append(temps, o.TempF), append(rain, o.PrecipMm/25.4), append(wind, o.WindMph)
+		} else {
+			temps, rain, wind = append(temps, o.TempC), append(rain, o.PrecipMm), append(wind, o.WindKph)
+		}
+		pressure = append(pressure, m.historyPressure(o.PressureMb))
+		axisHours = append(axisHours, weather.HourlyForecast{Time: o.LocalTime})
+	}
+
+	tempColor := func(v float64) lipgloss.Color {
+		if imperial {
+			v = (v - 32) * 5 / 9
+		}
+		return styles.TemperatureAccent(v)
+	}
+	_, maxRain := valueRange(rain)
+	tempChart := components.Chart{Values: temps, Color: tempColor}
+	rainChart := components.Chart{Values: rain, Min: 0, Max: math.Max(maxRain, rainFloor), Color: func(float64) lipgloss.Color { return styles.RainColor }}
+	windChart := components.Chart{Values: wind, Color: func(float64) lipgloss.Color { return styles.TextSecondary }}
+	pressureChart := components.Chart{Values: pressure, Color: func(float64) lipgloss.Color { return styles.TextSecondary }}
+
+	label := func(text string) string {
+		return styles.CaptionStyle.Render(fmt.Sprintf("%-*s", forecastLabelWidth, text))
+	}
+	span := func(values []float64, format, unit string) string {
+		lo, hi := valueRange(values)
+		return styles.CaptionStyle.Render(fmt.Sprintf(" "+format+"…"+format+"%s", lo, hi, unit))
+	}
+	pressureFormat, pressureUnit := "%.0f", " hPa"
+	if imperial {
+		pressureFormat, pressureUnit = "%.2f", " inHg"
+	}
+
+	var lines []string
+	for i, row := range tempChart.Braille(chartWidth, forecastTempHeight) {
+		if i == 0 {
+			lines = append(lines, label("Temp")+row+span(temps, "%.0f", tempUnit))
+		} else {
+			lines = append(lines, label("")+row)
+		}
+	}
+	lines = append(lines,
+		label("Rain")+rainChart.Sparkline(chartWidth)+styles.CaptionStyle.Render(fmt.Sprintf(" max %.1f%s", maxRain, rainUnit)),
+		label("Wind")+windChart.Sparkline(chartWidth)+span(wind, "%.0f", windUnit),
+		label("Press")+pressureChart.Sparkline(chartWidth)+span(pressure, pressureFormat, pressureUnit),
+		label("")+styles.CaptionStyle.Render(m.forecastAxis(axisHours, chartWidth)),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// archiveCondition returns the condition reported for the most hours.
+func archiveCondition(hours []history.Observation) string {
+	counts := make(map[string]int)
+	best := ""
+	for _, o := range hours {
+		counts[o.Condition]++
+		if counts[o.Condition] > counts[best] {
+			best = o.Condition
+		}
+	}
+	return best
+}
+
+// compactArchiveLines renders the date picker or a day from the archive as a
+// few lines for the compact layout.
+func (m Model) compactArchiveLines() []string {
+	h := m.history
+	if h.picking {
+		return []string{
+			"Look up " + h.pickDate.Format("Mon Jan 2, 2006"),
+			"←/→ Day ↑/↓ Week [/] Month",
+			"Enter Look up  Esc Cancel",
+		}
+	}
+
+	a := h.archive
+	header := a.date.Format("Mon Jan 2, 2006") + " (archive)"
+	switch {
+	case a.loading:
+		return []string{header, "⏳ Looking up..."}
+	case a.err != nil:
+		return []string{header, "⚠ " + a.err.Error()}
+	}
+	return []string{
+		header,
+		a.hours[0].Place(),
+		fmt.Sprintf("↑%s ↓%s %s",
+			m.historyTemperature(a.summary.HighC, a.summary.HighF),
+			m.historyTemperature(a.summary.LowC, a.summary.LowF),
+			m.historyPrecip(a.summary)),
+		archiveCondition(a.hours),
+	}
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"wms/internal/ui/icons"
+	"wms/internal/ui/styles"
+	"wms/internal/weather"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// compact reports whether the compact layout is used: when it was asked for
+// with compact = true or -compact, or when the window is too small for the
+// card layout.
+func (m Model) compact() bool {
+	return m.config.Compact || m.width < styles.MinTerminalWidth || m.height < styles.MinTerminalHeight
+}
+
+// renderCompact renders the screen as a few summary lines without borders or
+// ASCII art, for small terminals and split panes. Weather, moon and sun are
+// shown together, and the whole screen fits in 40×10 cells. Lines that do not
+// fit are cut off rather than wrapped.
+func (m Model) renderCompact() string {
+	var body []string
+	switch m.viewMode {
+	case ViewWeather, ViewMoon, ViewSolar:
+		body = append(body, m.compactWeatherLines()...)
+		body = append(body, m.compactMoonLine(), m.compactSunLine())
+	case ViewLocations:
+		body = m.compactLocationLines()
+	case ViewHistory:
+		body = m.compactHistoryLines()
+	case ViewSettings:
+		body = strings.Split(m.renderSettings(), "\n")
+	case ViewLocationInput:
+		body = strings.Split(m.renderLocationInput(), "\n")
+	case ViewAPIKeyInput:
+		body = strings.Split(m.renderAPIKeyInput(), "\n")
+	}
+
+	// The header and footer always stay; the body gives up its last lines
+	// when the window is too short
+	header := []string{m.compactHeader()}
+	if text := m.alertText(); text != "" {
+		header = append(header, styles.WarningStyle.Render(text))
+	}
+	var footer []string
+	if banner := m.renderErrorBanner(); banner != "" {
+		footer = append(footer, styles.ErrorStyle.Render("⚠ Refresh failed"))
+	} else if status := m.renderStatusLine(); status != "" {
+		footer = append(footer, status)
+	}
+	footer = append(footer, m.compactControls())
+
+	room := max(m.height-len(header)-len(footer), 0)
+	if len(body) > room {
+		body = body[:room]
+	}
+
+	lines := append(append(header, body...), footer...)
+	clip := lipgloss.NewStyle().MaxWidth(m.width)
+	for i, line := range lines {
+		lines[i] = clip.Render(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// compactHeader renders the clock and location, with a spinner while
+// refreshing.
+func (m Model) compactHeader() string {
+	header := styles.ClockStyle.Render(m.formatClock(m.time)) + " 📍 " + getLocationDisplay(m)
+	if m.refreshing || m.locationsLoading() {
+		header += " " + lipgloss.NewStyle().Foreground(styles.Info).Render(spinnerFrames[m.spinnerFrame])
+	}
+	return header
+}
+
+// compactWeatherLines renders the current weather as two lines.
+func (m Model) compactWeatherLines() []string {
+	if m.stormyWeather == nil {
+		if m.weatherError != nil {
+			return []string{"⚠️ Weather unavailable"}
+		}
+		return []string{"⏳ Loading weather..."}
+	}
+
+	w := m.stormyWeather
+	display := weather.FormatWeatherDisplay(w, m.config)
+	lines := []string{
+		icons.GetWeatherEmoji(w.Current.Condition, w.Current.IsDay == 1) + " " +
+			styles.TemperatureStyle.Copy().Foreground(display.Accent).Render(display.Temperature) + " " + display.Condition,
+		styles.CaptionStyle.Render("   feels " + display.FeelsLike + " · " + display.Wind + " · " + display.Humidity),
+	}
+	if m.weatherStatus.stale(m.refreshInterval()) {
+		for i, line := range lines {
+			lines[i] = dim(line)
+		}
+	}
+	return lines
+}
+
+// compactMoonLine renders the moon phase as a single line.
+func (m Model) compactMoonLine() string {
+	switch {
+	case m.moon.IsLoading:
+		return "⏳ Loading moon..."
+	case m.moon.Error != nil:
+		return "⚠️ Moon unavailable"
+	}
+
+	line := m.moon.Icon + " " + styles.MoonPhaseStyle.Render(m.moon.Phase) + fmt.Sprintf(" %.0f%%", m.moon.Illumination)
+	if m.moonStatus.stale(m.refreshInterval()) {
+		line = dim(line)
+	}
+	return line
+}
+
+// compactSunLine renders sunrise, sunset and the length of the day as a
+// single line.
+func (m Model) compactSunLine() string {
+	hours := int(m.sun.DayLength.Hours())
+	minutes := int(m.sun.DayLength.Minutes()) % 60
+	return fmt.Sprintf("%s ↑%s ↓%s · %dh %dm",
+		m.sun.Icon,
+		styles.SunTimeStyle.Render(m.formatClock(m.sun.Sunrise)),
+		styles.SunTimeStyle.Render(m.formatClock(m.sun.Sunset)),
+		hours, minutes)
+}
+
+// compactLocationLines renders one line per saved location.
+func (m Model) compactLocationLines() []string {
+	if len(m.locationCards) == 0 {
+		return []string{"📍 No saved locations"}
+	}
+
+	var lines []string
+	for _, card := range m.locationCards {
+		name := card.location
+		switch {
+		case card.weather != nil:
+			display := weather.FormatWeatherDisplay(card.weather, m.config)
+			lines = append(lines, fmt.Sprintf("%s %s %s %s",
+				icons.GetWeatherEmoji(card.weather.Current.Condition, card.weather.Current.IsDay == 1),
+				name,
+				styles.TemperatureStyle.Copy().Foreground(display.Accent).Render(display.Temperature),
+				display.Condition))
+		case card.loading:
+			lines = append(lines, "⏳ "+name)
+		default:
+			lines = append(lines, "⚠️ "+name+" unavailable")
+		}
+	}
+	return lines
+}
+
+// compactControls renders the key bindings in a single short line, with the
+// countdown to the next refresh.
+func (m Model) compactControls() string {
+	countdown := m.refreshCountdown()
+	if countdown == "" {
+		countdown = "..."
+	}
+	return styles.CaptionStyle.Render(fmt.Sprintf("r %s · u %s · s settings · q quit", countdown, m.config.Units))
+}
+
+// formatClock formats a time of day without seconds, honoring the time
+// format setting.
+func (m Model) formatClock(t time.Time) string {
+	if m.config.TimeFormat == "12" {
+		return t.Format("3:04 PM")
+	}
+	return t.Format("15:04")
+}
@@ -2,9 +2,11 @@ package models
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"wms/internal/alert"
 	"wms/internal/config"
 	"wms/internal/ui/components"
 	"wms/internal/ui/messages"
@@ -16,7 +18,25 @@ import (
 )
 
 type tickMsg time.Time
-type refreshMsg time.Time
+type spinnerMsg time.Time
+type gpsRetryMsg time.Time
+
+// refreshMsg triggers an automatic refresh. Only the message of the most
+// recently scheduled refresh is acted on, so rescheduling never leaves older
+// timers firing in between.
+type refreshMsg struct {
+	seq int
+}
+
+// gpsRetryInterval is how long to wait before reconnecting to gpsd after the
+// connection was lost or could not be established.
+const gpsRetryInterval = 30 * time.Second
+
+// spinnerInterval is how often the refresh spinner in the header advances.
+const spinnerInterval = 100 * time.Millisecond
+
+// spinnerFrames are the frames of the refresh spinner.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 
 type ViewMode int
 
@@ -24,11 +44,16 @@ const (
 	ViewWeather ViewMode = iota // Stormy-style weather tab
 	ViewMoon
 	ViewSolar
+	ViewLocations     // Side-by-side cards for every saved location
+	ViewHistory       // Daily trends from the recorded observations
 	ViewSettings      // A new view for the settings menu
 	ViewLocationInput // For text input, accessed from settings
 	ViewAPIKeyInput   // For API key input, accessed from settings
 )
 
+// mainViewCount is the number of tabs that can be cycled with Tab/Shift+Tab.
+const mainViewCount = 5
+
 // Model represents the state of the entire application. It contains all the
 // data and settings needed to render the TUI.
 type Model struct {
@@ -43,26 +68,58 @@ type Model struct {
 
 	// Time and refresh data
 	time        time.Time
-	lastRefresh time.Time
+	nextRefresh time.Time // When the next automatic refresh is due, zero while fetching
+	refreshSeq  int       // Identifies the scheduled refresh, see refreshMsg
+
+	// When each data source was last updated, and by which provider
+	weatherStatus   sourceStatus
+	moonStatus      sourceStatus
+	sunStatus       sourceStatus
+	locationsStatus sourceStatus // The location cards, as a whole
 
 	// View management
-	viewMode    ViewMode
-	refreshing  bool
-	statusMsg   string
-	statusTimer time.Time
+	viewMode     ViewMode
+	tabsForced   bool // Keys 1-3 show single panels although the configured layout is the dashboard
+	refreshing   bool // Weather is being fetched; the last data stays visible meanwhile
+	spinning     bool // The spinner is animating, see startRefreshing
+	spinnerFrame int
+	statusMsg    string
+	statusTimer  time.Time
 
 	// Configuration
-	config config.Config
+	config       config.Config
+	configIssues []config.Issue     // Shown in the status area until resolved
+	configFlags  *config.Flags      // Command-line overrides, re-applied on reload
+	flagUpdates  chan *config.Flags // Hands changed flags to the config watcher
 
 	// New weather system state
-	stormyWeather *weather.Weather
-	weatherError  error
+	stormyWeather  *weather.Weather
+	weatherError   error
+	locationSource string // Where the current location came from, empty for manual locations
+
+	// GPS state, used when the location mode is "gps"
+	gpsFix      *weather.GPSFix // The latest position reported by the watcher
+	weatherFix  *weather.GPSFix // The position the shown weather was fetched for
+	gpsWatching bool
+
+	// Multi-location view state, one card per saved location
+	locationCards []locationCard
+
+	// History tab state
+	history historyView
+
+	// Alerts raised by the rules in [alerts], shown as a banner while active
+	alerts alert.Tracker
 
 	// Location input state
 	isEditingLocation bool
 	locationInput     string
 	settingsCursor    int // For navigating the settings menu
 
+	// Settings changed in the TUI, by TOML key; only these are written by
+	// "Save and Exit"
+	editedSettings map[string]bool
+
 	// API key input state
 	isEditingAPIKey bool
 	apiKeyInput     string
@@ -82,32 +139,54 @@ func InitialModelWithConfig(cfg config.Config) Model {
 		moon:              components.NewMoon(),
 		sun:               components.NewSun(),
 		time:              now,
-		lastRefresh:       now,
+		sunStatus:         sourceStatus{updated: now, provider: sunSource},
 		viewMode:          ViewWeather,
-		refreshing:        false,
+		refreshing:        true, // Init fetches the weather
+		spinning:          true, // Init starts the spinner
 		statusMsg:         "",
 		statusTimer:       now,
 		config:            cfg,
 		stormyWeather:     nil,
 		weatherError:      nil,
+		gpsWatching:       cfg.LocationMode == "gps", // Init starts the GPS watcher
 		isEditingLocation: false,
 		locationInput:     cfg.Location,
 		settingsCursor:    0,
 		isEditingAPIKey:   false,
 		apiKeyInput:       cfg.WeatherAPIKey,
+		flagUpdates:       make(chan *config.Flags, 1),
 	}
 }
 
+// WithConfigIssues returns a copy of the model that shows the given
+// configuration issues in the status area.
+func (m Model) WithConfigIssues(issues []config.Issue) Model {
+	m.configIssues = issues
+	return m
+}
+
+// revalidateConfig refreshes the configuration issues after a setting was
+// changed from inside the TUI.
+func (m *Model) revalidateConfig() {
+	checked := m.config
+	m.configIssues = config.ValidateConfig(&checked)
+}
+
 // Init is the first command that is executed when the application starts. It
 // initializes the timers and fetches the initial data.
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		tickCmd(),
-		refreshCmd(),
+		spinnerCmd(),
 		tea.WindowSize(),
-		messages.FetchWeatherWithConfigCmd(m.config),
+		m.fetchWeatherCmd(),
 		m.fetchMoonDataCmd(), // Fetch moon data on init
-	)
+		m.watchConfigCmd(),
+	}
+	if m.config.LocationMode == "gps" {
+		cmds = append(cmds, messages.WatchGPSCmd(m.config, nil))
+	}
+	return tea.Batch(cmds...)
 }
 
 // tickCmd creates a command that sends a tick message every second. This is
@@ -118,22 +197,63 @@ func tickCmd() tea.Cmd {
 	})
 }
 
-// refreshCmd creates a command that sends a refresh message at the configured
-// interval. This is used to automatically refresh the weather data.
-func refreshCmd() tea.Cmd {
-	return tea.Tick(5*time.Minute, func(t time.Time) tea.Msg {
-		return refreshMsg(t)
+// spinnerCmd creates a command that advances the refresh spinner.
+func spinnerCmd() tea.Cmd {
+	return tea.Tick(spinnerInterval, func(t time.Time) tea.Msg {
+		return spinnerMsg(t)
+	})
+}
+
+// startRefreshing marks the weather as being refreshed and starts the
+// spinner. The data shown so far stays visible until the new data arrives.
+func (m *Model) startRefreshing() tea.Cmd {
+	m.refreshing = true
+	return m.startSpinner()
+}
+
+// startSpinner starts the spinner unless it is already running. It stops by
+// itself once nothing is being fetched anymore.
+func (m *Model) startSpinner() tea.Cmd {
+	if m.spinning {
+		return nil
+	}
+	m.spinning = true
+	return spinnerCmd()
+}
+
+// refreshCmd creates a command that sends a refresh message after the given
+// delay. This is used to automatically refresh the weather data.
+func refreshCmd(delay time.Duration, seq int) tea.Cmd {
+	return tea.Tick(delay, func(t time.Time) tea.Msg {
+		return refreshMsg{seq: seq}
+	})
+}
+
+// scheduleRefresh plans the next automatic refresh one refresh interval from
+// now, replacing any refresh that was planned before.
+func (m *Model) scheduleRefresh() tea.Cmd {
+	interval := time.Duration(m.config.RefreshInterval) * time.Minute
+	m.refreshSeq++
+	m.nextRefresh = time.Now().Add(interval)
+	return refreshCmd(interval, m.refreshSeq)
+}
+
+// gpsRetryCmd creates a command that sends a retry message after the gpsd
+// connection was lost, so the GPS watcher can reconnect.
+func gpsRetryCmd() tea.Cmd {
+	return tea.Tick(gpsRetryInterval, func(t time.Time) tea.Msg {
+		return gpsRetryMsg(t)
 	})
 }
 
 // fetchMoonDataCmd creates a command to fetch moon data.
 func (m *Model) fetchMoonDataCmd() tea.Cmd {
 	return func() tea.Msg {
-		data, err := components.FetchMoonData()
+		data, source, err := components.FetchMoonData()
 		if err != nil {
 			return messages.MoonDataMsg{Error: err}
 		}
-		return messages.MoonDataMsg{Data: data}
+		return messages.MoonDataMsg{Data: data, Source: source}
 	}
 }
 
@@ -151,6 +271,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.isEditingAPIKey {
 			return m.updateAPIKeyInputView(msg)
 		}
+		// While picking a date for the archive, only handle picker keys
+		if m.history.picking && m.viewMode == ViewHistory {
+			return m.updateDatePicker(msg)
+		}
 
 		// Global keybindings that work in any view
 		switch msg.String() {
@@ -158,19 +282,37 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "1":
 			m.viewMode = ViewWeather
+			m.tabsForced = true
 			return m, nil
 		case "2":
 			m.viewMode = ViewMoon
+			m.tabsForced = true
 			return m, nil
 		case "3":
 			m.viewMode = ViewSolar
+			m.tabsForced = true
 			return m, nil
+		case "4":
+			m.viewMode = ViewLocations
+			var cmd tea.Cmd
+			if m.locationsStale() {
+				cmd = m.locationsCmd()
+			}
+			return m, cmd
+		case "5":
+			m.viewMode = ViewHistory
+			return m, m.historyCmd()
 		case "r":
-			m.refreshing = true
 			m.statusMsg = "Refreshing..."
-			m.stormyWeather = nil
-			m.weatherError = nil
-			return m, messages.FetchWeatherWithConfigCmd(m.config)
+			m.statusTimer = time.Now()
+			cmds := []tea.Cmd{m.startRefreshing(), m.fetchWeatherCmd()}
+			if m.viewMode == ViewLocations {
+				cmds = append(cmds, m.locationsCmd())
+			}
+			if m.viewMode == ViewHistory {
+				cmds = append(cmds, m.historyCmd())
+			}
+			return m, tea.Batch(cmds...)
 		case "u":
 			// Cycle through all combinations of units and time formats
 			switch {
@@ -190,7 +332,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.statusMsg = "Units: Metric, Time: 24h"
 			}
 			m.statusTimer = time.Now()
-			return m, messages.FetchWeatherWithConfigCmd(m.config)
+			m.markEdited("units", "time_format")
+			return m, m.fetchWeatherCmd()
 		case "t":
 			// Toggle time format
 			if m.config.TimeFormat == "24" {
@@ -201,6 +344,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.statusMsg = "Time: 24-hour"
 			}
 			m.statusTimer = time.Now()
+			m.markEdited("time_format")
+			return m, nil
+		case "d":
+			// Switch between one panel at a time and all of them together
+			if m.viewMode < ViewSettings {
+				m.toggleLayout()
+				m.markEdited("layout")
+				m.statusTimer = time.Now()
+			}
 			return m, nil
 		case "s":
 			// Open the settings menu
@@ -212,7 +364,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Mode-specific keybindings
 		switch m.viewMode {
-		case ViewWeather, ViewMoon, ViewSolar:
+		case ViewWeather, ViewMoon, ViewSolar, ViewLocations, ViewHistory:
 			return m.updateMainView(msg)
 		case ViewSettings:
 			return m.updateSettingsView(msg)
@@ -226,30 +378,101 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tickMsg:
 		m.time = time.Now()
 		m.sun = components.NewSun()
+		m.sunStatus.succeed(sunSource)
 		if time.Since(m.statusTimer) > 3*time.Second {
 			m.statusMsg = ""
 		}
 		return m, tickCmd()
 
+	case spinnerMsg:
+		if !m.refreshing && !m.locationsLoading() {
+			m.spinning = false
+			return m, nil
+		}
+		m.spinnerFrame = (m.spinnerFrame + 1) % len(spinnerFrames)
+		return m, spinnerCmd()
+
 	case refreshMsg:
-		return m, messages.FetchWeatherWithConfigCmd(m.config)
+		if msg.seq != m.refreshSeq {
+			return m, nil // Superseded by a later schedule
+		}
+		// The next refresh is scheduled once this one finished
+		m.nextRefresh = time.Time{}
+		cmds := []tea.Cmd{m.startRefreshing(), m.fetchWeatherCmd(), m.fetchMoonDataCmd()}
+		if len(m.locationCards) > 0 {
+			cmds = append(cmds, m.locationsCmd())
+		}
+		return m, tea.Batch(cmds...)
 
 	case messages.WeatherMsg:
 		m.refreshing = false
+		next := m.scheduleRefresh()
 		if msg.Error != nil {
+			// Keep showing the last good weather, the banner explains why
+			// it is not current
 			m.weatherError = msg.Error
-			m.stormyWeather = nil
+			m.weatherStatus.fail()
 		} else {
+			m.weatherStatus.succeed(msg.Provider)
 			m.stormyWeather = msg.Weather
 			m.weatherError = nil
+			m.locationSource = msg.LocationSource
+			m.weatherFix = msg.Fix
+			next = tea.Batch(append([]tea.Cmd{next}, m.checkAlerts(msg.Weather)...)...)
+			if m.config.LocationMode == "gps" && m.gpsFix != nil && !m.showsFix(m.gpsFix) {
+				// The watcher reported a position while this fetch ran
+				m.statusMsg = "Location updated from GPS"
+				next = tea.Batch(next, m.startRefreshing(), m.fetchWeatherCmd())
+			}
+			if m.viewMode == ViewHistory {
+				// The new observation was just recorded
+				next = tea.Batch(next, m.historyCmd())
+			}
 		}
 		m.statusTimer = time.Now()
+		return m, next
+
+	case messages.GPSFixMsg:
+		return m.updateGPSFix(msg)
+
+	case gpsRetryMsg:
+		if m.config.LocationMode == "gps" && !m.gpsWatching {
+			m.gpsWatching = true
+			return m, messages.WatchGPSCmd(m.config, m.gpsFix)
+		}
+		return m, nil
+
+	case messages.ConfigChangedMsg:
+		return m.updateConfig(msg)
+
+	case messages.LocationWeatherMsg:
+		m.updateLocationCard(msg)
+		return m, nil
+
+	case messages.HistoryMsg:
+		m.updateHistory(msg)
+		return m, nil
+
+	case messages.ArchiveMsg:
+		m.updateArchive(msg)
+		return m, nil
+
+	case messages.AlertActionMsg:
+		if msg.Error != nil {
+			m.statusMsg = "Alert " + msg.Name + ": " + msg.Error.Error()
+			m.statusTimer = time.Now()
+		}
 		return m, nil
 
 	case messages.MoonDataMsg:
 		if msg.Error != nil {
-			m.moon.UpdateWithError(msg.Error)
+			m.moonStatus.fail()
+			// Keep showing the last phase rather than an error
+			if m.moonStatus.updated.IsZero() {
+				m.moon.UpdateWithError(msg.Error)
+			}
 		} else if msg.Data != nil {
+			m.moonStatus.succeed(msg.Source)
 			m.moon.UpdateWithData(msg.Data)
 		}
 		return m, nil
@@ -259,15 +482,99 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // updateMainView handles keybindings for the main tabbed view.
 func (m Model) updateMainView(msg tea.KeyMsg) (Model, tea.Cmd) {
-	switch msg.String() {
-	case "tab":
-		m.viewMode = (m.viewMode + 1) % 3 // Simple cycle through main views
-	case "shift+tab":
-		m.viewMode = (m.viewMode - 1 + 3) % 3 // Reverse cycle through main views
+	if m.viewMode == ViewHistory {
+		if updated, cmd, handled := m.updateHistoryView(msg); handled {
+			return updated, cmd
+		}
+	}
+
+	previous := m.viewMode
+	switch {
+	case m.layout() == config.LayoutDashboard && (msg.String() == "tab" || msg.String() == "shift+tab"):
+		// The dashboard takes the place of the first three tabs
+		views := []ViewMode{ViewWeather, ViewLocations, ViewHistory}
+		i := 0
+		for j, view := range views {
+			if view == m.viewMode {
+				i = j
+			}
+		}
+		if msg.String() == "tab" {
+			m.viewMode = views[(i+1)%len(views)]
+		} else {
+			m.viewMode = views[(i+len(views)-1)%len(views)]
+		}
+	case msg.String() == "tab":
+		m.viewMode = (m.viewMode + 1) % mainViewCount // Simple cycle through main views
+	case msg.String() == "shift+tab":
+		m.viewMode = (m.viewMode - 1 + mainViewCount) % mainViewCount // Reverse cycle through main views
+	}
+
+	switch {
+	case m.viewMode == previous:
+		return m, nil
+	case m.viewMode == ViewLocations && m.locationsStale():
+		return m, m.locationsCmd()
+	case m.viewMode == ViewHistory:
+		return m, m.historyCmd()
 	}
 	return m, nil
 }
 
+// updateGPSFix handles position updates from gpsd. Weather is refetched when
+// the position moved significantly, and the watcher is restarted so it keeps
+// following the position. If gpsd goes away, the location falls back to IP
+// detection and a reconnect is scheduled.
+func (m Model) updateGPSFix(msg messages.GPSFixMsg) (Model, tea.Cmd) {
+	m.gpsWatching = false
+	if m.config.LocationMode != "gps" {
+		m.gpsFix = nil
+		return m, nil
+	}
+
+	if msg.Error != nil {
+		lost := m.gpsFix != nil || m.locationSource == "gpsd"
+		m.gpsFix = nil
+		if lost {
+			m.statusMsg = "gpsd unavailable, using IP location"
+			m.statusTimer = time.Now()
+			return m, tea.Batch(m.fetchWeatherCmd(), gpsRetryCmd())
+		}
+		return m, gpsRetryCmd()
+	}
+
+	// The weather is refetched at the new position, unless it is already
+	// shown for it, such as for the first fix after the initial fetch. While
+	// a fetch is running, the result is compared with the fix when it
+	// arrives instead.
+	m.gpsFix = msg.Fix
+	m.gpsWatching = true
+
+	cmds := []tea.Cmd{messages.WatchGPSCmd(m.config, m.gpsFix)}
+	if !m.refreshing && !m.showsFix(m.gpsFix) {
+		m.statusMsg = "Location updated from GPS"
+		m.statusTimer = time.Now()
+		cmds = append(cmds, m.startRefreshing(), m.fetchWeatherCmd())
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// showsFix reports whether the shown weather was fetched for a position
+// within gps_min_distance_km of the fix.
+func (m Model) showsFix(fix *weather.GPSFix) bool {
+	if m.weatherFix == nil {
+		return false
+	}
+	return weather.DistanceKm(m.weatherFix.Lat, m.weatherFix.Lon, fix.Lat, fix.Lon) <= m.config.GPSMinDistanceKm
+}
+
+// fetchWeatherCmd fetches the weather for the configured location. In GPS
+// mode the latest position from the watcher is used, so only a fetch without
+// one reads a position from gpsd itself.
+func (m Model) fetchWeatherCmd() tea.Cmd {
+	return messages.FetchWeatherWithConfigCmd(m.config, m.gpsFix)
+}
+
 // updateSettingsView handles keybindings for the settings menu.
 func (m Model) updateSettingsView(msg tea.KeyMsg) (Model, tea.Cmd) {
 	switch msg.String() {
@@ -277,32 +584,58 @@ func (m Model) updateSettingsView(msg tea.KeyMsg) (Model, tea.Cmd) {
 		return m, nil
 	case "enter":
 		switch m.settingsCursor {
-		case 0: // Toggle Location Mode
-			if m.config.LocationMode == "ip" {
+		case 0: // Cycle Profile
+			if len(m.config.Profiles) == 0 {
+				m.statusMsg = "No profiles, add [profiles.NAME] to wms.toml"
+				m.statusTimer = time.Now()
+				return m, nil
+			}
+			m.markEdited("profile")
+			return m.switchProfile(m.nextProfile())
+		case 1: // Cycle Theme
+			m.config.Theme = m.nextTheme()
+			m.markEdited("theme")
+			styles.ApplyConfig(m.config)
+			m.statusMsg = "Theme: " + themeLabel(m.config.Theme)
+			m.statusTimer = time.Now()
+			return m, nil
+		case 2: // Cycle Location Mode
+			switch m.config.LocationMode {
+			case "ip":
 				m.config.LocationMode = "manual"
 				m.statusMsg = "Location: Manual"
-			} else {
+			case "manual":
+				m.config.LocationMode = "gps"
+				m.statusMsg = "Location: GPS (gpsd)"
+			default:
 				m.config.LocationMode = "ip"
 				m.statusMsg = "Location: IP Detection"
 			}
-			return m, messages.FetchWeatherWithConfigCmd(m.config)
-		case 1: // Set Manual Location
+			m.markEdited("location_mode")
+			cmds := []tea.Cmd{m.fetchWeatherCmd()}
+			if m.config.LocationMode == "gps" && !m.gpsWatching {
+				m.gpsWatching = true
+				cmds = append(cmds, messages.WatchGPSCmd(m.config, nil))
+			}
+			return m, tea.Batch(cmds...)
+		case 3: // Set Manual Location
 			// Only allow setting location in manual mode
 			if m.config.LocationMode == "manual" {
 				m.viewMode = ViewLocationInput
 				m.isEditingLocation = true
 				m.statusMsg = "Enter new location"
 			}
-		case 2: // Set API Key
+		case 4: // Set API Key
 			m.viewMode = ViewAPIKeyInput
 			m.isEditingAPIKey = true
 			m.statusMsg = "Enter WeatherAPI key"
-		case 3: // Save and Exit
-			err := config.WriteConfig(m.config)
+		case 5: // Save and Exit
+			err := m.saveSettings()
 			if err != nil {
 				m.statusMsg = "Error saving config"
 			} else {
 				m.statusMsg = "Config saved!"
+				m.revalidateConfig()
 			}
 			m.viewMode = ViewWeather
 			return m, nil
@@ -311,14 +644,41 @@ func (m Model) updateSettingsView(msg tea.KeyMsg) (Model, tea.Cmd) {
 
 	// Handle cursor navigation
 	if msg.String() == "up" {
-		m.settingsCursor = (m.settingsCursor - 1 + 4) % 4 // Cycle through 4 options
+		m.settingsCursor = (m.settingsCursor - 1 + 6) % 6 // Cycle through 6 options
 	} else if msg.String() == "down" {
-		m.settingsCursor = (m.settingsCursor + 1) % 4 // Cycle through 4 options
+		m.settingsCursor = (m.settingsCursor + 1) % 6 // Cycle through 6 options
 	}
 
 	return m, nil
 }
 
+// markEdited remembers that settings were changed in the TUI, so "Save and
+// Exit" writes them to wms.toml.
+func (m *Model) markEdited(keys ...string) {
+	if m.editedSettings == nil {
+		m.editedSettings = make(map[string]bool)
+	}
+	for _, key := range keys {
+		m.editedSettings[key] = true
+	}
+}
+
+// saveSettings writes the settings changed in the TUI to wms.toml. Settings
+// that come from environment variables or flags, and were not changed here,
+// stay out of the file.
+func (m *Model) saveSettings() error {
+	keys := make([]string, 0, len(m.editedSettings))
+	for key := range m.editedSettings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if err := config.SaveSettings(m.config, keys); err != nil {
+		return err
+	}
+	m.editedSettings = nil
+	return nil
+}
+
 // updateLocationInputView handles keybindings for the location input screen.
 func (m Model) updateLocationInputView(msg tea.KeyMsg) (Model, tea.Cmd) {
 	switch msg.String() {
@@ -332,11 +692,12 @@ func (m Model) updateLocationInputView(msg tea.KeyMsg) (Model, tea.Cmd) {
 	case "enter":
 		// Save the new location and refresh the weather
 		m.config.Location = m.locationInput
+		m.markEdited("location")
 		m.isEditingLocation = false
 		m.viewMode = ViewSettings // Return to settings after saving
 		m.statusMsg = "Location saved!"
 		m.statusTimer = time.Now()
-		return m, messages.FetchWeatherWithConfigCmd(m.config)
+		return m, m.fetchWeatherCmd()
 	case "backspace", "ctrl+h":
 		if len(m.locationInput) > 0 {
 			m.locationInput = m.locationInput[:len(m.locationInput)-1]
@@ -388,11 +749,12 @@ func (m Model) updateAPIKeyInputView(msg tea.KeyMsg) (Model, tea.Cmd) {
 
 		// Update the config with the cleaned key
 		m.config.WeatherAPIKey = cleanedKey
+		m.revalidateConfig()
 		m.statusMsg = "API key saved! Testing connection..."
 		m.statusTimer = time.Now()
 
 		// Fetch weather to test the new API key
-		return m, messages.FetchWeatherWithConfigCmd(m.config)
+		return m, m.fetchWeatherCmd()
 	case "backspace", "ctrl+h":
 		if len(m.apiKeyInput) > 0 {
 			m.apiKeyInput = m.apiKeyInput[:len(m.apiKeyInput)-1]
@@ -434,31 +796,69 @@ func (m Model) View() string {
 	if m.width == 0 || m.height == 0 {
 		return "Loading..."
 	}
+	if m.compact() {
+		return m.renderCompact()
+	}
 
 	header := m.createTabHeader()
+	if banner := m.renderErrorBanner(); banner != "" {
+		header = lipgloss.JoinVertical(lipgloss.Left, header, banner)
+	}
+	if banner := m.renderAlertBanner(); banner != "" {
+		header = lipgloss.JoinVertical(lipgloss.Left, header, banner)
+	}
 	footer := m.createTabFooter()
 	contentHeight := m.height - lipgloss.Height(header) - lipgloss.Height(footer)
 
+	if m.dashboardActive() {
+		dashboard := lipgloss.Place(m.width, contentHeight, lipgloss.Center, lipgloss.Center, m.renderDashboard(contentHeight))
+		return lipgloss.JoinVertical(lipgloss.Left, header, dashboard, footer)
+	}
+
 	var finalContent string
 
+	// Calculate available space - use most of the screen
+	borderPadding := 8 // Account for border + padding
+	availableWidth := m.width - borderPadding
+	availableHeight := contentHeight - borderPadding
+
+	// Don't make the card too small
+	minWidth := 60
+	minHeight := 20
+
+	if availableWidth < minWidth {
+		availableWidth = minWidth
+	}
+	if availableHeight < minHeight {
+		availableHeight = minHeight
+	}
+
 	var activeContent string
 	var activeColor lipgloss.Color
 
-	// Pre-generate all panel content
-	weatherContent := m.createWeatherPanelContent()
+	// Pre-generate all panel content; the card has 4 columns and 2 rows of
+	// padding on each side
+	weatherContent := m.createWeatherTabContent(availableWidth-8, availableHeight-4)
 	moonContent := m.createMoonPanelContent()
 	solarContent := m.createSolarPanelContent()
 
 	switch m.viewMode {
 	case ViewWeather:
 		activeContent = weatherContent
-		activeColor = styles.WeatherColor
+		activeColor = m.weatherAccent()
 	case ViewMoon:
 		activeContent = moonContent
 		activeColor = styles.MoonColor
 	case ViewSolar:
 		activeContent = solarContent
 		activeColor = styles.SunColor
+	case ViewLocations:
+		// The grid lives inside the main card, so subtract its border and padding.
+		activeContent = m.createLocationsPanelContent(m.width - 18)
+		activeColor = styles.WeatherColor
+	case ViewHistory:
+		activeContent = m.createHistoryPanelContent(availableWidth-8, availableHeight-4)
+		activeColor = styles.TimeColor
 	case ViewSettings:
 		activeContent = m.renderSettings()
 		activeColor = styles.Primary
@@ -470,20 +870,10 @@ func (m Model) View() string {
 		activeColor = styles.Primary
 	}
 
-	// Calculate available space - use most of the screen
-	borderPadding := 8 // Account for border + padding
-	availableWidth := m.width - borderPadding
-	availableHeight := contentHeight - borderPadding
-
-	// Don't make the card too small
-	minWidth := 60
-	minHeight := 20
-
-	if availableWidth < minWidth {
-		availableWidth = minWidth
-	}
-	if availableHeight < minHeight {
-		availableHeight = minHeight
+	// Fade out data that can no longer be trusted
+	if m.activeSourceStale() {
+		activeContent = dim(activeContent)
+		activeColor = styles.TextMuted
 	}
 
 	// Define a responsive card style that fills most of the screen
@@ -521,21 +911,39 @@ func (m Model) createTabHeader() string {
 	timeLocationDisplay := styles.ClockStyle.Render(fmt.Sprintf("%s • 📍 %s",
 		m.formatTime(m.time),
 		getLocationDisplay(m)))
+	if activity := m.renderActivity(); activity != "" {
+		timeLocationDisplay += "  " + activity
+	}
 
 	// --- Center Block: Tabs ---
 	weatherTab := "[1] Weather"
 	moonTab := "[2] Moon"
 	solarTab := "[3] Solar"
+	locationsTab := "[4] Locations"
+	historyTab := "[5] History"
 
 	switch m.viewMode {
 	case ViewWeather:
-		weatherTab = styles.H2Style.Copy().Foreground(styles.WeatherColor).Render("● WEATHER")
+		weatherTab = styles.H2Style.Copy().Foreground(m.weatherAccent()).Render("● WEATHER")
 	case ViewMoon:
 		moonTab = styles.H2Style.Copy().Foreground(styles.MoonColor).Render("● MOON")
 	case ViewSolar:
 		solarTab = styles.H2Style.Copy().Foreground(styles.SunColor).Render("● SOLAR")
+	case ViewLocations:
+		locationsTab = styles.H2Style.Copy().Foreground(styles.WeatherColor).Render("● LOCATIONS")
+	case ViewHistory:
+		historyTab = styles.H2Style.Copy().Foreground(styles.TimeColor).Render("● HISTORY")
+	}
+	tabsLine := fmt.Sprintf("%s    %s    %s    %s    %s", weatherTab, moonTab, solarTab, locationsTab, historyTab)
+
+	// The dashboard takes the place of the first three tabs
+	if m.layout() == config.LayoutDashboard {
+		dashboardTab := "[D] Dashboard"
+		if m.dashboardActive() {
+			dashboardTab = styles.H2Style.Copy().Foreground(m.weatherAccent()).Render("● DASHBOARD")
+		}
+		tabsLine = fmt.Sprintf("%s    %s    %s", dashboardTab, locationsTab, historyTab)
 	}
-	tabsLine := fmt.Sprintf("%s    %s    %s", weatherTab, moonTab, solarTab)
 
 	// --- Layout with a flexible spring ---
 	headerWidth := m.width
@@ -554,16 +962,80 @@ func (m Model) createTabHeader() string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, timeLocationDisplay, spring, tabsLine)
 }
 
-// createTabFooter creates the footer component, which displays the keybindings.
+// createTabFooter creates the footer component, which displays the status
+// area above the keybindings.
 func (m Model) createTabFooter() string {
 	// A cleaner footer with a unified units toggle and settings key
-	controls := fmt.Sprintf("[R] Refresh    [U] Units (%s, %s)    [S] Settings    [Tab] Switch Tabs    [Q] Quit",
+	controls := fmt.Sprintf("%s   [U] Units (%s, %s)   [D] Layout   [S] Settings   [Tab] Switch Tabs   [Q] Quit",
+		m.refreshLabel(),
 		m.config.Units,
 		m.config.TimeFormat+"h")
 
-	return styles.CaptionStyle.Copy().
+	footer := styles.CaptionStyle.Copy().
 		Align(lipgloss.Center).
 		Render(controls)
+
+	if status := m.renderStatusLine(); status != "" {
+		return lipgloss.JoinVertical(lipgloss.Center, status, footer)
+	}
+	return footer
+}
+
+// refreshLabel returns the refresh key binding together with a countdown to
+// the next automatic refresh.
+func (m Model) refreshLabel() string {
+	countdown := m.refreshCountdown()
+	if countdown == "" {
+		return "[R] Refreshing..."
+	}
+	return "[R] Refresh (" + countdown + ")"
+}
+
+// refreshCountdown formats the time until the next automatic refresh as m:ss.
+// It is empty while a refresh is in progress.
+func (m Model) refreshCountdown() string {
+	if m.nextRefresh.IsZero() {
+		return ""
+	}
+
+	remaining := time.Until(m.nextRefresh).Round(time.Second)
+	if remaining < 0 {
+		remaining = 0
+	}
+	minutes := int(remaining.Minutes())
+	seconds := int(remaining.Seconds()) % 60
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+}
+
+// renderStatusLine renders the status area: the latest status message while
+// it is fresh, otherwise the most important configuration issue. It is empty when
+// there is nothing to report.
+func (m Model) renderStatusLine() string {
+	if m.statusMsg != "" {
+		return styles.CaptionStyle.Render(m.statusMsg)
+	}
+	if len(m.configIssues) == 0 {
+		return ""
+	}
+
+	// Errors are more urgent than warnings, so show the first one if any
+	issue := m.configIssues[0]
+	for _, candidate := range m.configIssues {
+		if candidate.Severity == config.SeverityError {
+			issue = candidate
+			break
+		}
+	}
+	text := "Config " + issue.Severity.String() + ": " + issue.String()
+	if len(m.configIssues) > 1 {
+		text += fmt.Sprintf(" (+%d more, run \"wms config validate\")", len(m.configIssues)-1)
+	}
+
+	style := styles.WarningStyle
+	if issue.Severity == config.SeverityError {
+		style = styles.ErrorStyle
+	}
+	return style.Copy().MaxWidth(m.width).Render("⚠ " + text)
 }
 
 func getLocationDisplay(m Model) string {
@@ -576,6 +1048,15 @@ func getLocationDisplay(m Model) string {
 	return "IP Lookup"
 }
 
+// weatherAccent returns the color of the current conditions, or the theme's
+// weather color until there is weather to show.
+func (m Model) weatherAccent() lipgloss.Color {
+	if m.stormyWeather == nil {
+		return styles.WeatherColor
+	}
+	return weather.AccentColor(m.stormyWeather)
+}
+
 // createWeatherPanelContent generates the content for the weather tab.
 func (m Model) createWeatherPanelContent() string {
 	if m.stormyWeather != nil {
@@ -587,6 +1068,18 @@ func (m Model) createWeatherPanelContent() string {
 	return lipgloss.JoinVertical(lipgloss.Center, "⏳ Loading weather...")
 }
 
+// createWeatherTabContent generates the content for the weather tab: the
+// current weather, followed by the hourly forecast charts when they fit in
+// width×height cells.
+func (m Model) createWeatherTabContent(width, height int) string {
+	content := m.createWeatherPanelContent()
+	charts := m.renderForecastCharts(width)
+	if charts == "" || lipgloss.Height(content)+1+lipgloss.Height(charts) > height {
+		return content
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, content, "", charts)
+}
+
 // createMoonPanelContent generates the content for the moon tab.
 func (m Model) createMoonPanelContent() string {
 	if m.moon.Error != nil {
@@ -597,26 +1090,30 @@ func (m Model) createMoonPanelContent() string {
 	}
 
 	moonIcon := getMoonPhaseIcon(m.moon.Phase)
+
+	// Create text lines to match weather format exactly, starting with an
+	// empty line to match icon spacing
+	textLines := append([]string{""}, m.moonDetailLines()...)
+
+	return m.formatTwoColumnContent(moonIcon, textLines)
+}
+
+// moonDetailLines formats the labelled moon details, one per line.
+func (m Model) moonDetailLines() []string {
 	labelStyle := lipgloss.NewStyle().Foreground(styles.MoonColor)
-	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F3F4F6"))
+	valueStyle := lipgloss.NewStyle().Foreground(styles.TextPrimary)
 
-	// Create text lines to match weather format exactly, with enough space for all lines
-	textLines := []string{
-		"", // Empty line to match icon spacing
+	lines := []string{
 		labelStyle.Render("Phase") + "    " + valueStyle.Render(m.moon.Phase),
 		labelStyle.Render("Illuminated") + "  " + valueStyle.Render(fmt.Sprintf("%.0f%%", m.moon.Illumination)),
 		labelStyle.Render("Next") + "     " + valueStyle.Render(m.moon.NextPhase),
-		"", // Placeholder for moon name
-		"",
-		"",
 	}
 
 	// Add moon name if available
 	if m.moon.MoonName != "" {
-		textLines[4] = labelStyle.Render("Name") + "     " + valueStyle.Render(m.moon.MoonName)
+		lines = append(lines, labelStyle.Render("Name")+"     "+valueStyle.Render(m.moon.MoonName))
 	}
-
-	return m.formatTwoColumnContent(moonIcon, textLines)
+	return lines
 }
 
 // createSolarPanelContent generates the content for the solar tab.
@@ -636,6 +1133,15 @@ func (m Model) createSolarPanelContent() string {
 		}
 	}
 
+	// Create text lines to match weather format exactly, starting with an
+	// empty line to match icon spacing
+	textLines := append([]string{""}, m.solarDetailLines()...)
+
+	return m.formatTwoColumnContent(solarIcon, textLines)
+}
+
+// solarDetailLines formats the labelled sun details, one per line.
+func (m Model) solarDetailLines() []string {
 	sunriseStr := m.formatTime(m.sun.Sunrise)
 	sunsetStr := m.formatTime(m.sun.Sunset)
 	hours := int(m.sun.DayLength.Hours())
@@ -644,20 +1150,14 @@ func (m Model) createSolarPanelContent() string {
 
 	// Create styles
 	labelStyle := lipgloss.NewStyle().Foreground(styles.SunColor)
-	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F3F4F6"))
+	valueStyle := lipgloss.NewStyle().Foreground(styles.TextPrimary)
 
-	// Create text lines to match weather format exactly
-	textLines := []string{
-		"", // Empty line to match icon spacing
+	return []string{
 		labelStyle.Render("Status") + "   " + valueStyle.Render(strings.Title(m.sun.CurrentPos)),
 		labelStyle.Render("Sunrise") + "  " + valueStyle.Render(sunriseStr),
 		labelStyle.Render("Sunset") + "   " + valueStyle.Render(sunsetStr),
 		labelStyle.Render("Daylight") + " " + valueStyle.Render(daylightStr),
-		"",
-		"",
 	}
-
-	return m.formatTwoColumnContent(solarIcon, textLines)
 }
 
 func (m Model) formatTwoColumnContent(iconLines, textLines []string) string {
@@ -682,21 +1182,41 @@ func (m Model) renderSettings() string {
 	b.WriteString(styles.H2Style.Render("Settings"))
 	b.WriteString("\n\n")
 
-	// --- Location Mode Setting ---
+	// --- Profile Setting ---
 	cursor := " "
+	profileStyle := lipgloss.NewStyle()
+	if len(m.config.Profiles) == 0 {
+		profileStyle = profileStyle.Foreground(styles.TextMuted)
+	}
 	if m.settingsCursor == 0 {
 		cursor = ">"
 	}
-	modeStatus := fmt.Sprintf("Location Mode: %s", strings.Title(m.config.LocationMode))
+	profileStatus := fmt.Sprintf("Profile:       %s", profileLabel(m.config.Profile))
+	b.WriteString(fmt.Sprintf("%s %s\n", cursor, profileStyle.Render(profileStatus)))
+
+	// --- Theme Setting ---
+	cursor = " "
+	if m.settingsCursor == 1 {
+		cursor = ">"
+	}
+	themeStatus := fmt.Sprintf("Theme:         %s", themeLabel(m.config.Theme))
+	b.WriteString(fmt.Sprintf("%s %s\n", cursor, themeStatus))
+
+	// --- Location Mode Setting ---
+	cursor = " "
+	if m.settingsCursor == 2 {
+		cursor = ">"
+	}
+	modeStatus := fmt.Sprintf("Location Mode: %s", locationModeLabel(m.config.LocationMode))
 	b.WriteString(fmt.Sprintf("%s %s\n", cursor, modeStatus))
 
 	// --- Manual Location Setting ---
 	cursor = " "
 	locationStyle := lipgloss.NewStyle()
-	if m.config.LocationMode == "ip" {
+	if m.config.LocationMode != "manual" {
 		locationStyle = locationStyle.Foreground(styles.TextMuted)
 	}
-	if m.settingsCursor == 1 {
+	if m.settingsCursor == 3 {
 		cursor = ">"
 	}
 	locationStatus := fmt.Sprintf("Set Location:  %s", m.config.Location)
@@ -704,7 +1224,7 @@ func (m Model) renderSettings() string {
 
 	// --- API Key Setting ---
 	cursor = " "
-	if m.settingsCursor == 2 {
+	if m.settingsCursor == 4 {
 		cursor = ">"
 	}
 	apiKeyDisplay := "Not Set"
@@ -721,7 +1241,7 @@ func (m Model) renderSettings() string {
 
 	// --- Save and Exit Setting ---
 	cursor = " "
-	if m.settingsCursor == 3 {
+	if m.settingsCursor == 5 {
 		cursor = ">"
 	}
 	saveStatus := "Save and Exit"
@@ -735,6 +1255,18 @@ func (m Model) renderSettings() string {
 	return b.String()
 }
 
+// locationModeLabel returns the display name of a location mode.
+func locationModeLabel(mode string) string {
+	switch mode {
+	case "ip":
+		return "IP"
+	case "gps":
+		return "GPS"
+	default:
+		return strings.Title(mode)
+	}
+}
+
 // renderLocationInput creates the view for the location input screen.
 func (m Model) renderLocationInput() string {
 	// Create a simple input field for location, styled as a card
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"wms/internal/ui/components"
+	"wms/internal/ui/styles"
+	"wms/internal/weather"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Sizes of the parts of a forecast chart row around the chart itself.
+const (
+	forecastLabelWidth = 6  // "Feels "
+	forecastRangeWidth = 12 // " 10…20°C", " 5…24 km/h"
+	forecastTempHeight = 3  // Rows of the temperature line
+)
+
+// renderForecastCharts renders the hourly forecast in width cells: the
+// temperature as a line, and the feels-like temperature, the chance of
+// precipitation and the wind as sparklines, each with its range, above a time
+// axis. Charts cover two days when there is a cell for every hour, otherwise
+// one. It is empty without an hourly forecast or room for it.
+func (m Model) renderForecastCharts(width int) string {
+	if m.stormyWeather == nil || len(m.stormyWeather.Hourly) < 2 {
+		return ""
+	}
+	chartWidth := width - forecastLabelWidth - forecastRangeWidth
+	if chartWidth < 12 {
+		return ""
+	}
+
+	hours := 24
+	if chartWidth >= 48 {
+		hours = 48
+	}
+	hourly := m.stormyWeather.Hourly[:min(hours, len(m.stormyWeather.Hourly))]
+	chartWidth = min(chartWidth, 2*len(hourly)) // Wider charts would only stretch
+
+	imperial := m.config.Units == "imperial"
+	tempUnit, windUnit := "°C", "km/h"
+	if imperial {
+		tempUnit, windUnit = "°F", "mph"
+	}
+	var temps, feels, rain, wind []float64
+	for _, h := range hourly {
+		if imperial {
+			temps, feels, wind = append(temps, h.TempF), append(feels, h.FeelslikeF), append(wind, h.WindMph)
+		} else {
+			temps, feels, wind = append(temps, h.TempC), append(feels, h.FeelslikeC), append(wind, h.WindKph)
+		}
+		rain = append(rain, float64(h.ChanceOfRain))
+	}
+
+	// Temperatures take the color of the temperature gradient
+	tempColor := func(v float64) lipgloss.Color {
+		if imperial {
+			v = (v - 32) * 5 / 9
+		}
+		return styles.TemperatureAccent(v)
+	}
+	// Both temperature charts share a range, so they can be compared
+	lo, hi := valueRange(append(append([]float64{}, temps...), feels...))
+	tempChart := components.Chart{Values: temps, Min: lo, Max: hi, Color: tempColor}
+	feelsChart := components.Chart{Values: feels, Min: lo, Max: hi, Color: tempColor}
+	rainChart := components.Chart{Values: rain, Min: 0, Max: 100, Color: func(float64) lipgloss.Color { return styles.RainColor }}
+	windChart := components.Chart{Values: wind, Color: func(float64) lipgloss.Color { return styles.TextSecondary }}
+
+	label := func(text string) string {
+		return styles.CaptionStyle.Render(fmt.Sprintf("%-*s", forecastLabelWidth, text))
+	}
+	span := func(values []float64, unit string) string {
+		lo, hi := valueRange(values)
+		return styles.CaptionStyle.Render(fmt.Sprintf(" %.0f…%.0f%s", lo, hi, unit))
+	}
+
+	lines := []string{styles.H3Style.Render(fmt.Sprintf("Next %d hours", len(hourly)))}
+	for i, row := range tempChart.Braille(chartWidth, forecastTempHeight) {
+		if i == 0 {
+			lines = append(lines, label("Temp")+row+span(temps, tempUnit))
+		} else {
+			lines = append(lines, label("")+row)
+		}
+	}
+	_, maxRain := valueRange(rain)
+	lines = append(lines,
+		label("Feels")+feelsChart.Sparkline(chartWidth)+span(feels, tempUnit),
+		label("Rain")+rainChart.Sparkline(chartWidth)+styles.CaptionStyle.Render(fmt.Sprintf(" max %.0f%%", maxRain)),
+		label("Wind")+windChart.Sparkline(chartWidth)+span(wind, " "+windUnit),
+		label("")+styles.CaptionStyle.Render(m.forecastAxis(hourly, chartWidth)),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// forecastAxis renders the times of the first, middle and last hour of the
+// forecast under a chart of the given width.
+func (m Model) forecastAxis(hourly []weather.HourlyForecast, width int) string {
+	axis := []rune(strings.Repeat(" ", width))
+	place := func(i int, align lipgloss.Position) {
+		t, err := time.Parse("2006-01-02 15:04", hourly[i].Time)
+		if err != nil {
+			return
+		}
+		text := []rune(m.formatClock(t))
+		x := i * (width - 1) / (len(hourly) - 1)
+		x -= int(float64(len(text)) * float64(align))
+		x = max(0, min(width-len(text), x))
+		copy(axis[x:], text)
+	}
+	place(0, lipgloss.Left)
+	if width >= 24 {
+		place(len(hourly)/2, lipgloss.Center)
+	}
+	place(len(hourly)-1, lipgloss.Right)
+	return string(axis)
+}
+
+// valueRange returns the smallest and the largest of the values.
+func valueRange(values []float64) (lo, hi float64) {
+	for i, v := range values {
+		if i == 0 || v < lo {
+			lo = v
+		}
+		if i == 0 || v > hi {
+			hi = v
+		}
+	}
+	return lo, hi
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"wms/internal/ui/styles"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// sunSource names where the sun times come from; they are calculated on
+// every tick, so they never go stale.
+const sunSource = "local calculation"
+
+// sourceStatus records when a data source was last updated successfully and
+// which provider served it.
+type sourceStatus struct {
+	updated  time.Time // Zero until the first successful update
+	provider string
+	failed   bool // The latest update failed, so any data shown is older
+}
+
+// succeed records a successful update from the given provider.
+func (s *sourceStatus) succeed(provider string) {
+	s.updated = time.Now()
+	s.provider = provider
+	s.failed = false
+}
+
+// fail records a failed update. The time and provider of the last successful
+// update are kept.
+func (s *sourceStatus) fail() {
+	s.failed = true
+}
+
+// stale reports whether the data should no longer be trusted: it is older
+// than twice the refresh interval, or the latest update failed.
+func (s sourceStatus) stale(interval time.Duration) bool {
+	if s.updated.IsZero() {
+		return false // Nothing shown yet, the panel says so itself
+	}
+	return s.failed || time.Since(s.updated) > 2*interval
+}
+
+// activeSource returns the status of the data shown in the current tab, or
+// nil for views that do not show data.
+func (m Model) activeSource() *sourceStatus {
+	switch m.viewMode {
+	case ViewWeather:
+		return &m.weatherStatus
+	case ViewLocations:
+		return &m.locationsStatus
+	case ViewMoon:
+		return &m.moonStatus
+	case ViewSolar:
+		return &m.sunStatus
+	}
+	return nil
+}
+
+// activeSourceStale reports whether the data in the current tab is stale.
+func (m Model) activeSourceStale() bool {
+	source := m.activeSource()
+	return source != nil && source.stale(m.refreshInterval())
+}
+
+// refreshInterval returns the configured refresh interval as a duration.
+func (m Model) refreshInterval() time.Duration {
+	return time.Duration(m.config.RefreshInterval) * time.Minute
+}
+
+// renderUpdated renders when the data in the current tab was last updated
+// and by which provider, e.g. "updated 3m ago · WeatherAPI". Stale data is
+// highlighted. It is empty before the first successful update.
+func (m Model) renderUpdated() string {
+	source := m.activeSource()
+	if source == nil || source.updated.IsZero() {
+		return ""
+	}
+
+	text := "updated " + formatAge(time.Since(source.updated))
+	if source.provider != "" {
+		text += " · " + source.provider
+	}
+	if source.failed {
+		text += " · last refresh failed"
+	}
+
+	if source.stale(m.refreshInterval()) {
+		return styles.WarningStyle.Render(text)
+	}
+	return styles.CaptionStyle.Render(text)
+}
+
+// renderActivity renders the header status of the current tab: a spinner
+// while refreshing, followed by when its data was last updated.
+func (m Model) renderActivity() string {
+	updated := m.renderUpdated()
+	if !m.refreshing && !m.locationsLoading() {
+		return updated
+	}
+
+	spinner := lipgloss.NewStyle().Foreground(styles.Info).Render(spinnerFrames[m.spinnerFrame])
+	if updated == "" {
+		return spinner + " " + styles.CaptionStyle.Render("loading")
+	}
+	return spinner + " " + updated
+}
+
+// renderErrorBanner renders the error of the last weather refresh above the
+// panel. Any older weather stays visible below it. It is empty when the last
+// refresh succeeded or the current view does not show weather.
+func (m Model) renderErrorBanner() string {
+	if m.weatherError == nil || (m.viewMode != ViewWeather && m.viewMode != ViewLocations) {
+		return ""
+	}
+
+	text := "⚠ Refresh failed: " + m.weatherError.Error()
+	if m.stormyWeather != nil && !m.weatherStatus.updated.IsZero() {
+		text += " (showing data from " + formatAge(time.Since(m.weatherStatus.updated)) + ")"
+	}
+	return lipgloss.PlaceHorizontal(m.width, lipgloss.Center, styles.ErrorStyle.Copy().MaxWidth(m.width).Render(text))
+}
+
+// formatAge formats a duration as a short, human-readable age such as
+// "just now", "3m ago" or "2h ago".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// dim renders already styled content faint. Every style inside the content
+// ends with a full reset, so faint is switched on again after each of them.
+func dim(content string) string {
+	if lipgloss.ColorProfile() == termenv.Ascii {
+		return content // The terminal does not support styling
+	}
+	const faint, reset = "\x1b[2m", "\x1b[0m"
+	return faint + strings.ReplaceAll(content, reset, reset+faint) + reset
+}
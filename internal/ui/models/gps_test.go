@@ -0,0 +1,106 @@
+package models
+
+import (
+	"testing"
+
+	"wms/internal/config"
+	"wms/internal/ui/messages"
+	"wms/internal/weather"
+)
+
+// gpsModel returns a model in GPS mode that is not fetching anything.
+func gpsModel() Model {
+	cfg := config.DefaultConfig()
+	cfg.LocationMode = "gps"
+	m := InitialModelWithConfig(cfg)
+	m.refreshing = false
+	return m
+}
+
+// fetched hands the model weather fetched for the given fix, nil for an IP
+// location.
+func fetched(m Model, fix *weather.GPSFix) Model {
+	msg := messages.WeatherMsg{Weather: &weather.Weather{}, Provider: config.ProviderWeatherAPI, Fix: fix}
+	if fix != nil {
+		msg.LocationSource = "gpsd"
+	}
+	updated, _ := m.Update(msg)
+	return updated.(Model)
+}
+
+// located hands the model a fix from the GPS watcher.
+func located(m Model, fix *weather.GPSFix) Model {
+	m, _ = m.updateGPSFix(messages.GPSFixMsg{Fix: fix})
+	return m
+}
+
+func TestGPSFixRefetch(t *testing.T) {
+	berlin := &weather.GPSFix{Lat: 52.52, Lon: 13.405}
+	nearBerlin := &weather.GPSFix{Lat: 52.521, Lon: 13.406} // About 130 m away
+	potsdam := &weather.GPSFix{Lat: 52.39, Lon: 13.065}
+
+	tests := []struct {
+		name      string
+		steps     func(Model) Model
+		wantFetch bool
+		wantFix   *weather.GPSFix // Where the model fetches next, if it does
+	}{
+		{
+			name:  "first fix matches the initial fetch",
+			steps: func(m Model) Model { return located(fetched(m, berlin), nearBerlin) },
+		},
+		{
+			name: "first fix arrives while the initial fetch runs",
+			steps: func(m Model) Model {
+				m.refreshing = true
+				m = located(m, nearBerlin)
+				if !m.refreshing || m.statusMsg != "" {
+					t.Errorf("a second fetch was started while the first one ran")
+				}
+				return fetched(m, berlin)
+			},
+		},
+		{
+			name:      "first fix after the fetch fell back to IP",
+			steps:     func(m Model) Model { return located(fetched(m, nil), berlin) },
+			wantFetch: true,
+			wantFix:   berlin,
+		},
+		{
+			name: "fix changed while the fetch ran",
+			steps: func(m Model) Model {
+				m.refreshing = true
+				m = located(m, potsdam)
+				return fetched(m, berlin)
+			},
+			wantFetch: true,
+			wantFix:   potsdam,
+		},
+		{
+			name:      "moved",
+			steps:     func(m Model) Model { return located(located(fetched(m, berlin), berlin), potsdam) },
+			wantFetch: true,
+			wantFix:   potsdam,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.steps(gpsModel())
+			if m.refreshing != tt.wantFetch {
+				t.Errorf("refreshing = %v, want %v", m.refreshing, tt.wantFetch)
+			}
+			if tt.wantFetch && (m.gpsFix != tt.wantFix || m.statusMsg != "Location updated from GPS") {
+				t.Errorf("fetching at %+v with status %q, want %+v", m.gpsFix, m.statusMsg, tt.wantFix)
+			}
+		})
+	}
+}
+
+func TestGPSFixOutsideGPSMode(t *testing.T) {
+	m := gpsModel()
+	m.config.LocationMode = "ip"
+	m = located(m, &weather.GPSFix{Lat: 52.52, Lon: 13.405})
+	if m.gpsFix != nil || m.refreshing {
+		t.Errorf("a fix in IP mode was kept (%+v) or fetched (%v)", m.gpsFix, m.refreshing)
+	}
+}
@@ -0,0 +1,416 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"wms/internal/history"
+	"wms/internal/ui/components"
+	"wms/internal/ui/messages"
+	"wms/internal/ui/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// historyDays is how many days the History tab covers, including today.
+const historyDays = 14
+
+// Sizes of the parts of the History tab around its table rows.
+const (
+	historyChartRows  = 6  // High, low, rain and pressure, the day marker and the date axis
+	historyFixedRows  = 7  // Title, table header, day details, key hint and the blank lines between
+	historyLabelWidth = 6  // "Press "
+	historyRangeWidth = 16 // " 1003…1021 hPa"
+	historyMaxCellDay = 4  // Widest a day gets in the charts
+)
+
+// historyView holds the state of the History tab: the recorded observations
+// and the daily summaries of the place being shown.
+type historyView struct {
+	observations []history.Observation
+	err          error
+	loaded       bool
+	places       []string      // Recorded places, the most recent first
+	place        string        // The place shown, see history.Observation.Place
+	days         []history.Day // Daily summaries of the place, oldest first
+	cursor       int           // The selected day, an index into days
+
+	picking  bool        // The date picker is open
+	pickDate time.Time   // The day selected in the date picker
+	archive  *archiveDay // A past day looked up in the archive, shown instead of the recorded days
+}
+
+// historyCmd reads the recorded observations for the History tab.
+func (m *Model) historyCmd() tea.Cmd {
+	return messages.LoadHistoryCmd(historyDays - 1)
+}
+
+// updateHistory stores freshly read observations. The place and day shown
+// stay selected when they are still recorded; otherwise the current location
+// and the latest day are shown.
+func (m *Model) updateHistory(msg messages.HistoryMsg) {
+	h := &m.history
+	h.loaded = true
+	h.err = msg.Error
+	if msg.Error != nil {
+		return
+	}
+	h.observations = msg.Observations
+	h.places = history.Places(msg.Observations)
+
+	place := h.place
+	if !containsString(h.places, place) {
+		place = m.currentPlace()
+	}
+	if !containsString(h.places, place) && len(h.places) > 0 {
+		place = h.places[0]
+	}
+	m.selectHistoryPlace(place)
+}
+
+// selectHistoryPlace shows the daily summaries of a place. The selected date
+// is kept if the place has a summary for it, otherwise the latest day is
+// selected.
+func (m *Model) selectHistoryPlace(place string) {
+	h := &m.history
+	var selected history.Day
+	if h.cursor < len(h.days) {
+		selected = h.days[h.cursor]
+	}
+
+	var observations []history.Observation
+	for _, o := range h.observations {
+		if o.Place() == place {
+			observations = append(observations, o)
+		}
+	}
+	h.place = place
+	h.days = history.Daily(observations)
+
+	h.cursor = len(h.days) - 1
+	for i, day := range h.days {
+		if day.Date.Equal(selected.Date) {
+			h.cursor = i
+		}
+	}
+	h.cursor = max(h.cursor, 0)
+}
+
+// currentPlace returns the place of the current weather the way observations
+// name it, or "" before the weather arrived.
+func (m Model) currentPlace() string {
+	if m.stormyWeather == nil {
+		return ""
+	}
+	loc := m.stormyWeather.Location
+	return history.Observation{Location: loc.Name, Region: loc.Region, Country: loc.Country}.Place()
+}
+
+// updateHistoryView handles the keys of the History tab: left and right move
+// between days, up and down between the recorded places, and A opens the date
+// picker to look up a past day in the archive.
+func (m Model) updateHistoryView(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	if msg.String() == "a" {
+		m.openDatePicker()
+		return m, nil, true
+	}
+	if m.history.archive != nil {
+		return m.updateArchiveView(msg)
+	}
+
+	h := &m.history
+	switch msg.String() {
+	case "left", "h":
+		h.cursor = max(h.cursor-1, 0)
+	case "right", "l":
+		h.cursor = max(min(h.cursor+1, len(h.days)-1), 0)
+	case "up", "k", "down", "j":
+		if len(h.places) < 2 {
+			return m, nil, true
+		}
+		step := 1
+		if msg.String() == "up" || msg.String() == "k" {
+			step = len(h.places) - 1
+		}
+		i := indexOfString(h.places, h.place)
+		m.selectHistoryPlace(h.places[(i+step)%len(h.places)])
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// createHistoryPanelContent renders the History tab in width×height cells:
+// charts of the daily highs and lows, precipitation and pressure, a table of
+// the days and the details of the selected day. The charts are left out when
+// there is no room for them.
+func (m Model) createHistoryPanelContent(width, height int) string {
+	h := m.history
+	switch {
+	case h.picking:
+		return m.renderDatePicker()
+	case h.archive != nil:
+		return m.createArchivePanelContent(width)
+	case !h.loaded:
+		return styles.LoadingStyle.Render("⏳ Loading history...")
+	case h.err != nil:
+		return lipgloss.JoinVertical(lipgloss.Center,
+			styles.ErrorStyle.Render("⚠️ History unavailable"),
+			styles.CaptionStyle.Render(h.err.Error()),
+		)
+	case len(h.days) == 0:
+		hint := "Observations appear here after the next refresh"
+		if !m.config.History.Enabled {
+			hint = "Set enabled = true under [history] in wms.toml to record the weather"
+		}
+		return lipgloss.JoinVertical(lipgloss.Center,
+			"📈 No recorded weather yet",
+			"",
+			styles.CaptionStyle.Render(hint),
+			styles.CaptionStyle.Render("Press A to look up a past day in the archive"),
+		)
+	}
+
+	title := fmt.Sprintf("Last %d days · %s", historyDays, h.place)
+	if len(h.places) > 1 {
+		title += fmt.Sprintf(" (%d of %d)", indexOfString(h.places, h.place)+1, len(h.places))
+	}
+	lines := []string{styles.H3Style.Render(title), ""}
+
+	rows := height - historyFixedRows
+	if charts := m.renderHistoryCharts(width); charts != "" && rows-historyChartRows-1 >= min(len(h.days), 3) {
+		lines = append(lines, charts, "")
+		rows -= historyChartRows + 1
+	}
+	lines = append(lines, m.renderHistoryTable(max(rows, 1))...)
+	lines = append(lines, "", m.historyDayDetails(h.days[h.cursor]))
+
+	keys := "←/→ Day"
+	if len(h.places) > 1 {
+		keys += "   ↑/↓ Place"
+	}
+	keys += "   A Archive"
+	lines = append(lines, styles.CaptionStyle.Render(keys))
+	return lipgloss.JoinVertical(lipgloss.Center, lines...)
+}
+
+// renderHistoryCharts renders the daily highs, lows, precipitation and mean
+// pressure as sparklines with an equal number of cells per day, followed by
+// a marker under the selected day and the first and last date. It is empty
+// when there is not a cell for every day.
+func (m Model) renderHistoryCharts(width int) string {
+	days := m.history.days
+	perDay := min((width-historyLabelWidth-historyRangeWidth)/len(days), historyMaxCellDay)
+	if perDay < 1 {
+		return ""
+	}
+	chartWidth := perDay * len(days)
+
+	imperial := m.config.Units == "imperial"
+	tempUnit, rainUnit, rainFloor := "°C", " mm", 1.0
+	if imperial {
+		tempUnit, rainUnit, rainFloor = "°F", " in", 0.04
+	}
+	var highs, lows, rain, pressure []float64
+	for _, day := range days {
+		for i := 0; i < perDay; i++ {
+			if imperial {
+				highs, lows, rain = append(highs, day.HighF), append(lows, day.LowF), append(rain, day.PrecipIn())
+			} else {
+				highs, lows, rain = append(highs, day.HighC), append(lows, day.LowC), append(rain, day.PrecipMm)
+			}
+			pressure = append(pressure, m.historyPressure(day.PressureMb))
+		}
+	}
+
+	tempColor := func(v float64) lipgloss.Color {
+		if imperial {
+			v = (v - 32) * 5 / 9
+		}
+		return styles.TemperatureAccent(v)
+	}
+	// Highs and lows share a range, so they can be compared
+	lo, hi := valueRange(append(append([]float64{}, highs...), lows...))
+	_, maxRain := valueRange(rain)
+	highChart := components.Chart{Values: highs, Min: lo, Max: hi, Color: tempColor}
+	lowChart := components.Chart{Values: lows, Min: lo, Max: hi, Color: tempColor}
+	rainChart := components.Chart{Values: rain, Min: 0, Max: math.Max(maxRain, rainFloor), Color: func(float64) lipgloss.Color { return styles.RainColor }}
+	pressureChart := components.Chart{Values: pressure, Color: func(float64) lipgloss.Color { return styles.TextSecondary }}
+
+	label := func(text string) string {
+		return styles.CaptionStyle.Render(fmt.Sprintf("%-*s", historyLabelWidth, text))
+	}
+	span := func(values []float64, format string) string {
+		lo, hi := valueRange(values)
+		return styles.CaptionStyle.Render(fmt.Sprintf(" "+format+"…"+format, lo, hi))
+	}
+	pressureFormat, pressureUnit := "%.0f", " hPa"
+	if imperial {
+		pressureFormat, pressureUnit = "%.2f", " inHg"
+	}
+
+	// The marker and the dates line up with the cells of the days
+	marker := []rune(strings.Repeat(" ", chartWidth))
+	marker[m.history.cursor*perDay+(perDay-1)/2] = '▲'
+	axis := []rune(strings.Repeat(" ", chartWidth))
+	first, last := days[0].Date.Format("Jan 2"), days[len(days)-1].Date.Format("Jan 2")
+	copy(axis, []rune(first))
+	if len(first)+1+len(last) <= chartWidth {
+		copy(axis[chartWidth-len(last):], []rune(last))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		label("High")+highChart.Sparkline(chartWidth)+span(highs, "%.0f")+styles.CaptionStyle.Render(tempUnit),
+		label("Low")+lowChart.Sparkline(chartWidth)+span(lows, "%.0f")+styles.CaptionStyle.Render(tempUnit),
+		label("Rain")+rainChart.Sparkline(chartWidth)+styles.CaptionStyle.Render(fmt.Sprintf(" max %.1f%s", maxRain, rainUnit)),
+		label("Press")+pressureChart.Sparkline(chartWidth)+span(pressure, pressureFormat)+styles.CaptionStyle.Render(pressureUnit),
+		label("")+lipgloss.NewStyle().Foreground(styles.Primary).Render(string(marker)),
+		label("")+styles.CaptionStyle.Render(string(axis)),
+	)
+}
+
+// renderHistoryTable renders a table of the days in at most rows lines after
+// its header. When not every day fits, the rows scroll to keep the selected
+// day visible.
+func (m Model) renderHistoryTable(rows int) []string {
+	days := m.history.days
+	start := 0
+	if len(days) > rows {
+		start = max(0, min(m.history.cursor-rows/2, len(days)-rows))
+	}
+	end := min(start+rows, len(days))
+
+	row := func(cells ...string) string {
+		return fmt.Sprintf("%-10s %8s %8s %9s %11s %5s", cells[0], cells[1], cells[2], cells[3], cells[4], cells[5])
+	}
+	lines := []string{styles.CaptionStyle.Render(row("Day", "High", "Low", "Rain", "Pressure", "Obs"))}
+
+	selected := lipgloss.NewStyle().Foreground(styles.TextInverse).Background(styles.Primary)
+	for i := start; i < end; i++ {
+		day := days[i]
+		trend := "→"
+		if i > 0 {
+			switch change := day.PressureMb - days[i-1].PressureMb; {
+			case change >= 1:
+				trend = "↑"
+			case change <= -1:
+				trend = "↓"
+			}
+		}
+		line := row(
+			day.Date.Format("Mon Jan 2"),
+			m.historyTemperature(day.HighC, day.HighF),
+			m.historyTemperature(day.LowC, day.LowF),
+			m.historyPrecip(day),
+			m.historyPressureText(day.PressureMb)+" "+trend,
+			fmt.Sprint(day.Observations),
+		)
+		if i == m.history.cursor {
+			line = selected.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// historyDayDetails describes the selected day: when the low and the high
+// were observed and how the pressure changed over the day.
+func (m Model) historyDayDetails(day history.Day) string {
+	details := fmt.Sprintf("%s: low %s at %s, high %s at %s",
+		day.Date.Format("Monday"),
+		m.historyTemperature(day.LowC, day.LowF), m.formatClock(day.LowAt),
+		m.historyTemperature(day.HighC, day.HighF), m.formatClock(day.HighAt))
+
+	change := m.historyPressure(day.PressureChange)
+	switch {
+	case day.PressureChange >= 1:
+		details += fmt.Sprintf(", pressure rising by %s", m.historyPressureAmount(change))
+	case day.PressureChange <= -1:
+		details += fmt.Sprintf(", pressure falling by %s", m.historyPressureAmount(-change))
+	default:
+		details += ", pressure steady"
+	}
+	return styles.BodyStyle.Render(details)
+}
+
+// historyTemperature formats a temperature in the configured units.
+func (m Model) historyTemperature(tempC, tempF float64) string {
+	if m.config.Units == "imperial" {
+		return fmt.Sprintf("%.1f°F", tempF)
+	}
+	return fmt.Sprintf("%.1f°C", tempC)
+}
+
+// historyPrecip formats the estimated precipitation of a day in the
+// configured units.
+func (m Model) historyPrecip(day history.Day) string {
+	if m.config.Units == "imperial" {
+		return fmt.Sprintf("%.2f in", day.PrecipIn())
+	}
+	return fmt.Sprintf("%.1f mm", day.PrecipMm)
+}
+
+// historyPressure converts a pressure in hectopascals to the configured
+// units: inches of mercury for imperial units.
+func (m Model) historyPressure(mb float64) float64 {
+	if m.config.Units == "imperial" {
+		return mb * 0.02953
+	}
+	return mb
+}
+
+// historyPressureText formats a pressure in hectopascals in the configured
+// units.
+func (m Model) historyPressureText(mb float64) string {
+	return m.historyPressureAmount(m.historyPressure(mb))
+}
+
+// historyPressureAmount formats a pressure already converted with
+// historyPressure.
+func (m Model) historyPressureAmount(v float64) string {
+	if m.config.Units == "imperial" {
+		return fmt.Sprintf("%.2f inHg", v)
+	}
+	return fmt.Sprintf("%.0f hPa", v)
+}
+
+// compactHistoryLines renders one line per recorded day of the place shown,
+// the latest first.
+func (m Model) compactHistoryLines() []string {
+	h := m.history
+	if h.picking || h.archive != nil {
+		return m.compactArchiveLines()
+	}
+	if len(h.days) == 0 {
+		return []string{"📈 No recorded weather"}
+	}
+
+	lines := []string{h.place}
+	for i := len(h.days) - 1; i >= 0; i-- {
+		day := h.days[i]
+		lines = append(lines, fmt.Sprintf("%s ↑%s ↓%s %s",
+			day.Date.Format("Mon 2"),
+			m.historyTemperature(day.HighC, day.HighF),
+			m.historyTemperature(day.LowC, day.LowF),
+			m.historyPrecip(day)))
+	}
+	return lines
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	return indexOfString(list, s) >= 0
+}
+
+// indexOfString returns the index of s in list, or -1.
+func indexOfString(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"strings"
+
+	"wms/internal/config"
+	"wms/internal/ui/styles"
+	"wms/internal/weather"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// panel is one of the data panels together with the ways it can be drawn,
+// from the largest to the smallest, so a layout can pick the largest one that
+// fits the room it has.
+type panel struct {
+	title string
+	color lipgloss.Color
+	style lipgloss.Style // Base card style, e.g. styles.WeatherCardStyle
+	forms []string       // Renderings from largest to smallest
+	stale bool           // Dim the panel, see sourceStatus.stale
+}
+
+// weatherPanel returns the weather panel: with ASCII art, without it, or as
+// a two-line summary.
+func (m Model) weatherPanel() panel {
+	p := panel{
+		title: "Weather",
+		color: m.weatherAccent(),
+		style: styles.WeatherCardStyle,
+		forms: []string{m.createWeatherPanelContent()},
+		stale: m.weatherStatus.stale(m.refreshInterval()),
+	}
+	if m.stormyWeather != nil {
+		p.forms = append(p.forms,
+			weather.RenderWeatherDetails(m.stormyWeather, m.config),
+			strings.Join(m.compactWeatherLines(), "\n"))
+	}
+	return p
+}
+
+// moonPanel returns the moon panel: with ASCII art, without it, or as a
+// single line.
+func (m Model) moonPanel() panel {
+	p := panel{
+		title: "Moon",
+		color: styles.MoonColor,
+		style: styles.MoonCardStyle,
+		forms: []string{m.createMoonPanelContent()},
+		stale: m.moonStatus.stale(m.refreshInterval()),
+	}
+	if !m.moon.IsLoading && m.moon.Error == nil {
+		p.forms = append(p.forms,
+			lipgloss.JoinVertical(lipgloss.Left, m.moonDetailLines()...),
+			m.compactMoonLine())
+	}
+	return p
+}
+
+// solarPanel returns the solar panel: with ASCII art, without it, or as a
+// single line.
+func (m Model) solarPanel() panel {
+	return panel{
+		title: "Solar",
+		color: styles.SunColor,
+		style: styles.SunCardStyle,
+		forms: []string{
+			m.createSolarPanelContent(),
+			lipgloss.JoinVertical(lipgloss.Left, m.solarDetailLines()...),
+			m.compactSunLine(),
+		},
+	}
+}
+
+// fit returns the largest form of the panel that fits in width×height cells.
+// When none fits, the smallest one is cut to size and false is returned.
+func (p panel) fit(width, height int) (string, bool) {
+	for _, form := range p.forms {
+		if lipgloss.Width(form) <= width && lipgloss.Height(form) <= height {
+			return form, true
+		}
+	}
+
+	lines := strings.Split(p.forms[len(p.forms)-1], "\n")
+	if len(lines) > height {
+		lines = lines[:max(height, 0)]
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(strings.Join(lines, "\n")), false
+}
+
+// render draws the panel as a bordered card with its title on top. width and
+// height are the size inside the border.
+func (p panel) render(width, height int) string {
+	title := styles.H2Style.Copy().Foreground(p.color).Render(strings.ToUpper(p.title))
+
+	// The card has one column of padding on each side; the title is followed
+	// by an empty line unless the content needs the room
+	innerWidth := width - 2
+	body := []string{title, ""}
+	content, ok := p.fit(innerWidth, height-len(body))
+	if !ok {
+		body = body[:1]
+		content, _ = p.fit(innerWidth, height-len(body))
+	}
+
+	color := p.color
+	if p.stale {
+		content = dim(content)
+		color = styles.TextMuted
+	}
+
+	return p.style.Copy().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(0, 1).
+		Width(width).
+		Height(height).
+		Align(lipgloss.Center, lipgloss.Center).
+		Render(lipgloss.JoinVertical(lipgloss.Center, append(body, content)...))
+}
+
+// dashboardActive reports whether the dashboard layout replaces the weather,
+// moon and solar tabs.
+func (m Model) dashboardActive() bool {
+	if m.layout() != config.LayoutDashboard {
+		return false
+	}
+	return m.viewMode == ViewWeather || m.viewMode == ViewMoon || m.viewMode == ViewSolar
+}
+
+// renderDashboard renders the weather, moon and solar panels together: side
+// by side on wide terminals and stacked on narrow ones, as decided by
+// styles.GetResponsiveLayout. height is the room between header and footer.
+func (m Model) renderDashboard(height int) string {
+	panels := []panel{m.weatherPanel(), m.moonPanel(), m.solarPanel()}
+
+	columns, rows := styles.GetResponsiveLayout(m.width, m.height)
+	cardWidth := styles.GetAdaptiveWidth(m.width, columns)
+	cardHeight := min(styles.GetAdaptiveHeight(m.height, rows), height/rows-2) // Minus the border
+
+	var lines []string
+	for start := 0; start < len(panels); start += columns {
+		end := min(start+columns, len(panels))
+
+		var cards []string
+		for _, p := range panels[start:end] {
+			cards = append(cards, p.render(cardWidth, cardHeight))
+		}
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, cards...))
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, lines...)
+}
+
+// layout returns the layout in use: the configured one, unless keys 1-3
+// switched from the dashboard to single panels for now.
+func (m Model) layout() string {
+	if m.tabsForced {
+		return config.LayoutTabs
+	}
+	return m.config.Layout
+}
+
+// toggleLayout switches between the tabbed and the dashboard layout. The
+// choice is kept in the configuration, so "Save and Exit" remembers it.
+func (m *Model) toggleLayout() {
+	current := m.layout()
+	m.tabsForced = false
+	if current == config.LayoutDashboard {
+		m.config.Layout = config.LayoutTabs
+		m.statusMsg = "Layout: Tabs"
+	} else {
+		m.config.Layout = config.LayoutDashboard
+		if m.viewMode == ViewMoon || m.viewMode == ViewSolar {
+			m.viewMode = ViewWeather
+		}
+		m.statusMsg = "Layout: Dashboard"
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+
+	"wms/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// press sends a key to the model and returns the updated model.
+func press(m Model, key string) Model {
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	return updated.(Model)
+}
+
+func TestTabKeysLeaveLayoutSetting(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Layout = config.LayoutDashboard
+	m := InitialModelWithConfig(cfg)
+
+	m = press(m, "2")
+	if m.config.Layout != config.LayoutDashboard {
+		t.Errorf("config layout = %q after key 2, want it unchanged", m.config.Layout)
+	}
+	if m.viewMode != ViewMoon || m.dashboardActive() {
+		t.Errorf("key 2 shows view %v with the dashboard active = %v, want the moon tab", m.viewMode, m.dashboardActive())
+	}
+
+	m = press(m, "d")
+	if !m.dashboardActive() || m.config.Layout != config.LayoutDashboard {
+		t.Errorf("d after key 2 did not return to the dashboard: active = %v, layout %q", m.dashboardActive(), m.config.Layout)
+	}
+
+	m = press(m, "d")
+	if m.dashboardActive() || m.config.Layout != config.LayoutTabs {
+		t.Errorf("d on the dashboard did not switch to tabs: active = %v, layout %q", m.dashboardActive(), m.config.Layout)
+	}
+}
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"strings"
+
+	"wms/internal/ui/messages"
+	"wms/internal/ui/styles"
+	"wms/internal/weather"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// locationCard holds the state of a single card in the multi-location view.
+type locationCard struct {
+	location string
+	weather  *weather.Weather
+	err      error
+	loading  bool
+}
+
+// locationsCmd starts fetching the weather for every saved location. The
+// cards are set to their loading state first so each one can report its own
+// progress; cards that already show weather keep it until the new data
+// arrives. It returns nil when no locations are configured.
+func (m *Model) locationsCmd() tea.Cmd {
+	if len(m.config.Locations) == 0 {
+		m.locationCards = nil
+		return nil
+	}
+
+	previous := make(map[string]*weather.Weather)
+	for _, card := range m.locationCards {
+		if card.weather != nil {
+			previous[card.location] = card.weather
+		}
+	}
+
+	m.locationCards = make([]locationCard, len(m.config.Locations))
+	for i, location := range m.config.Locations {
+		m.locationCards[i] = locationCard{location: location, weather: previous[location], loading: true}
+	}
+
+	return tea.Batch(m.startSpinner(), messages.FetchLocationsWeatherCmd(m.config, m.config.Locations))
+}
+
+// locationsStale reports whether the cards no longer match the saved
+// locations and have to be fetched again.
+func (m Model) locationsStale() bool {
+	if len(m.locationCards) != len(m.config.Locations) {
+		return true
+	}
+	for i, card := range m.locationCards {
+		if card.location != m.config.Locations[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// locationsLoading reports whether any location card is still being fetched.
+func (m Model) locationsLoading() bool {
+	for _, card := range m.locationCards {
+		if card.loading {
+			return true
+		}
+	}
+	return false
+}
+
+// updateLocationCard stores the result of a single location fetch. A failed
+// fetch keeps the weather the card already shows. Results for locations that
+// are no longer shown are ignored. Once every card has its result, the
+// freshness of the cards is updated: they count as updated when all of them
+// were fetched, and as failed when any of them was not.
+func (m *Model) updateLocationCard(msg messages.LocationWeatherMsg) {
+	if msg.Index < 0 || msg.Index >= len(m.locationCards) {
+		return
+	}
+	card := &m.locationCards[msg.Index]
+	if card.location != msg.Location {
+		return
+	}
+
+	card.loading = false
+	card.err = msg.Error
+	if msg.Error == nil {
+		card.weather = msg.Weather
+	}
+
+	if m.locationsLoading() {
+		return
+	}
+	for _, card := range m.locationCards {
+		if card.err != nil {
+			m.locationsStatus.fail()
+			return
+		}
+	}
+	m.locationsStatus.succeed(m.config.WeatherProvider)
+}
+
+// createLocationsPanelContent renders the saved locations as a grid of compact
+// weather cards that fits within the given width.
+func (m Model) createLocationsPanelContent(width int) string {
+	if len(m.locationCards) == 0 {
+		return lipgloss.JoinVertical(lipgloss.Center,
+			"📍 No saved locations",
+			"",
+			styles.CaptionStyle.Render(`Add locations = ["London", "Tokyo"] to wms.toml`),
+		)
+	}
+
+	// Render every card body first so the grid can be sized to its contents.
+	bodies := make([]string, len(m.locationCards))
+	contentWidth, contentHeight := 0, 0
+	for i, card := range m.locationCards {
+		bodies[i] = m.renderLocationCardBody(card)
+		contentWidth = max(contentWidth, lipgloss.Width(bodies[i]))
+		contentHeight = max(contentHeight, lipgloss.Height(bodies[i]))
+	}
+
+	// Start from the responsive layout and drop columns until the cards fit.
+	columns, _ := styles.GetResponsiveLayout(width, m.height)
+	cardWidth := styles.GetAdaptiveWidth(width, columns)
+	for columns > 1 && cardWidth < contentWidth+4 {
+		columns--
+		cardWidth = styles.GetAdaptiveWidth(width, columns)
+	}
+
+	var rows []string
+	for start := 0; start < len(m.locationCards); start += columns {
+		end := min(start+columns, len(m.locationCards))
+
+		var cells []string
+		for i := start; i < end; i++ {
+			cells = append(cells, renderLocationCard(m.locationCards[i], bodies[i], cardWidth, contentHeight+2))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cells...))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Center, rows...)
+}
+
+// renderLocationCardBody renders the content of a single card depending on
+// whether its data is loading, failed or available. Weather from an earlier
+// fetch is shown while refreshing and after a failed refresh.
+func (m Model) renderLocationCardBody(card locationCard) string {
+	switch {
+	case card.weather != nil && card.err != nil:
+		return lipgloss.JoinVertical(lipgloss.Center,
+			weather.RenderWeatherCompact(card.weather, m.config),
+			styles.ErrorStyle.Render("⚠ Refresh failed"),
+		)
+	case card.weather != nil:
+		return weather.RenderWeatherCompact(card.weather, m.config)
+	case card.loading:
+		return styles.LoadingStyle.Render("⏳ Loading weather...")
+	case card.err != nil:
+		return lipgloss.JoinVertical(lipgloss.Center,
+			styles.ErrorStyle.Render("⚠️ Weather data unavailable"),
+			styles.CaptionStyle.Render(card.err.Error()),
+		)
+	default:
+		return ""
+	}
+}
+
+// renderLocationCard wraps a card body in a bordered box with the location as
+// its title. All cards share the same size so the grid lines up.
+func renderLocationCard(card locationCard, body string, width, height int) string {
+	title := card.location
+	if card.weather != nil && card.weather.Location.Name != "" {
+		title = card.weather.Location.Name
+	}
+
+	// Every card takes the color of its own conditions
+	accent := styles.WeatherColor
+	if card.weather != nil {
+		accent = weather.AccentColor(card.weather)
+	}
+	borderColor := accent
+	if card.err != nil {
+		borderColor = styles.Error
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Padding(0, 1).
+		Width(width).
+		Height(height).
+		AlignHorizontal(lipgloss.Center).
+		Render(lipgloss.JoinVertical(lipgloss.Center,
+			styles.H2Style.Copy().Foreground(accent).Render(strings.ToUpper(title)),
+			"",
+			body,
+		))
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"wms/internal/config"
+	"wms/internal/ui/messages"
+	"wms/internal/weather"
+)
+
+// locationsModel returns a model with the given saved locations.
+func locationsModel(locations ...string) Model {
+	cfg := config.DefaultConfig()
+	cfg.Locations = locations
+	return InitialModelWithConfig(cfg)
+}
+
+func TestLocationsStale(t *testing.T) {
+	tests := []struct {
+		name  string
+		cards []string
+		saved []string
+		want  bool
+	}{
+		{"never fetched", nil, []string{"Tokyo"}, true},
+		{"same locations", []string{"Tokyo", "Berlin"}, []string{"Tokyo", "Berlin"}, false},
+		{"location added", []string{"Tokyo"}, []string{"Tokyo", "Berlin"}, true},
+		{"locations reordered", []string{"Berlin", "Tokyo"}, []string{"Tokyo", "Berlin"}, true},
+		{"no locations", nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := locationsModel(tt.saved...)
+			for _, location := range tt.cards {
+				m.locationCards = append(m.locationCards, locationCard{location: location})
+			}
+			if got := m.locationsStale(); got != tt.want {
+				t.Errorf("locationsStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocationsCmdKeepsWeather(t *testing.T) {
+	m := locationsModel("Tokyo", "Berlin")
+	tokyo := &weather.Weather{}
+	m.locationCards = []locationCard{{location: "Tokyo", weather: tokyo}}
+
+	if m.locationsCmd() == nil {
+		t.Fatal("locationsCmd() = nil, want the fetch")
+	}
+	if len(m.locationCards) != 2 || !m.locationsLoading() {
+		t.Fatalf("cards = %+v, want two loading cards", m.locationCards)
+	}
+	if m.locationCards[0].weather != tokyo || m.locationCards[1].weather != nil {
+		t.Errorf("cards = %+v, want Tokyo to keep its weather while loading", m.locationCards)
+	}
+
+	if m := locationsModel(); m.locationsCmd() != nil {
+		t.Error("locationsCmd() without locations started a fetch")
+	}
+}
+
+func TestUpdateLocationCard(t *testing.T) {
+	shown := &weather.Weather{}
+	fetched := &weather.Weather{}
+	tests := []struct {
+		name        string
+		msg         messages.LocationWeatherMsg
+		wantWeather *weather.Weather
+		wantErr     bool
+		wantLoading bool
+	}{
+		{"success", messages.LocationWeatherMsg{Index: 0, Location: "Tokyo", Weather: fetched}, fetched, false, false},
+		{"failure keeps the weather", messages.LocationWeatherMsg{Index: 0, Location: "Tokyo", Error: errors.New("offline")}, shown, true, false},
+		{"location moved", messages.LocationWeatherMsg{Index: 0, Location: "Berlin", Weather: fetched}, shown, false, true},
+		{"index out of range", messages.LocationWeatherMsg{Index: 3, Location: "Tokyo", Weather: fetched}, shown, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := locationsModel("Tokyo")
+			m.locationCards = []locationCard{{location: "Tokyo", weather: shown, loading: true}}
+
+			m.updateLocationCard(tt.msg)
+			card := m.locationCards[0]
+			if card.weather != tt.wantWeather || (card.err != nil) != tt.wantErr || card.loading != tt.wantLoading {
+				t.Errorf("card = %+v, want weather %p, error %v, loading %v", card, tt.wantWeather, tt.wantErr, tt.wantLoading)
+			}
+		})
+	}
+}
+
+func TestLocationsFreshness(t *testing.T) {
+	m := locationsModel("Tokyo", "Berlin")
+	m.viewMode = ViewLocations
+	m.weatherStatus.succeed(config.ProviderWeatherAPI) // The main weather is current
+	m.locationsCmd()
+
+	if got := m.renderUpdated(); got != "" {
+		t.Errorf("renderUpdated() = %q before any card was fetched, want nothing rather than the main weather's age", got)
+	}
+
+	m.updateLocationCard(messages.LocationWeatherMsg{Index: 0, Location: "Tokyo", Weather: &weather.Weather{}})
+	if !m.locationsStatus.updated.IsZero() {
+		t.Error("the cards count as updated while Berlin is still loading")
+	}
+	m.updateLocationCard(messages.LocationWeatherMsg{Index: 1, Location: "Berlin", Error: errors.New("offline")})
+	if !m.locationsStatus.failed || !m.locationsStatus.updated.IsZero() {
+		t.Errorf("status = %+v after Berlin failed, want failed and never updated", m.locationsStatus)
+	}
+
+	m.locationsCmd()
+	m.updateLocationCard(messages.LocationWeatherMsg{Index: 0, Location: "Tokyo", Weather: &weather.Weather{}})
+	m.updateLocationCard(messages.LocationWeatherMsg{Index: 1, Location: "Berlin", Weather: &weather.Weather{}})
+	if m.locationsStatus.failed || m.locationsStatus.updated.IsZero() || m.locationsStatus.provider != m.config.WeatherProvider {
+		t.Errorf("status = %+v after every card was fetched, want updated by %s", m.locationsStatus, m.config.WeatherProvider)
+	}
+	if m.activeSourceStale() || m.renderUpdated() == "" {
+		t.Errorf("freshly fetched cards count as stale (%v) or show no update time", m.activeSourceStale())
+	}
+}
@@ -0,0 +1,195 @@
+package models
+
+import (
+	"reflect"
+	"time"
+
+	"wms/internal/config"
+	"wms/internal/ui/messages"
+	"wms/internal/ui/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// WithConfigFlags returns a copy of the model that re-applies the given
+// command-line flags whenever the config file is reloaded, so flags keep
+// taking precedence over edits to the file.
+func (m Model) WithConfigFlags(flags *config.Flags) Model {
+	m.configFlags = flags
+	return m
+}
+
+// watchConfigCmd starts watching the config file and .env for changes.
+func (m Model) watchConfigCmd() tea.Cmd {
+	return messages.WatchConfigCmd(m.configFlags, config.CurrentStamp(), m.flagUpdates)
+}
+
+// updateConfig applies a configuration that was reloaded because wms.toml or
+// .env changed on disk. Display settings take effect on the next render;
+// weather is only refetched when a setting that affects fetching changed.
+func (m Model) updateConfig(msg messages.ConfigChangedMsg) (Model, tea.Cmd) {
+	old := m.config
+	cfg, issues := msg.Config, msg.Issues
+	if msg.Flags != nil && m.configFlags != nil && *msg.Flags != *m.configFlags {
+		// The profile was switched while the watcher was reloading
+		cfg, issues = config.Load(m.configFlags)
+	}
+	cmds := append(m.applyConfig(cfg, issues), messages.WatchConfigCmd(m.configFlags, msg.Stamp, m.flagUpdates))
+
+	// Saving settings from the TUI also changes the file; only announce
+	// reloads that changed something
+	switch {
+	case config.HasErrors(m.configIssues):
+		m.statusMsg = "Config reloaded with errors"
+		m.statusTimer = time.Now()
+	case !reflect.DeepEqual(old, m.config):
+		m.statusMsg = "Config reloaded"
+		m.statusTimer = time.Now()
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// switchProfile applies another profile, or none for an empty name, and
+// reloads the configuration with it. The choice lasts until the next switch
+// and is written to wms.toml by "Save and Exit".
+func (m Model) switchProfile(name string) (Model, tea.Cmd) {
+	m.configFlags = m.configFlags.WithProfile(name)
+	m.sendFlagUpdate()
+	cfg, issues := config.Load(m.configFlags)
+	cmds := m.applyConfig(cfg, issues)
+
+	m.statusMsg = "Profile: " + profileLabel(m.config.Profile)
+	if config.HasErrors(issues) {
+		m.statusMsg += " (with config errors)"
+	}
+	m.statusTimer = time.Now()
+	return m, tea.Batch(cmds...)
+}
+
+// applyConfig replaces the configuration and returns the commands needed to
+// catch up with it. Settings changed in the TUI but not saved yet keep their
+// values, inputs being edited are left alone, and weather is only refetched
+// when a setting that affects fetching changed.
+func (m *Model) applyConfig(cfg config.Config, issues []config.Issue) []tea.Cmd {
+	old := m.config
+	m.config = m.keepEdits(cfg)
+	m.configIssues = issues
+	if !m.isEditingLocation {
+		m.locationInput = m.config.Location
+	}
+	if !m.isEditingAPIKey {
+		m.apiKeyInput = m.config.WeatherAPIKey
+	}
+
+	if old.Theme != m.config.Theme || old.UseColors != m.config.UseColors || !reflect.DeepEqual(old.Themes, m.config.Themes) {
+		styles.ApplyConfig(m.config)
+	}
+
+	var cmds []tea.Cmd
+	if old.RefreshInterval != m.config.RefreshInterval && !m.nextRefresh.IsZero() {
+		cmds = append(cmds, m.scheduleRefresh())
+	}
+	if fetchSettingsChanged(old, m.config) {
+		cmds = append(cmds, m.startRefreshing(), m.fetchWeatherCmd())
+	}
+	if !reflect.DeepEqual(old.Locations, m.config.Locations) {
+		m.locationCards = nil
+		if m.viewMode == ViewLocations {
+			cmds = append(cmds, m.locationsCmd())
+		}
+	}
+	if !reflect.DeepEqual(old.Alerts, m.config.Alerts) && m.stormyWeather != nil {
+		// Edited rules are checked right away rather than on the next refresh
+		cmds = append(cmds, m.checkAlerts(m.stormyWeather)...)
+	}
+	if m.config.LocationMode == "gps" && !m.gpsWatching {
+		m.gpsWatching = true
+		cmds = append(cmds, messages.WatchGPSCmd(m.config, nil))
+	}
+	return cmds
+}
+
+// keepEdits returns cfg with the settings changed in the TUI copied over from
+// the current configuration, so a reload does not revert them before "Save
+// and Exit" writes them.
+func (m Model) keepEdits(cfg config.Config) config.Config {
+	for key := range m.editedSettings {
+		if key == "profile" {
+			continue // Already applied through the flags cfg was loaded with
+		}
+		value, err := config.GetField(m.config, key)
+		if err != nil {
+			continue
+		}
+		config.SetField(&cfg, key, value)
+	}
+	return cfg
+}
+
+// nextProfile returns the profile after the active one, cycling through no
+// profile and then every configured profile in alphabetical order.
+func (m Model) nextProfile() string {
+	names := append([]string{""}, m.config.ProfileNames()...)
+	for i, name := range names {
+		if name == m.config.Profile {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return ""
+}
+
+// nextTheme returns the theme after the active one, cycling through auto, the
+// built-in themes and the custom themes.
+func (m Model) nextTheme() string {
+	names := m.config.ThemeNames()
+	for i, name := range names {
+		if name == m.config.Theme {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return config.ThemeAuto
+}
+
+// themeLabel returns the display name of a theme. For "auto" it includes the
+// theme picked from the terminal background.
+func themeLabel(name string) string {
+	if name == config.ThemeAuto {
+		return "auto (" + styles.CurrentTheme() + ")"
+	}
+	return name
+}
+
+// profileLabel returns the display name of a profile.
+func profileLabel(name string) string {
+	if name == "" {
+		return "None"
+	}
+	return name
+}
+
+// fetchSettingsChanged reports whether any setting that affects which weather
+// is fetched, or how, differs between two configurations.
+func fetchSettingsChanged(a, b config.Config) bool {
+	return a.WeatherProvider != b.WeatherProvider ||
+		a.Location != b.Location ||
+		a.LocationMode != b.LocationMode ||
+		!reflect.DeepEqual(a.IPGeoProviders, b.IPGeoProviders) ||
+		a.StaticLocation != b.StaticLocation ||
+		a.GPSDAddress != b.GPSDAddress ||
+		a.WeatherAPIKey != b.WeatherAPIKey ||
+		a.IPInfoToken != b.IPInfoToken
+}
+
+// sendFlagUpdate hands the current flags to the running config watcher, so
+// its next reload uses them too. Only the latest flags matter, so an update
+// the watcher has not picked up yet is replaced.
+func (m Model) sendFlagUpdate() {
+	select {
+	case <-m.flagUpdates:
+	default:
+	}
+	select {
+	case m.flagUpdates <- m.configFlags:
+	default:
+	}
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wms/internal/config"
+	"wms/internal/ui/messages"
+)
+
+// reload hands the model a configuration reloaded from disk.
+func reload(m Model, cfg config.Config) Model {
+	m, _ = m.updateConfig(messages.ConfigChangedMsg{Config: cfg})
+	return m
+}
+
+func TestReloadKeepsUnsavedEdits(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WMS_CONFIG", "")
+	m := InitialModelWithConfig(config.DefaultConfig())
+
+	for _, key := range []string{"u", "u", "t", "d"} {
+		m = press(m, key) // Imperial units, 12-hour time, dashboard layout
+	}
+	if m.config.Units != "imperial" || m.config.TimeFormat != "12" || m.config.Layout != config.LayoutDashboard {
+		t.Fatalf("edits gave units %q, time format %q and layout %q", m.config.Units, m.config.TimeFormat, m.config.Layout)
+	}
+
+	// The file changed elsewhere, still with the old units and layout
+	fromFile := config.DefaultConfig()
+	fromFile.RefreshInterval = 30
+	fromFile.Locations = []string{"Portland, OR", "Tokyo"}
+	m = reload(m, fromFile)
+
+	if m.config.Units != "imperial" || m.config.TimeFormat != "12" || m.config.Layout != config.LayoutDashboard {
+		t.Errorf("after the reload units = %q, time format = %q, layout = %q, want the unsaved edits", m.config.Units, m.config.TimeFormat, m.config.Layout)
+	}
+	if m.config.RefreshInterval != 30 || strings.Join(m.config.Locations, "|") != "Portland, OR|Tokyo" {
+		t.Errorf("after the reload refresh interval = %d and locations = %q, want the values from the file", m.config.RefreshInterval, m.config.Locations)
+	}
+
+	if err := m.saveSettings(); err != nil {
+		t.Fatalf("saveSettings() error = %v", err)
+	}
+	data, err := os.ReadFile(config.GetConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`units = "imperial"`, `time_format = "12"`, `layout = "dashboard"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("wms.toml =\n%s\nwant it to contain %s", data, want)
+		}
+	}
+
+	// Once saved, reloads apply the file again
+	m = reload(m, fromFile)
+	if m.config.Units != "metric" {
+		t.Errorf("units = %q after saving and reloading, want the file's metric", m.config.Units)
+	}
+}
+
+func TestReloadWithoutEdits(t *testing.T) {
+	cfg := config.DefaultConfig()
+	m := InitialModelWithConfig(cfg)
+
+	unchanged := reload(m, cfg)
+	if unchanged.statusMsg != "" {
+		t.Errorf("status = %q after reloading the same settings, want none", unchanged.statusMsg)
+	}
+
+	cfg.Units = "imperial"
+	cfg.Theme = "solarized"
+	changed := reload(m, cfg)
+	if changed.config.Units != "imperial" || changed.config.Theme != "solarized" {
+		t.Errorf("reloaded units %q and theme %q, want imperial and solarized", changed.config.Units, changed.config.Theme)
+	}
+	if changed.statusMsg != "Config reloaded" {
+		t.Errorf("status = %q, want Config reloaded", changed.statusMsg)
+	}
+}
+
+func TestFetchSettingsChanged(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*config.Config)
+		want   bool
+	}{
+		{"nothing", func(*config.Config) {}, false},
+		{"display setting", func(c *config.Config) { c.Theme = "light"; c.Layout = config.LayoutDashboard }, false},
+		{"location", func(c *config.Config) { c.Location = "Tokyo" }, true},
+		{"provider", func(c *config.Config) { c.WeatherProvider = config.ProviderOpenMeteo }, true},
+		{"IP backends", func(c *config.Config) { c.IPGeoProviders = []string{config.IPGeoIPAPI} }, true},
+		{"static location", func(c *config.Config) { c.StaticLocation.Latitude = 1.5 }, true},
+		{"API key", func(c *config.Config) { c.WeatherAPIKey = "new" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := config.DefaultConfig()
+			after := config.DefaultConfig()
+			tt.change(&after)
+			if got := fetchSettingsChanged(before, after); got != tt.want {
+				t.Errorf("fetchSettingsChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReloadAfterProfileSwitch(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WMS_CONFIG", "")
+	settings := "version = 2\ntheme = \"dark\"\n\n[profiles.work]\nunits = \"imperial\"\n"
+	if err := os.MkdirAll(filepath.Dir(config.GetConfigPath()), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config.GetConfigPath(), []byte(settings), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ := config.Load(nil)
+	m := InitialModelWithConfig(cfg)
+
+	m.viewMode = ViewSettings
+	m = press(m, "enter") // Switch to the work profile
+	m.config.Theme = "light"
+	m.markEdited("theme")
+	if m.config.Profile != "work" || m.config.Units != "imperial" {
+		t.Fatalf("switching gave profile %q with units %q, want work with imperial", m.config.Profile, m.config.Units)
+	}
+
+	reloaded, _ := config.Load(m.configFlags)
+	m = reload(m, reloaded)
+	if m.config.Profile != "work" || m.config.Units != "imperial" || m.config.Theme != "light" {
+		t.Errorf("after the reload profile = %q, units = %q, theme = %q, want work, imperial and the edited light theme", m.config.Profile, m.config.Units, m.config.Theme)
+	}
+}
@@ -0,0 +1,112 @@
+package styles
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Sky groups the weather conditions that share an accent color.
+type Sky int
+
+const (
+	SkyFair  Sky = iota // Clear or cloudy, the temperature decides
+	SkyFog              // Mist and fog
+	SkyRain             // Drizzle, rain and showers
+	SkySnow             // Snow, sleet and ice pellets
+	SkyStorm            // Thunderstorms
+)
+
+// temperatureStop pins a color of the active theme to a temperature.
+type temperatureStop struct {
+	tempC float64
+	color func() lipgloss.Color
+}
+
+// temperatureStops run from cold over the theme's weather color and its sun
+// color to hot. Temperatures in between get a blend of their neighbors.
+var temperatureStops = []temperatureStop{
+	{0, func() lipgloss.Color { return ColdColor }},
+	{15, func() lipgloss.Color { return WeatherColor }},
+	{25, func() lipgloss.Color { return SunColor }},
+	{35, func() lipgloss.Color { return HotColor }},
+}
+
+// WeatherAccent returns the accent color for the current conditions: the
+// storm, snow, rain or fog color when there is any, the night color after
+// sunset, and otherwise a color between cold and hot by temperature.
+func WeatherAccent(sky Sky, tempC float64, isDay bool) lipgloss.Color {
+	switch sky {
+	case SkyStorm:
+		return StormColor
+	case SkySnow:
+		return SnowColor
+	case SkyRain:
+		return RainColor
+	case SkyFog:
+		return FogColor
+	}
+	if !isDay {
+		return NightColor
+	}
+	return TemperatureAccent(tempC)
+}
+
+// TemperatureAccent returns the color of the temperature gradient for a
+// temperature in degrees Celsius.
+func TemperatureAccent(tempC float64) lipgloss.Color {
+	first, last := temperatureStops[0], temperatureStops[len(temperatureStops)-1]
+	if tempC <= first.tempC {
+		return first.color()
+	}
+	for i := 1; i < len(temperatureStops); i++ {
+		lo, hi := temperatureStops[i-1], temperatureStops[i]
+		if tempC <= hi.tempC {
+			return blend(lo.color(), hi.color(), (tempC-lo.tempC)/(hi.tempC-lo.tempC))
+		}
+	}
+	return last.color()
+}
+
+// blend mixes two colors, from a at t = 0 to b at t = 1. With 16 colors or
+// less a blend would be rounded to an unrelated color, so the nearer of the
+// two is used instead; the same goes for ANSI color numbers, which cannot
+// be mixed.
+func blend(a, b lipgloss.Color, t float64) lipgloss.Color {
+	nearer := a
+	if t >= 0.5 {
+		nearer = b
+	}
+	if lipgloss.ColorProfile() >= termenv.ANSI {
+		return nearer
+	}
+
+	ar, ag, ab, ok1 := parseHex(a)
+	br, bg, bb, ok2 := parseHex(b)
+	if !ok1 || !ok2 {
+		return nearer
+	}
+	mix := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return lipgloss.Color(fmt.Sprintf("#%02X%02X%02X", mix(ar, br), mix(ag, bg), mix(ab, bb)))
+}
+
+// parseHex splits a "#RRGGBB" or "#RGB" color into its channels.
+func parseHex(c lipgloss.Color) (r, g, b uint8, ok bool) {
+	s := string(c)
+	if len(s) == 4 && s[0] == '#' {
+		s = "#" + string([]byte{s[1], s[1], s[2], s[2], s[3], s[3]})
+	}
+	if len(s) != 7 || s[0] != '#' {
+		return 0, 0, 0, false
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v), true
+}
@@ -4,16 +4,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Tailwind palette the built-in dark theme is drawn from
 var (
-	// Modern Color Palette - Dark Theme
-	Primary   = lipgloss.Color("#60A5FA") // Blue-400
-	Secondary = lipgloss.Color("#A78BFA") // Violet-400
-	Success   = lipgloss.Color("#34D399") // Emerald-400
-	Warning   = lipgloss.Color("#FBBF24") // Amber-400
-	Error     = lipgloss.Color("#F87171") // Red-400
-	Info      = lipgloss.Color("#38BDF8") // Sky-400
-
-	// Grayscale
 	White   = lipgloss.Color("#FFFFFF")
 	Gray50  = lipgloss.Color("#F9FAFB")
 	Gray100 = lipgloss.Color("#F3F4F6")
@@ -25,24 +17,85 @@ var (
 	Gray700 = lipgloss.Color("#374151")
 	Gray800 = lipgloss.Color("#1F2937")
 	Gray900 = lipgloss.Color("#111827")
+)
+
+// Colors of the active theme, set by ApplyTheme
+var (
+	Primary, Secondary, Success, Warning, Error, Info lipgloss.Color
 
 	// Component-specific Colors
-	WeatherColor = lipgloss.Color("#06B6D4") // Cyan-500
-	MoonColor    = lipgloss.Color("#8B5CF6") // Violet-500
-	SunColor     = lipgloss.Color("#F59E0B") // Amber-500
-	TimeColor    = lipgloss.Color("#10B981") // Emerald-500
+	WeatherColor, MoonColor, SunColor, TimeColor lipgloss.Color
 
 	// Typography Scale
-	TextPrimary   = Gray50
-	TextSecondary = Gray300
-	TextMuted     = Gray500
-	TextInverse   = Gray900
+	TextPrimary, TextSecondary, TextMuted, TextInverse lipgloss.Color
+
+	// Dividers, separators and progress tracks
+	BorderColor, SubtleColor lipgloss.Color
+
+	// Weather icon art
+	IconSunColor, IconMoonColor, IconCloudColor lipgloss.Color
+
+	// Accents for the current conditions, see WeatherAccent
+	HotColor, ColdColor, StormColor, SnowColor, RainColor, FogColor, NightColor lipgloss.Color
 )
 
-// Base Styles
+// Styles of the active theme, rebuilt by ApplyTheme
 var (
+	BaseStyle lipgloss.Style
+
+	// Typography
+	H1Style, H2Style, H3Style, BodyStyle, CaptionStyle lipgloss.Style
+
+	// Layout Components - Ultra minimal padding
+	ContainerStyle, CardStyle, CardHeaderStyle lipgloss.Style
+
+	// Navigation & Header - Ultra minimal padding
+	HeaderStyle, StatusBarStyle lipgloss.Style
+
+	// Data Display
+	MetricLabelStyle, MetricValueStyle, MetricLargeStyle lipgloss.Style
+
+	// Icons and Indicators
+	IconStyle, IconLargeStyle lipgloss.Style
+
+	// States
+	LoadingStyle, ErrorStyle, SuccessStyle, WarningStyle lipgloss.Style
+
+	// Interactive Elements
+	ButtonStyle, ButtonSecondaryStyle, KeybindStyle lipgloss.Style
+
+	// Dividers and Separators
+	DividerStyle, SeparatorStyle lipgloss.Style
+
+	// Progress and Charts
+	ProgressBarStyle, ProgressTrackStyle lipgloss.Style
+
+	// Specialized Component Styles - Clean borderless design
+	WeatherCardStyle, MoonCardStyle, SunCardStyle, TimeCardStyle lipgloss.Style
+
+	// Weather-specific
+	TemperatureStyle, ConditionStyle lipgloss.Style
+
+	// Moon-specific
+	MoonPhaseStyle, IlluminationStyle lipgloss.Style
+
+	// Sun-specific
+	SunTimeStyle, DayLengthStyle lipgloss.Style
+
+	// Time-specific
+	ClockStyle, DateStyle lipgloss.Style
+
+	// Utility Styles
+	CenterStyle, RightStyle lipgloss.Style
+
+	// Responsive helpers
+	CompactStyle, SpacingXS, SpacingSM, SpacingMD, SpacingLG lipgloss.Style
+)
+
+// buildStyles rebuilds every style from the colors of the active theme.
+func buildStyles() {
 	BaseStyle = lipgloss.NewStyle().
-			Foreground(TextPrimary)
+		Foreground(TextPrimary)
 
 	// Typography
 	H1Style = BaseStyle.Copy().
@@ -59,112 +112,112 @@ var (
 		Foreground(TextSecondary)
 
 	BodyStyle = BaseStyle.Copy().
-			Foreground(TextPrimary)
+		Foreground(TextPrimary)
 
 	CaptionStyle = BaseStyle.Copy().
-			Foreground(TextMuted)
+		Foreground(TextMuted)
 
 	// Layout Components - Ultra minimal padding
 	ContainerStyle = BaseStyle.Copy().
-			Padding(0, 0)
+		Padding(0, 0)
 
 	CardStyle = BaseStyle.Copy().
-			Padding(0, 0).
-			Margin(0, 0)
+		Padding(0, 0).
+		Margin(0, 0)
 
 	CardHeaderStyle = BaseStyle.Copy().
-			Bold(true).
-			Foreground(TextPrimary).
-			MarginBottom(1)
+		Bold(true).
+		Foreground(TextPrimary).
+		MarginBottom(1)
 
 	// Navigation & Header - Ultra minimal padding
 	HeaderStyle = BaseStyle.Copy().
-			Bold(true).
-			Foreground(Primary).
-			Padding(0, 0).
-			Align(lipgloss.Center)
+		Bold(true).
+		Foreground(Primary).
+		Padding(0, 0).
+		Align(lipgloss.Center)
 
 	StatusBarStyle = BaseStyle.Copy().
-			Foreground(TextMuted).
-			Padding(0, 0)
+		Foreground(TextMuted).
+		Padding(0, 0)
 
 	// Data Display
 	MetricLabelStyle = BaseStyle.Copy().
-				Foreground(TextMuted).
-				Bold(false)
+		Foreground(TextMuted).
+		Bold(false)
 
 	MetricValueStyle = BaseStyle.Copy().
-				Foreground(TextPrimary).
-				Bold(true)
+		Foreground(TextPrimary).
+		Bold(true)
 
 	MetricLargeStyle = BaseStyle.Copy().
-				Foreground(TextPrimary).
-				Bold(true).
-				MarginRight(1)
+		Foreground(TextPrimary).
+		Bold(true).
+		MarginRight(1)
 
 	// Icons and Indicators
 	IconStyle = BaseStyle.Copy().
-			Bold(true).
-			MarginRight(1)
+		Bold(true).
+		MarginRight(1)
 
 	IconLargeStyle = BaseStyle.Copy().
-			Bold(true).
-			MarginRight(1)
+		Bold(true).
+		MarginRight(1)
 
 	// States
 	LoadingStyle = BaseStyle.Copy().
-			Foreground(Info).
-			Italic(true).
-			Align(lipgloss.Center)
+		Foreground(Info).
+		Italic(true).
+		Align(lipgloss.Center)
 
 	ErrorStyle = BaseStyle.Copy().
-			Foreground(Error).
-			Bold(true).
-			Align(lipgloss.Center)
+		Foreground(Error).
+		Bold(true).
+		Align(lipgloss.Center)
 
 	SuccessStyle = BaseStyle.Copy().
-			Foreground(Success).
-			Bold(true)
+		Foreground(Success).
+		Bold(true)
 
 	WarningStyle = BaseStyle.Copy().
-			Foreground(Warning).
-			Bold(true)
+		Foreground(Warning).
+		Bold(true)
 
 	// Interactive Elements
 	ButtonStyle = BaseStyle.Copy().
-			Foreground(Primary).
-			Padding(0, 1).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(Primary).
-			Bold(true)
+		Foreground(Primary).
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Primary).
+		Bold(true)
 
 	ButtonSecondaryStyle = BaseStyle.Copy().
-				Foreground(Primary).
-				Padding(0, 1).
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(Primary).
-				Bold(true)
+		Foreground(Primary).
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Primary).
+		Bold(true)
 
 	KeybindStyle = BaseStyle.Copy().
-			Foreground(Primary).
-			Bold(true)
+		Foreground(Primary).
+		Bold(true)
 
 	// Dividers and Separators
 	DividerStyle = BaseStyle.Copy().
-			Foreground(Gray600).
-			MarginTop(1).
-			MarginBottom(1)
+		Foreground(BorderColor).
+		MarginTop(1).
+		MarginBottom(1)
 
 	SeparatorStyle = BaseStyle.Copy().
-			Foreground(Gray700)
+		Foreground(SubtleColor)
 
 	// Progress and Charts
 	ProgressBarStyle = BaseStyle.Copy().
-				Foreground(Primary).
-				Bold(true)
+		Foreground(Primary).
+		Bold(true)
 
 	ProgressTrackStyle = BaseStyle.Copy().
-				Foreground(Gray600)
+		Foreground(BorderColor)
 
 		// Specialized Component Styles - Clean borderless design
 	WeatherCardStyle = CardStyle.Copy()
@@ -177,53 +230,53 @@ var (
 
 	// Weather-specific
 	TemperatureStyle = BaseStyle.Copy().
-				Foreground(WeatherColor).
-				Bold(true)
+		Foreground(WeatherColor).
+		Bold(true)
 
 	ConditionStyle = BaseStyle.Copy().
-			Foreground(TextSecondary).
-			Italic(true)
+		Foreground(TextSecondary).
+		Italic(true)
 
 	// Moon-specific
 	MoonPhaseStyle = BaseStyle.Copy().
-			Foreground(MoonColor).
-			Bold(true)
+		Foreground(MoonColor).
+		Bold(true)
 
 	IlluminationStyle = BaseStyle.Copy().
-				Foreground(MoonColor)
+		Foreground(MoonColor)
 
 	// Sun-specific
 	SunTimeStyle = BaseStyle.Copy().
-			Foreground(SunColor).
-			Bold(true)
+		Foreground(SunColor).
+		Bold(true)
 
 	DayLengthStyle = BaseStyle.Copy().
-			Foreground(SunColor)
+		Foreground(SunColor)
 
 	// Time-specific
 	ClockStyle = BaseStyle.Copy().
-			Foreground(TimeColor).
-			Bold(true)
+		Foreground(TimeColor).
+		Bold(true)
 
 	DateStyle = BaseStyle.Copy().
-			Foreground(TextSecondary)
+		Foreground(TextSecondary)
 
 	// Utility Styles
 	CenterStyle = BaseStyle.Copy().
-			Align(lipgloss.Center)
+		Align(lipgloss.Center)
 
 	RightStyle = BaseStyle.Copy().
-			Align(lipgloss.Right)
+		Align(lipgloss.Right)
 
 	// Responsive helpers
 	CompactStyle = BaseStyle.Copy().
-			Padding(0, 1)
+		Padding(0, 1)
 
 	SpacingXS = BaseStyle.Copy().Margin(0, 1)
 	SpacingSM = BaseStyle.Copy().Margin(0, 2)
 	SpacingMD = BaseStyle.Copy().Margin(1, 2)
 	SpacingLG = BaseStyle.Copy().Margin(1, 3)
-)
+}
 
 // Layout Constants
 const (
@@ -0,0 +1,290 @@
+package styles
+
+import (
+	"wms/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme is a set of colors every style is built from.
+type Theme struct {
+	Name string
+
+	Primary, Secondary, Success, Warning, Error, Info lipgloss.Color
+
+	Text, TextSecondary, TextMuted, TextInverse lipgloss.Color
+	Border, Subtle                              lipgloss.Color // Dividers and progress tracks, separators
+
+	Weather, Moon, Sun, Time lipgloss.Color // Panel accents
+
+	IconSun, IconMoon, IconCloud lipgloss.Color // Weather icon art
+
+	// Accents for the current conditions, see WeatherAccent
+	Hot, Cold, Storm, Snow, Rain, Fog, Night lipgloss.Color
+}
+
+// Built-in themes
+var (
+	// DarkTheme is the original WMS look, for dark terminals.
+	DarkTheme = Theme{
+		Name:          config.ThemeDark,
+		Primary:       lipgloss.Color("#60A5FA"), // Blue-400
+		Secondary:     lipgloss.Color("#A78BFA"), // Violet-400
+		Success:       lipgloss.Color("#34D399"), // Emerald-400
+		Warning:       lipgloss.Color("#FBBF24"), // Amber-400
+		Error:         lipgloss.Color("#F87171"), // Red-400
+		Info:          lipgloss.Color("#38BDF8"), // Sky-400
+		Text:          Gray50,
+		TextSecondary: Gray300,
+		TextMuted:     Gray500,
+		TextInverse:   Gray900,
+		Border:        Gray600,
+		Subtle:        Gray700,
+		Weather:       lipgloss.Color("#06B6D4"), // Cyan-500
+		Moon:          lipgloss.Color("#8B5CF6"), // Violet-500
+		Sun:           lipgloss.Color("#F59E0B"), // Amber-500
+		Time:          lipgloss.Color("#10B981"), // Emerald-500
+		IconSun:       lipgloss.Color("#FCD34D"), // Amber-300
+		IconMoon:      Gray200,
+		IconCloud:     Gray400,
+		Hot:           lipgloss.Color("#EF4444"), // Red-500
+		Cold:          lipgloss.Color("#93C5FD"), // Blue-300
+		Storm:         lipgloss.Color("#A855F7"), // Purple-500
+		Snow:          lipgloss.Color("#F8FAFC"), // Slate-50
+		Rain:          lipgloss.Color("#60A5FA"), // Blue-400
+		Fog:           lipgloss.Color("#D1D5DB"), // Gray-300
+		Night:         lipgloss.Color("#818CF8"), // Indigo-400
+	}
+
+	// LightTheme uses darker shades of the same hues, for light terminals.
+	LightTheme = Theme{
+		Name:          config.ThemeLight,
+		Primary:       lipgloss.Color("#2563EB"), // Blue-600
+		Secondary:     lipgloss.Color("#7C3AED"), // Violet-600
+		Success:       lipgloss.Color("#059669"), // Emerald-600
+		Warning:       lipgloss.Color("#B45309"), // Amber-700
+		Error:         lipgloss.Color("#DC2626"), // Red-600
+		Info:          lipgloss.Color("#0284C7"), // Sky-600
+		Text:          Gray900,
+		TextSecondary: Gray700,
+		TextMuted:     Gray500,
+		TextInverse:   Gray50,
+		Border:        Gray300,
+		Subtle:        Gray200,
+		Weather:       lipgloss.Color("#0891B2"), // Cyan-600
+		Moon:          lipgloss.Color("#7C3AED"), // Violet-600
+		Sun:           lipgloss.Color("#D97706"), // Amber-600
+		Time:          lipgloss.Color("#059669"), // Emerald-600
+		IconSun:       lipgloss.Color("#F59E0B"), // Amber-500
+		IconMoon:      Gray500,
+		IconCloud:     Gray400,
+		Hot:           lipgloss.Color("#DC2626"), // Red-600
+		Cold:          lipgloss.Color("#2563EB"), // Blue-600
+		Storm:         lipgloss.Color("#7E22CE"), // Purple-700
+		Snow:          lipgloss.Color("#64748B"), // Slate-500
+		Rain:          lipgloss.Color("#1D4ED8"), // Blue-700
+		Fog:           lipgloss.Color("#6B7280"), // Gray-500
+		Night:         lipgloss.Color("#4F46E5"), // Indigo-600
+	}
+
+	// HighContrastTheme uses saturated colors and white text on dark
+	// terminals, for low vision and bright rooms.
+	HighContrastTheme = Theme{
+		Name:          config.ThemeHighContrast,
+		Primary:       lipgloss.Color("#00AFFF"),
+		Secondary:     lipgloss.Color("#FF5FFF"),
+		Success:       lipgloss.Color("#00FF00"),
+		Warning:       lipgloss.Color("#FFFF00"),
+		Error:         lipgloss.Color("#FF0000"),
+		Info:          lipgloss.Color("#00FFFF"),
+		Text:          White,
+		TextSecondary: White,
+		TextMuted:     lipgloss.Color("#D0D0D0"),
+		TextInverse:   lipgloss.Color("#000000"),
+		Border:        White,
+		Subtle:        lipgloss.Color("#D0D0D0"),
+		Weather:       lipgloss.Color("#00FFFF"),
+		Moon:          lipgloss.Color("#FF5FFF"),
+		Sun:           lipgloss.Color("#FFFF00"),
+		Time:          lipgloss.Color("#00FF00"),
+		IconSun:       lipgloss.Color("#FFFF00"),
+		IconMoon:      White,
+		IconCloud:     lipgloss.Color("#D0D0D0"),
+		Hot:           lipgloss.Color("#FF0000"),
+		Cold:          lipgloss.Color("#00AFFF"),
+		Storm:         lipgloss.Color("#FF00FF"),
+		Snow:          lipgloss.Color("#FFFFFF"),
+		Rain:          lipgloss.Color("#5F87FF"),
+		Fog:           lipgloss.Color("#D0D0D0"),
+		Night:         lipgloss.Color("#8787FF"),
+	}
+
+	// SolarizedTheme uses Ethan Schoonover's Solarized accents on the dark
+	// Solarized background.
+	SolarizedTheme = Theme{
+		Name:          config.ThemeSolarized,
+		Primary:       lipgloss.Color("#268BD2"), // blue
+		Secondary:     lipgloss.Color("#6C71C4"), // violet
+		Success:       lipgloss.Color("#859900"), // green
+		Warning:       lipgloss.Color("#B58900"), // yellow
+		Error:         lipgloss.Color("#DC322F"), // red
+		Info:          lipgloss.Color("#2AA198"), // cyan
+		Text:          lipgloss.Color("#93A1A1"), // base1
+		TextSecondary: lipgloss.Color("#839496"), // base0
+		TextMuted:     lipgloss.Color("#586E75"), // base01
+		TextInverse:   lipgloss.Color("#002B36"), // base03
+		Border:        lipgloss.Color("#586E75"), // base01
+		Subtle:        lipgloss.Color("#073642"), // base02
+		Weather:       lipgloss.Color("#2AA198"), // cyan
+		Moon:          lipgloss.Color("#6C71C4"), // violet
+		Sun:           lipgloss.Color("#CB4B16"), // orange
+		Time:          lipgloss.Color("#859900"), // green
+		IconSun:       lipgloss.Color("#B58900"), // yellow
+		IconMoon:      lipgloss.Color("#EEE8D5"), // base2
+		IconCloud:     lipgloss.Color("#839496"), // base0
+		Hot:           lipgloss.Color("#DC322F"), // red
+		Cold:          lipgloss.Color("#268BD2"), // blue
+		Storm:         lipgloss.Color("#D33682"), // magenta
+		Snow:          lipgloss.Color("#FDF6E3"), // base3
+		Rain:          lipgloss.Color("#268BD2"), // blue
+		Fog:           lipgloss.Color("#839496"), // base0
+		Night:         lipgloss.Color("#6C71C4"), // violet
+	}
+)
+
+// current is the name of the active theme.
+var current string
+
+// detectedProfile is the color profile of the terminal, remembered so colors
+// can be switched back on after use_colors = false replaced it.
+var detectedProfile *termenv.Profile
+
+func init() {
+	ApplyTheme(DarkTheme)
+}
+
+// ApplyTheme makes t the active theme and rebuilds every style from it.
+// Styles copied before the call keep their old colors.
+func ApplyTheme(t Theme) {
+	current = t.Name
+	Primary, Secondary, Success, Warning, Error, Info = t.Primary, t.Secondary, t.Success, t.Warning, t.Error, t.Info
+	TextPrimary, TextSecondary, TextMuted, TextInverse = t.Text, t.TextSecondary, t.TextMuted, t.TextInverse
+	BorderColor, SubtleColor = t.Border, t.Subtle
+	WeatherColor, MoonColor, SunColor, TimeColor = t.Weather, t.Moon, t.Sun, t.Time
+	IconSunColor, IconMoonColor, IconCloudColor = t.IconSun, t.IconMoon, t.IconCloud
+	HotColor, ColdColor, StormColor, SnowColor = t.Hot, t.Cold, t.Storm, t.Snow
+	RainColor, FogColor, NightColor = t.Rain, t.Fog, t.Night
+	buildStyles()
+}
+
+// ApplyConfig applies the theme and color settings of a configuration. Colors
+// are switched off when use_colors is false or NO_COLOR is set; the theme is
+// still applied, so it is in place when colors come back.
+func ApplyConfig(cfg config.Config) {
+	if detectedProfile == nil {
+		profile := lipgloss.ColorProfile() // Ascii when NO_COLOR is set
+		detectedProfile = &profile
+	}
+	if cfg.UseColors {
+		lipgloss.SetColorProfile(*detectedProfile)
+	} else {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+
+	ApplyTheme(ResolveTheme(cfg))
+}
+
+// CurrentTheme returns the name of the active theme.
+func CurrentTheme() string {
+	return current
+}
+
+// DetectBackground asks the terminal for its background color, which the
+// "auto" theme uses to pick between dark and light. The answer is cached, so
+// this must run before Bubble Tea starts reading input; later lookups reuse
+// it.
+func DetectBackground() {
+	lipgloss.HasDarkBackground()
+}
+
+// ResolveTheme returns the theme a configuration selects. Custom themes start
+// from their base theme; names that are not known fall back to "auto".
+func ResolveTheme(cfg config.Config) Theme {
+	if t, ok := builtinTheme(cfg.Theme); ok {
+		return t
+	}
+	custom, ok := cfg.Themes[cfg.Theme]
+	if !ok {
+		return autoTheme()
+	}
+
+	t, ok := builtinTheme(custom.Base)
+	if !ok {
+		t = autoTheme()
+	}
+	t.Name = cfg.Theme
+	for _, c := range []struct {
+		color *lipgloss.Color
+		value string
+	}{
+		{&t.Primary, custom.Primary},
+		{&t.Secondary, custom.Secondary},
+		{&t.Success, custom.Success},
+		{&t.Warning, custom.Warning},
+		{&t.Error, custom.Error},
+		{&t.Info, custom.Info},
+		{&t.Text, custom.Text},
+		{&t.TextSecondary, custom.TextSecondary},
+		{&t.TextMuted, custom.TextMuted},
+		{&t.TextInverse, custom.TextInverse},
+		{&t.Border, custom.Border},
+		{&t.Subtle, custom.Subtle},
+		{&t.Weather, custom.Weather},
+		{&t.Moon, custom.Moon},
+		{&t.Sun, custom.Sun},
+		{&t.Time, custom.Time},
+		{&t.IconSun, custom.IconSun},
+		{&t.IconMoon, custom.IconMoon},
+		{&t.IconCloud, custom.IconCloud},
+		{&t.Hot, custom.Hot},
+		{&t.Cold, custom.Cold},
+		{&t.Storm, custom.Storm},
+		{&t.Snow, custom.Snow},
+		{&t.Rain, custom.Rain},
+		{&t.Fog, custom.Fog},
+		{&t.Night, custom.Night},
+	} {
+		if c.value != "" {
+			*c.color = lipgloss.Color(c.value)
+		}
+	}
+	return t
+}
+
+// builtinTheme returns the built-in theme with the given name, resolving
+// "auto" from the terminal background.
+func builtinTheme(name string) (Theme, bool) {
+	switch name {
+	case config.ThemeAuto:
+		return autoTheme(), true
+	case config.ThemeDark:
+		return DarkTheme, true
+	case config.ThemeLight:
+		return LightTheme, true
+	case config.ThemeHighContrast:
+		return HighContrastTheme, true
+	case config.ThemeSolarized:
+		return SolarizedTheme, true
+	}
+	return Theme{}, false
+}
+
+// autoTheme returns the dark or the light theme, whichever suits the
+// terminal background. Terminals that do not answer count as dark.
+func autoTheme() Theme {
+	if lipgloss.HasDarkBackground() {
+		return DarkTheme
+	}
+	return LightTheme
+}
@@ -0,0 +1,176 @@
+package weather
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"wms/internal/config"
+)
+
+// ArchiveMaxDays is the longest range that can be looked up in the archive at
+// once.
+const ArchiveMaxDays = 31
+
+// archiveClient is used for archive lookups, which can take longer than
+// current weather for long ranges.
+var archiveClient = &http.Client{Timeout: 30 * time.Second}
+
+// OpenMeteoArchiveResponse represents the JSON structure returned by the
+// Open-Meteo historical weather API. Times are Unix seconds, and hours the
+// archive has no data for yet are null.
+type OpenMeteoArchiveResponse struct {
+	Latitude             float64 `json:"latitude"`
+	Longitude            float64 `json:"longitude"`
+	Timezone             string  `json:"timezone"`
+	TimezoneAbbreviation string  `json:"timezone_abbreviation"`
+	UTCOffsetSeconds     int     `json:"utc_offset_seconds"` // Offset at the start of the range only
+	Hourly               struct {
+		Time                []int64    `json:"time"`
+		Temperature2m       []*float64 `json:"temperature_2m"`
+		ApparentTemperature []*float64 `json:"apparent_temperature"`
+		RelativeHumidity2m  []*float64 `json:"relative_humidity_2m"`
+		Precipitation       []*float64 `json:"precipitation"`
+		WeatherCode         []*float64 `json:"weather_code"`
+		PressureMSL         []*float64 `json:"pressure_msl"`
+		CloudCover          []*float64 `json:"cloud_cover"`
+		WindSpeed10m        []*float64 `json:"wind_speed_10m"`
+		WindDirection10m    []*float64 `json:"wind_direction_10m"`
+		IsDay               []*float64 `json:"is_day"`
+	} `json:"hourly"`
+}
+
+// Archive is the past weather of a location, hour by hour.
+type Archive struct {
+	Hours []*Weather     // Oldest first, each with the conditions of the hour in Current
+	Times []time.Time    // When each of the hours began
+	Zone  *time.Location // Time zone of the location, in which LocalTime of the hours is given
+}
+
+// ValidateArchiveRange checks that the days from the first to the last,
+// inclusive, can be looked up at once.
+func ValidateArchiveRange(from, to time.Time) error {
+	if to.Before(from) {
+		return fmt.Errorf("the range ends before it starts")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > ArchiveMaxDays {
+		return fmt.Errorf("the range spans %d days, at most %d can be looked up at once", days, ArchiveMaxDays)
+	}
+	return nil
+}
+
+// FetchArchiveWithConfig resolves the location according to the
+// configuration and looks up its past weather, see FetchArchive.
+func FetchArchiveWithConfig(cfg config.Config, from, to time.Time) (*Archive, error) {
+	location, detectedLocation, err := ResolveLocation(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect location: %w", err)
+	}
+
+	archive, err := FetchArchive(location, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if detectedLocation != nil {
+		for _, w := range archive.Hours {
+			detectedLocation.FillWeather(w)
+		}
+	}
+	return archive, nil
+}
+
+// FetchArchive looks up the weather of every hour from the first to the last
+// day, inclusive, in Open-Meteo's historical archive. Each hour comes back as
+// its own Weather, with the conditions of the hour in Current and the hour in
+// the local time of the location as LocalTime. The archive needs no API key,
+// so it is used whatever the configured provider. It lags a few days behind,
+// and hours without data yet are left out.
+func FetchArchive(location string, from, to time.Time) (*Archive, error) {
+	if err := ValidateArchiveRange(from, to); err != nil {
+		return nil, err
+	}
+
+	o := NewOpenMeteoProvider()
+	geoResult, err := o.getFirstGeoResult(location)
+	if err != nil {
+		return nil, fmt.Errorf("geocoding failed: %w", err)
+	}
+
+	apiURL := fmt.Sprintf(
+		"https://archive-api.open-meteo.com/v1/archive?latitude=%f&longitude=%f&start_date=%s&end_date=%s&hourly=temperature_2m,apparent_temperature,relative_humidity_2m,precipitation,weather_code,pressure_msl,cloud_cover,wind_speed_10m,wind_direction_10m,is_day&timezone=auto&timeformat=unixtime&wind_speed_unit=kmh&temperature_unit=celsius",
+		geoResult.Latitude,
+		geoResult.Longitude,
+		from.Format("2006-01-02"),
+		to.Format("2006-01-02"),
+	)
+
+	var resp OpenMeteoArchiveResponse
+	if err := getJSON(archiveClient, apiURL, &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch archived weather: %w", err)
+	}
+
+	archive := openMeteoArchive(resp)
+	for _, w := range archive.Hours {
+		w.Location.Name = geoResult.Name
+		w.Location.Region = geoResult.Admin1
+		w.Location.Country = geoResult.Country
+		w.Location.Lat = geoResult.Latitude
+		w.Location.Lon = geoResult.Longitude
+	}
+	if len(archive.Hours) == 0 {
+		return nil, fmt.Errorf("no archived weather for %s to %s yet, the archive lags a few days behind", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+	return archive, nil
+}
+
+// archiveZone returns the time zone of an archive response. The offset of
+// the response only holds at the start of the range, so it is used only if
+// the named zone is unknown on this system.
+func archiveZone(resp OpenMeteoArchiveResponse) *time.Location {
+	if zone, err := time.LoadLocation(resp.Timezone); err == nil && resp.Timezone != "" {
+		return zone
+	}
+	return time.FixedZone(resp.TimezoneAbbreviation, resp.UTCOffsetSeconds)
+}
+
+// openMeteoArchive converts the hourly columns of an archive response into
+// one Weather per hour, skipping hours without a temperature. The hours are
+// given in Unix time and converted to the time zone of the location, so
+// local times stay right across daylight saving time changes.
+func openMeteoArchive(resp OpenMeteoArchiveResponse) *Archive {
+	archive := &Archive{Zone: archiveZone(resp)}
+	h := resp.Hourly
+	value := func(column []*float64, i int) float64 {
+		if i >= len(column) || column[i] == nil {
+			return 0
+		}
+		return *column[i]
+	}
+
+	for i, unix := range h.Time {
+		if i >= len(h.Temperature2m) || h.Temperature2m[i] == nil {
+			continue
+		}
+		t := time.Unix(unix, 0).In(archive.Zone)
+		w := &Weather{}
+		w.Location.LocalTime = t.Format("2006-01-02 15:04")
+		w.Current = Current{
+			TempC:      value(h.Temperature2m, i),
+			TempF:      celsiusToFahrenheit(value(h.Temperature2m, i)),
+			IsDay:      int(value(h.IsDay, i)),
+			Condition:  weatherCodeToCondition(int(value(h.WeatherCode, i))),
+			WindMph:    kmhToMph(value(h.WindSpeed10m, i)),
+			WindKph:    value(h.WindSpeed10m, i),
+			WindDir:    degreeToDirection(int(value(h.WindDirection10m, i))),
+			Humidity:   int(value(h.RelativeHumidity2m, i)),
+			FeelslikeC: value(h.ApparentTemperature, i),
+			FeelslikeF: celsiusToFahrenheit(value(h.ApparentTemperature, i)),
+			PrecipMm:   value(h.Precipitation, i),
+			PressureMb: value(h.PressureMSL, i),
+			Cloud:      int(value(h.CloudCover, i)),
+		}
+		archive.Hours = append(archive.Hours, w)
+		archive.Times = append(archive.Times, t)
+	}
+	return archive
+}
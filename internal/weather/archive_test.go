@@ -0,0 +1,131 @@
+package weather
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // Europe/Berlin on systems without a zone database
+)
+
+// archiveResponse returns an archive response for Berlin with a temperature
+// for each of the given hours.
+func archiveResponse(timezone string, offset int, times ...time.Time) OpenMeteoArchiveResponse {
+	resp := OpenMeteoArchiveResponse{Timezone: timezone, TimezoneAbbreviation: "CET", UTCOffsetSeconds: offset}
+	for i, t := range times {
+		temp := float64(i)
+		resp.Hourly.Time = append(resp.Hourly.Time, t.Unix())
+		resp.Hourly.Temperature2m = append(resp.Hourly.Temperature2m, &temp)
+	}
+	return resp
+}
+
+func TestOpenMeteoArchiveAcrossDST(t *testing.T) {
+	// Clocks in Berlin went from 02:00 CET to 03:00 CEST on 2025-03-30
+	start := time.Date(2025, 3, 29, 23, 0, 0, 0, time.UTC)
+	var times []time.Time
+	for i := 0; i < 4; i++ {
+		times = append(times, start.Add(time.Duration(i)*time.Hour))
+	}
+
+	tests := []struct {
+		name      string
+		timezone  string
+		wantLocal []string
+	}{
+		{"named zone", "Europe/Berlin", []string{"2025-03-30 00:00", "2025-03-30 01:00", "2025-03-30 03:00", "2025-03-30 04:00"}},
+		{"unknown zone falls back to the offset", "Mars/Olympus_Mons", []string{"2025-03-30 00:00", "2025-03-30 01:00", "2025-03-30 02:00", "2025-03-30 03:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := openMeteoArchive(archiveResponse(tt.timezone, 3600, times...))
+			if len(archive.Hours) != len(times) || len(archive.Times) != len(times) {
+				t.Fatalf("got %d hours and %d times, want %d", len(archive.Hours), len(archive.Times), len(times))
+			}
+			for i, w := range archive.Hours {
+				if w.Location.LocalTime != tt.wantLocal[i] {
+					t.Errorf("hour %d local time = %q, want %q", i, w.Location.LocalTime, tt.wantLocal[i])
+				}
+				if !archive.Times[i].Equal(times[i]) {
+					t.Errorf("hour %d began at %v, want %v", i, archive.Times[i], times[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOpenMeteoArchiveSkipsMissingHours(t *testing.T) {
+	start := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	resp := archiveResponse("UTC", 0, start, start.Add(time.Hour), start.Add(2*time.Hour))
+	resp.Hourly.Temperature2m[1] = nil
+	resp.Hourly.Temperature2m = resp.Hourly.Temperature2m[:2] // The archive lags behind
+
+	archive := openMeteoArchive(resp)
+	if len(archive.Hours) != 1 || archive.Hours[0].Location.LocalTime != "2025-01-15 00:00" {
+		t.Errorf("hours = %d, want only the first one", len(archive.Hours))
+	}
+}
+
+func TestValidateArchiveRange(t *testing.T) {
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		wantErr string
+	}{
+		{"single day", day, day, ""},
+		{"longest range", day, day.AddDate(0, 0, ArchiveMaxDays-1), ""},
+		{"too long", day, day.AddDate(0, 0, ArchiveMaxDays), "at most 31"},
+		{"backwards", day.AddDate(0, 0, 1), day, "ends before it starts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArchiveRange(tt.from, tt.to)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("ValidateArchiveRange() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("ValidateArchiveRange() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFetchArchive(t *testing.T) {
+	var archiveURL string
+	original := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(req *http.Request) *http.Response {
+		body := `{"results": [{"name": "Berlin", "latitude": 52.52, "longitude": 13.41, "country": "Germany"}]}`
+		if req.URL.Host == "archive-api.open-meteo.com" {
+			archiveURL = req.URL.String()
+			body = `{"timezone": "Europe/Berlin", "utc_offset_seconds": 3600, "hourly": {
+				"time": [1743289200, 1743292800, 1743296400],
+				"temperature_2m": [4.1, 3.8, null],
+				"weather_code": [3, 61, null]
+			}}`
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header)}
+	})
+	t.Cleanup(func() { http.DefaultTransport = original })
+
+	day := time.Date(2025, 3, 30, 0, 0, 0, 0, time.UTC)
+	archive, err := FetchArchive("Berlin", day, day)
+	if err != nil {
+		t.Fatalf("FetchArchive() error = %v", err)
+	}
+	if !strings.Contains(archiveURL, "timeformat=unixtime") || !strings.Contains(archiveURL, "start_date=2025-03-30") {
+		t.Errorf("archive URL = %s, want Unix times for 2025-03-30", archiveURL)
+	}
+	if len(archive.Hours) != 2 || archive.Zone.String() != "Europe/Berlin" {
+		t.Fatalf("got %d hours in %v, want 2 in Europe/Berlin", len(archive.Hours), archive.Zone)
+	}
+	if w := archive.Hours[0]; w.Location.Name != "Berlin" || w.Location.LocalTime != "2025-03-30 00:00" || w.Current.TempC != 4.1 {
+		t.Errorf("first hour = %+v", w)
+	}
+
+	if _, err := FetchArchive("Berlin", day, day.AddDate(0, 0, ArchiveMaxDays)); err == nil {
+		t.Error("FetchArchive() accepted a range that is too long")
+	}
+}
@@ -22,6 +22,7 @@ type DisplayOptions struct {
 // WeatherDisplay is a struct that holds pre-formatted weather data ready for display.
 type WeatherDisplay struct {
 	Icon          *icons.WeatherIcon
+	Accent        lipgloss.Color // Color of the conditions, see AccentColor
 	Location      string
 	Condition     string
 	Temperature   string
@@ -43,8 +44,12 @@ func FormatWeatherDisplay(weather *Weather, cfg config.Config) *WeatherDisplay {
 		Units:        cfg.Units,
 	}
 
-	// Get weather icon
+	// Get weather icon, tinted with the color of the conditions
+	accent := AccentColor(weather)
 	weatherIcon := icons.GetWeatherIcon(weather.Current.Condition, weather.Current.IsDay == 1, opts.UseColors)
+	if opts.UseColors {
+		weatherIcon = icons.GetTintedWeatherIcon(weather.Current.Condition, weather.Current.IsDay == 1, accent)
+	}
 
 	// Format location
 	location := ""
@@ -93,6 +98,7 @@ func FormatWeatherDisplay(weather *Weather, cfg config.Config) *WeatherDisplay {
 
 	return &WeatherDisplay{
 		Icon:          weatherIcon,
+		Accent:        accent,
 		Location:      location,
 		Condition:     weather.Current.Condition,
 		Temperature:   temp + tempUnit,
@@ -106,36 +112,25 @@ func FormatWeatherDisplay(weather *Weather, cfg config.Config) *WeatherDisplay {
 	}
 }
 
+// AccentColor returns the color that reflects the current conditions in the
+// active theme: stormy, snowy, rainy or foggy, the night color after sunset,
+// and otherwise a gradient from cold to hot.
+func AccentColor(weather *Weather) lipgloss.Color {
+	return styles.WeatherAccent(icons.GetSky(weather.Current.Condition), weather.Current.TempC, weather.Current.IsDay == 1)
+}
+
 // RenderWeatherCompact creates a compact, two-column string representation of the
 // weather, inspired by the Stormy TUI. It features an ASCII art icon on the
 // left and formatted weather data on the right.
 func RenderWeatherCompact(weather *Weather, cfg config.Config) string {
 	display := FormatWeatherDisplay(weather, cfg)
 
-	// Use themed colors for the weather card
-	labelColor := styles.WeatherColor
-	valueColor := styles.TextPrimary // Use primary text color for values
-
-	// Create styles
-	labelStyle := lipgloss.NewStyle().Foreground(labelColor)
-	valueStyle := lipgloss.NewStyle().Foreground(valueColor)
-
 	// Get icon lines
 	iconLines := display.Icon.Lines
 
-	// Format precipitation with percentage
-	precipPercent := int(weather.Current.Cloud) // Use cloud coverage as precipitation chance
-	precipText := fmt.Sprintf("%.1f mm | %d%%", weather.Current.PrecipMm, precipPercent)
-
-	// Prepare text lines to match the exact format from the image - left aligned
-	var textLines []string
-	textLines = append(textLines, "") // Empty line to match icon spacing
-	textLines = append(textLines, labelStyle.Render("Weather")+"  "+valueStyle.Render(display.Condition))
-	textLines = append(textLines, labelStyle.Render("Temp")+"     "+valueStyle.Render(display.Temperature))
-	textLines = append(textLines, labelStyle.Render("Wind")+"     "+valueStyle.Render(display.Wind))
-	textLines = append(textLines, labelStyle.Render("Humidity")+" "+valueStyle.Render(display.Humidity))
-	textLines = append(textLines, labelStyle.Render("Precip")+"   "+valueStyle.Render(precipText))
-	textLines = append(textLines, "") // Empty line to match icon spacing
+	// Pad the details to match the icon spacing
+	textLines := append([]string{""}, weatherDetailLines(weather, display)...)
+	textLines = append(textLines, "")
 
 	// Combine icon and text with a robust two-column layout
 	maxLines := max(len(iconLines), len(textLines))
@@ -154,6 +149,54 @@ func RenderWeatherCompact(weather *Weather, cfg config.Config) string {
 		Render(lipgloss.JoinHorizontal(lipgloss.Top, iconBlock, "    ", textBlock))
 }
 
+// RenderWeatherDetails renders the details of RenderWeatherCompact without the
+// ASCII art, for places where there is no room for it.
+func RenderWeatherDetails(weather *Weather, cfg config.Config) string {
+	display := FormatWeatherDisplay(weather, cfg)
+	return lipgloss.JoinVertical(lipgloss.Left, weatherDetailLines(weather, display)...)
+}
+
+// weatherDetailLines formats the labelled weather details, one per line.
+func weatherDetailLines(weather *Weather, display *WeatherDisplay) []string {
+	// Labels take the color of the conditions
+	labelColor := display.Accent
+	valueColor := styles.TextPrimary // Use primary text color for values
+
+	// Create styles
+	labelStyle := lipgloss.NewStyle().Foreground(labelColor)
+	valueStyle := lipgloss.NewStyle().Foreground(valueColor)
+
+	// Format precipitation with percentage
+	precipPercent := int(weather.Current.Cloud) // Use cloud coverage as precipitation chance
+	precipText := fmt.Sprintf("%.1f mm | %d%%", weather.Current.PrecipMm, precipPercent)
+
+	return []string{
+		labelStyle.Render("Weather") + "  " + valueStyle.Render(display.Condition),
+		labelStyle.Render("Temp") + "     " + valueStyle.Render(display.Temperature),
+		labelStyle.Render("Wind") + "     " + valueStyle.Render(display.Wind),
+		labelStyle.Render("Humidity") + " " + valueStyle.Render(display.Humidity),
+		labelStyle.Render("Precip") + "   " + valueStyle.Render(precipText),
+	}
+}
+
+// RenderWeatherLine creates a single-line plain-text summary of the weather,
+// suitable for scripts, logs and notifications.
+func RenderWeatherLine(weather *Weather, cfg config.Config) string {
+	display := FormatWeatherDisplay(weather, cfg)
+
+	summary := fmt.Sprintf("%s %s (feels like %s), wind %s, humidity %s",
+		display.Condition,
+		display.Temperature,
+		display.FeelsLike,
+		display.Wind,
+		display.Humidity,
+	)
+	if display.Location != "" {
+		return display.Location + ": " + summary
+	}
+	return summary
+}
+
 // getWindDirectionSymbol converts a wind direction string (e.g., "N", "SSW")
 // into a corresponding arrow symbol for a more visual representation.
 func getWindDirectionSymbol(dir string) string {
@@ -0,0 +1,99 @@
+// Package weather provides core logic for fetching weather and location data.
+package weather
+
+import (
+	"fmt"
+	"time"
+
+	"wms/internal/config"
+)
+
+// gpsFixTimeout is how long a fetch waits for gpsd before falling back to IP
+// geolocation.
+const gpsFixTimeout = 5 * time.Second
+
+// FetchWithConfig resolves the location according to the configuration and
+// fetches its current weather from the configured provider. It also returns
+// where the location came from ("gpsd", "ipinfo", ...), which is empty for
+// manually specified locations.
+func FetchWithConfig(cfg config.Config) (*Weather, string, error) {
+	weatherData, detectedLocation, err := FetchAtFix(cfg, nil)
+	if err != nil || detectedLocation == nil {
+		return weatherData, "", err
+	}
+	return weatherData, detectedLocation.Source, nil
+}
+
+// FetchAtFix is like FetchWithConfig, but in GPS mode it uses the given fix as
+// the location instead of asking gpsd for one, so a position gpsd already
+// reported does not cost another connection. It returns the detected location
+// rather than its source, which is nil for manually specified locations.
+func FetchAtFix(cfg config.Config, fix *GPSFix) (*Weather, *IPLocation, error) {
+	location, detectedLocation, err := resolveLocation(cfg, fix)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to detect location: %w", err)
+	}
+
+	weatherData, err := FetchLocation(cfg, location)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if detectedLocation != nil {
+		detectedLocation.FillWeather(weatherData)
+	}
+	return weatherData, detectedLocation, nil
+}
+
+// FetchLocation creates the configured weather provider and fetches the
+// weather for a single location.
+func FetchLocation(cfg config.Config, location string) (*Weather, error) {
+	// Create a weather provider based on the configuration.
+	provider, err := CreateWeatherProvider(cfg.WeatherProvider, cfg.WeatherAPIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create weather provider: %w", err)
+	}
+
+	// Fetch the weather data using the provider.
+	weatherData, err := provider.FetchWeather(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch weather: %w", err)
+	}
+
+	return weatherData, nil
+}
+
+// ResolveLocation determines the location to fetch based on the LocationMode
+// setting. Detected locations are returned as "lat,lon" so the provider does
+// not need to geocode the city name again.
+func ResolveLocation(cfg config.Config) (string, *IPLocation, error) {
+	return resolveLocation(cfg, nil)
+}
+
+// resolveLocation is ResolveLocation with an optional GPS fix that was already
+// read from gpsd.
+func resolveLocation(cfg config.Config, fix *GPSFix) (string, *IPLocation, error) {
+	switch {
+	case cfg.LocationMode == "gps" && fix != nil:
+		detected := fix.Location()
+		return detected.Query(), detected, nil
+	case cfg.LocationMode == "gps":
+		// Read the current position from gpsd, falling back to IP detection
+		// when the daemon is not reachable or has no fix yet.
+		fix, err := NewGPSDClient(cfg.GPSDAddress).NextFix(gpsFixTimeout)
+		if err == nil {
+			detected := fix.Location()
+			return detected.Query(), detected, nil
+		}
+	case cfg.LocationMode == "manual" && cfg.Location != "":
+		// Use the manually specified location
+		return cfg.Location, nil, nil
+	}
+
+	// Attempt to automatically detect the user's location via their IP address.
+	detected, err := DetectLocationFromIP(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	return detected.Query(), detected, nil
+}
@@ -0,0 +1,74 @@
+package weather
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"wms/internal/config"
+)
+
+func TestFetchAtFixSkipsGPSD(t *testing.T) {
+	var query string
+	original := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(req *http.Request) *http.Response {
+		query = req.URL.Query().Get("q")
+		body := `{"location": {"name": "", "lat": 52.52, "lon": 13.41}, "current": {"temp_c": 4.5, "condition": {"text": "Overcast"}}}`
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header)}
+	})
+	t.Cleanup(func() { http.DefaultTransport = original })
+
+	// A gpsd that never answers, so reading a fix from it would time out
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	cfg := config.DefaultConfig()
+	cfg.WeatherProvider = config.ProviderWeatherAPI
+	cfg.WeatherAPIKey = "key"
+	cfg.LocationMode = "gps"
+	cfg.GPSDAddress = listener.Addr().String()
+
+	w, detected, err := FetchAtFix(cfg, &GPSFix{Lat: 52.52, Lon: 13.405})
+	if err != nil {
+		t.Fatalf("FetchAtFix() error = %v", err)
+	}
+	if query != "52.5200,13.4050" {
+		t.Errorf("queried %q, want the fix's coordinates", query)
+	}
+	if detected == nil || detected.Source != "gpsd" || detected.Lat != 52.52 {
+		t.Errorf("detected location = %+v, want the fix from gpsd", detected)
+	}
+	if w.Current.TempC != 4.5 {
+		t.Errorf("temperature = %v, want 4.5", w.Current.TempC)
+	}
+}
+
+func TestFetchAtFixOutsideGPSMode(t *testing.T) {
+	var query string
+	original := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(req *http.Request) *http.Response {
+		query = req.URL.Query().Get("q")
+		body := `{"location": {"name": "Tokyo"}, "current": {"temp_c": 9}}`
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header)}
+	})
+	t.Cleanup(func() { http.DefaultTransport = original })
+
+	cfg := config.DefaultConfig()
+	cfg.WeatherProvider = config.ProviderWeatherAPI
+	cfg.WeatherAPIKey = "key"
+	cfg.LocationMode = "manual"
+	cfg.Location = "Tokyo"
+
+	_, detected, err := FetchAtFix(cfg, &GPSFix{Lat: 52.52, Lon: 13.405})
+	if err != nil {
+		t.Fatalf("FetchAtFix() error = %v", err)
+	}
+	if query != "Tokyo" || detected != nil {
+		t.Errorf("queried %q with detected location %+v, want the manual location", query, detected)
+	}
+}
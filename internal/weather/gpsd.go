@@ -0,0 +1,170 @@
+// Package weather provides core logic for fetching weather and location data.
+package weather
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"time"
+)
+
+// DefaultGPSDAddress is the address gpsd listens on out of the box.
+const DefaultGPSDAddress = "localhost:2947"
+
+// gpsdReadTimeout is how long gpsd may stay silent before the connection is
+// considered lost. While watching, gpsd sends a report every second or so,
+// even without a fix.
+const gpsdReadTimeout = 30 * time.Second
+
+// gpsdWatchCommand asks gpsd to stream reports as JSON objects, one per line.
+const gpsdWatchCommand = `?WATCH={"enable":true,"json":true};` + "\n"
+
+// GPSFix is a single position reported by gpsd.
+type GPSFix struct {
+	Lat  float64
+	Lon  float64
+	Time time.Time
+}
+
+// Location converts the fix into an IPLocation so it can be passed to the
+// weather providers in the same way as an IP lookup.
+func (f *GPSFix) Location() *IPLocation {
+	return &IPLocation{Lat: f.Lat, Lon: f.Lon, Source: "gpsd"}
+}
+
+// GPSDClient reads positions from a gpsd daemon using its JSON protocol over
+// TCP. Each call opens its own connection, so the client is safe to share.
+type GPSDClient struct {
+	Addr        string
+	DialTimeout time.Duration
+	ReadTimeout time.Duration // How long gpsd may stay silent, see gpsdReadTimeout
+}
+
+// gpsdReport represents the fields WMS needs from a gpsd report. Only reports
+// of class "TPV" (time-position-velocity) carry a position.
+type gpsdReport struct {
+	Class string  `json:"class"`
+	Mode  int     `json:"mode"` // 0/1 = no fix, 2 = 2D fix, 3 = 3D fix
+	Lat   float64 `json:"lat"`
+	Lon   float64 `json:"lon"`
+	Time  string  `json:"time"`
+}
+
+// NewGPSDClient creates a new instance of the GPSDClient for the given address.
+func NewGPSDClient(addr string) *GPSDClient {
+	if addr == "" {
+		addr = DefaultGPSDAddress
+	}
+	return &GPSDClient{
+		Addr:        addr,
+		DialTimeout: 3 * time.Second,
+		ReadTimeout: gpsdReadTimeout,
+	}
+}
+
+// NextFix waits up to timeout for gpsd to report a position fix.
+func (c *GPSDClient) NextFix(timeout time.Duration) (*GPSFix, error) {
+	return c.watch(time.Now().Add(timeout), func(*GPSFix) bool { return true })
+}
+
+// WaitForMove blocks until gpsd reports a position at least minDistanceKm away
+// from the given fix. It returns an error as soon as the connection to gpsd is
+// lost or gpsd stays silent for longer than the read timeout, so callers can
+// fall back to another location source and reconnect later.
+func (c *GPSDClient) WaitForMove(from GPSFix, minDistanceKm float64) (*GPSFix, error) {
+	return c.watch(time.Time{}, func(fix *GPSFix) bool {
+		return DistanceKm(from.Lat, from.Lon, fix.Lat, fix.Lon) >= minDistanceKm
+	})
+}
+
+// watch connects to gpsd, enables JSON streaming and returns the first fix
+// accepted by the given function. A zero deadline waits indefinitely for an
+// accepted fix, but every read still times out when gpsd stays silent.
+func (c *GPSDClient) watch(deadline time.Time, accept func(*GPSFix) bool) (*GPSFix, error) {
+	conn, err := net.DialTimeout("tcp", c.Addr, c.DialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to gpsd at %s: %w", c.Addr, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(c.readDeadline(deadline)); err != nil {
+		return nil, fmt.Errorf("failed to set gpsd deadline: %w", err)
+	}
+	if _, err := conn.Write([]byte(gpsdWatchCommand)); err != nil {
+		return nil, fmt.Errorf("failed to send watch command to gpsd: %w", err)
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for {
+		if err := conn.SetReadDeadline(c.readDeadline(deadline)); err != nil {
+			return nil, fmt.Errorf("failed to set gpsd deadline: %w", err)
+		}
+		if !scanner.Scan() {
+			break
+		}
+
+		var report gpsdReport
+		if err := json.Unmarshal(scanner.Bytes(), &report); err != nil {
+			// gpsd may emit reports WMS does not understand; skip them.
+			continue
+		}
+		if report.Class != "TPV" || report.Mode < 2 {
+			continue
+		}
+
+		fix := &GPSFix{Lat: report.Lat, Lon: report.Lon, Time: time.Now()}
+		if t, err := time.Parse(time.RFC3339, report.Time); err == nil {
+			fix.Time = t
+		}
+		if accept(fix) {
+			return fix, nil
+		}
+	}
+
+	err = scanner.Err()
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout() && !deadline.IsZero() && !time.Now().Before(deadline):
+		return nil, fmt.Errorf("no position fix from gpsd in time")
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return nil, fmt.Errorf("gpsd sent nothing for %s", c.readTimeout())
+	case err != nil:
+		return nil, fmt.Errorf("failed to read from gpsd: %w", err)
+	}
+	return nil, fmt.Errorf("gpsd closed the connection")
+}
+
+// readDeadline returns the deadline for the next read or write: the read
+// timeout from now, or the overall deadline when that comes first.
+func (c *GPSDClient) readDeadline(deadline time.Time) time.Time {
+	next := time.Now().Add(c.readTimeout())
+	if !deadline.IsZero() && deadline.Before(next) {
+		return deadline
+	}
+	return next
+}
+
+// readTimeout returns the read timeout, gpsdReadTimeout if none is set.
+func (c *GPSDClient) readTimeout() time.Duration {
+	if c.ReadTimeout <= 0 {
+		return gpsdReadTimeout
+	}
+	return c.ReadTimeout
+}
+
+// DistanceKm returns the great-circle distance between two coordinates in
+// kilometers using the haversine formula.
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
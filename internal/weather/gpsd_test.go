@@ -0,0 +1,209 @@
+package weather
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeGPSD listens like gpsd and, once a client sent the watch command,
+// writes the given reports to it one per line. With hold, the connection is
+// kept open afterwards without sending anything else.
+func fakeGPSD(t *testing.T, reports []string, hold bool) *GPSDClient {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(done)
+		listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				command, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil || !strings.HasPrefix(command, "?WATCH=") {
+					t.Errorf("client sent %q, want the watch command", command)
+					return
+				}
+				for _, report := range reports {
+					if _, err := conn.Write([]byte(report + "\n")); err != nil {
+						return
+					}
+				}
+				if hold {
+					<-done
+				}
+			}()
+		}
+	}()
+
+	client := NewGPSDClient(listener.Addr().String())
+	client.ReadTimeout = 200 * time.Millisecond
+	return client
+}
+
+const (
+	versionReport = `{"class":"VERSION","release":"3.25"}`
+	noFixReport   = `{"class":"TPV","mode":1}`
+	berlinReport  = `{"class":"TPV","mode":3,"lat":52.52,"lon":13.405,"time":"2025-01-15T12:00:00Z"}`
+	potsdamReport = `{"class":"TPV","mode":2,"lat":52.39,"lon":13.065,"time":"2025-01-15T12:05:00Z"}`
+	munichReport  = `{"class":"TPV","mode":3,"lat":48.137,"lon":11.575,"time":"2025-01-15T13:00:00Z"}`
+)
+
+func TestNextFix(t *testing.T) {
+	tests := []struct {
+		name    string
+		reports []string
+		hold    bool
+		wantLat float64
+		wantErr string
+	}{
+		{
+			name:    "first fix",
+			reports: []string{versionReport, berlinReport, munichReport},
+			wantLat: 52.52,
+		},
+		{
+			name:    "skips reports without a fix and damaged lines",
+			reports: []string{noFixReport, `{"class":`, `{"class":"SKY","mode":3,"lat":1}`, potsdamReport},
+			wantLat: 52.39,
+		},
+		{
+			name:    "no fix before the timeout",
+			reports: []string{versionReport, noFixReport},
+			hold:    true,
+			wantErr: "no position fix",
+		},
+		{
+			name:    "connection closed",
+			reports: []string{versionReport, noFixReport},
+			wantErr: "closed the connection",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fakeGPSD(t, tt.reports, tt.hold)
+			client.ReadTimeout = time.Minute // Only the NextFix timeout applies
+
+			fix, err := client.NextFix(100 * time.Millisecond)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NextFix() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NextFix() error = %v", err)
+			}
+			if fix.Lat != tt.wantLat {
+				t.Errorf("NextFix() lat = %v, want %v", fix.Lat, tt.wantLat)
+			}
+		})
+	}
+}
+
+func TestNextFixTime(t *testing.T) {
+	client := fakeGPSD(t, []string{berlinReport}, false)
+	fix, err := client.NextFix(time.Second)
+	if err != nil {
+		t.Fatalf("NextFix() error = %v", err)
+	}
+	if want := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC); !fix.Time.Equal(want) {
+		t.Errorf("NextFix() time = %v, want %v", fix.Time, want)
+	}
+}
+
+func TestWaitForMove(t *testing.T) {
+	berlin := GPSFix{Lat: 52.52, Lon: 13.405}
+	tests := []struct {
+		name    string
+		reports []string
+		hold    bool
+		minKm   float64
+		wantLat float64
+		wantErr string
+	}{
+		{
+			name:    "ignores small moves",
+			reports: []string{berlinReport, potsdamReport, munichReport},
+			minKm:   100,
+			wantLat: 48.137,
+		},
+		{
+			name:    "any fix without a minimum distance",
+			reports: []string{noFixReport, berlinReport},
+			wantLat: 52.52,
+		},
+		{
+			name:    "gpsd goes silent",
+			reports: []string{berlinReport},
+			hold:    true,
+			minKm:   100,
+			wantErr: "sent nothing",
+		},
+		{
+			name:    "connection closed",
+			reports: []string{berlinReport},
+			minKm:   100,
+			wantErr: "closed the connection",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fakeGPSD(t, tt.reports, tt.hold)
+
+			type result struct {
+				fix *GPSFix
+				err error
+			}
+			results := make(chan result, 1)
+			go func() {
+				fix, err := client.WaitForMove(berlin, tt.minKm)
+				results <- result{fix, err}
+			}()
+
+			var got result
+			select {
+			case got = <-results:
+			case <-time.After(5 * time.Second):
+				t.Fatal("WaitForMove() did not return")
+			}
+			if tt.wantErr != "" {
+				if got.err == nil || !strings.Contains(got.err.Error(), tt.wantErr) {
+					t.Fatalf("WaitForMove() error = %v, want one containing %q", got.err, tt.wantErr)
+				}
+				return
+			}
+			if got.err != nil {
+				t.Fatalf("WaitForMove() error = %v", got.err)
+			}
+			if got.fix.Lat != tt.wantLat {
+				t.Errorf("WaitForMove() lat = %v, want %v", got.fix.Lat, tt.wantLat)
+			}
+		})
+	}
+}
+
+func TestGPSDUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	if _, err := NewGPSDClient(addr).NextFix(time.Second); err == nil || !strings.Contains(err.Error(), "failed to connect") {
+		t.Errorf("NextFix() error = %v, want a connection error", err)
+	}
+}
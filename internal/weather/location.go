@@ -3,68 +3,341 @@ package weather
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+
+	"wms/internal/config"
 )
 
+// IPLocation is the normalized result of an IP geolocation lookup. Every
+// backend fills in coordinates so the weather providers can be queried
+// directly, without geocoding the city name a second time.
+type IPLocation struct {
+	City     string
+	Region   string
+	Country  string
+	Lat      float64
+	Lon      float64
+	Timezone string
+	Source   string // Name of the backend that resolved the location
+}
+
+// Query returns the location as a "lat,lon" string that every weather
+// provider accepts.
+func (l *IPLocation) Query() string {
+	return fmt.Sprintf("%.4f,%.4f", l.Lat, l.Lon)
+}
+
+// String returns the most specific human-readable name available.
+func (l *IPLocation) String() string {
+	if l.City != "" {
+		if l.Region != "" && l.Region != l.City {
+			return fmt.Sprintf("%s, %s", l.City, l.Region)
+		}
+		return l.City
+	}
+	if l.Country != "" {
+		return l.Country
+	}
+	return l.Query()
+}
+
+// FillWeather copies the place names into weather data that was fetched by
+// coordinates and therefore came back without a resolved name.
+func (l *IPLocation) FillWeather(w *Weather) {
+	if w.Location.Name != "" {
+		return
+	}
+	w.Location.Name = l.City
+	w.Location.Region = l.Region
+	w.Location.Country = l.Country
+	if w.Location.Name == "" {
+		w.Location.Name = l.String()
+	}
+}
+
+// IPGeoProvider defines a common interface for IP geolocation backends.
+type IPGeoProvider interface {
+	LocateIP() (*IPLocation, error)
+	GetProviderName() string
+}
+
+// IPAPIProvider looks up the location with ip-api.com. The free tier is only
+// available over plain HTTP, so it is tried after the HTTPS backends.
+type IPAPIProvider struct {
+	Client *http.Client
+}
+
+// IPInfoProvider looks up the location with ipinfo.io over HTTPS. A token is
+// optional and only raises the rate limit.
+type IPInfoProvider struct {
+	Token  string
+	Client *http.Client
+}
+
+// IPAPICoProvider looks up the location with ipapi.co over HTTPS.
+type IPAPICoProvider struct {
+	Client *http.Client
+}
+
+// StaticIPGeoProvider always returns a fixed, user-configured location. It is
+// useful behind VPNs or on machines whose IP address resolves to the wrong city.
+type StaticIPGeoProvider struct {
+	Location IPLocation
+}
+
+// FallbackIPGeoProvider tries each backend in order and returns the first
+// successful result.
+type FallbackIPGeoProvider struct {
+	Providers []IPGeoProvider
+}
+
 // IPLocationResponse represents the structure of the JSON response from the
 // ip-api.com geolocation service.
 type IPLocationResponse struct {
-	City    string  `json:"city"`
-	Region  string  `json:"region"`
-	Country string  `json:"country"`
-	Lat     float64 `json:"lat"`
-	Lon     float64 `json:"lon"`
-	Query   string  `json:"query"`
+	Status     string  `json:"status"`
+	Message    string  `json:"message"`
+	City       string  `json:"city"`
+	RegionName string  `json:"regionName"`
+	Country    string  `json:"country"`
+	Lat        float64 `json:"lat"`
+	Lon        float64 `json:"lon"`
+	Timezone   string  `json:"timezone"`
+	Query      string  `json:"query"`
+}
+
+// IPInfoResponse represents the structure of the JSON response from ipinfo.io.
+type IPInfoResponse struct {
+	City     string `json:"city"`
+	Region   string `json:"region"`
+	Country  string `json:"country"`
+	Loc      string `json:"loc"` // "lat,lon"
+	Timezone string `json:"timezone"`
+}
+
+// IPAPICoResponse represents the structure of the JSON response from ipapi.co.
+type IPAPICoResponse struct {
+	Error       bool    `json:"error"`
+	Reason      string  `json:"reason"`
+	City        string  `json:"city"`
+	Region      string  `json:"region"`
+	CountryName string  `json:"country_name"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Timezone    string  `json:"timezone"`
+}
+
+// NewIPAPIProvider creates a new instance of the IPAPIProvider.
+func NewIPAPIProvider() *IPAPIProvider {
+	return &IPAPIProvider{Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// NewIPInfoProvider creates a new instance of the IPInfoProvider with an
+// optional API token.
+func NewIPInfoProvider(token string) *IPInfoProvider {
+	return &IPInfoProvider{Token: token, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// NewIPAPICoProvider creates a new instance of the IPAPICoProvider.
+func NewIPAPICoProvider() *IPAPICoProvider {
+	return &IPAPICoProvider{Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// LocateIP fetches the location from ip-api.com.
+func (p *IPAPIProvider) LocateIP() (*IPLocation, error) {
+	var resp IPLocationResponse
+	if err := getJSON(p.Client, "http://ip-api.com/json/?fields=status,message,city,regionName,country,lat,lon,timezone,query", &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "" && resp.Status != "success" {
+		return nil, fmt.Errorf("lookup failed: %s", resp.Message)
+	}
+
+	return &IPLocation{
+		City:     resp.City,
+		Region:   resp.RegionName,
+		Country:  resp.Country,
+		Lat:      resp.Lat,
+		Lon:      resp.Lon,
+		Timezone: resp.Timezone,
+		Source:   p.GetProviderName(),
+	}, nil
+}
+
+// GetProviderName returns the name of the provider.
+func (p *IPAPIProvider) GetProviderName() string {
+	return config.IPGeoIPAPI
+}
+
+// LocateIP fetches the location from ipinfo.io.
+func (p *IPInfoProvider) LocateIP() (*IPLocation, error) {
+	apiURL := "https://ipinfo.io/json"
+	if p.Token != "" {
+		apiURL += "?token=" + p.Token
+	}
+
+	var resp IPInfoResponse
+	if err := getJSON(p.Client, apiURL, &resp); err != nil {
+		return nil, err
+	}
+
+	lat, lon, ok := parseCoordinates(resp.Loc)
+	if !ok {
+		return nil, fmt.Errorf("response did not contain coordinates")
+	}
+
+	return &IPLocation{
+		City:     resp.City,
+		Region:   resp.Region,
+		Country:  resp.Country,
+		Lat:      lat,
+		Lon:      lon,
+		Timezone: resp.Timezone,
+		Source:   p.GetProviderName(),
+	}, nil
+}
+
+// GetProviderName returns the name of the provider.
+func (p *IPInfoProvider) GetProviderName() string {
+	return config.IPGeoIPInfo
+}
+
+// LocateIP fetches the location from ipapi.co.
+func (p *IPAPICoProvider) LocateIP() (*IPLocation, error) {
+	var resp IPAPICoResponse
+	if err := getJSON(p.Client, "https://ipapi.co/json/", &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error {
+		return nil, fmt.Errorf("lookup failed: %s", resp.Reason)
+	}
+
+	return &IPLocation{
+		City:     resp.City,
+		Region:   resp.Region,
+		Country:  resp.CountryName,
+		Lat:      resp.Latitude,
+		Lon:      resp.Longitude,
+		Timezone: resp.Timezone,
+		Source:   p.GetProviderName(),
+	}, nil
+}
+
+// GetProviderName returns the name of the provider.
+func (p *IPAPICoProvider) GetProviderName() string {
+	return config.IPGeoIPAPICo
+}
+
+// LocateIP returns the configured static location.
+func (p *StaticIPGeoProvider) LocateIP() (*IPLocation, error) {
+	location := p.Location
+	location.Source = p.GetProviderName()
+	return &location, nil
+}
+
+// GetProviderName returns the name of the provider.
+func (p *StaticIPGeoProvider) GetProviderName() string {
+	return config.IPGeoStatic
+}
+
+// LocateIP tries every backend in order. If all of them fail, the individual
+// errors are joined so the user can see why each one was rejected.
+func (p *FallbackIPGeoProvider) LocateIP() (*IPLocation, error) {
+	var errs []error
+	for _, provider := range p.Providers {
+		location, err := provider.LocateIP()
+		if err == nil {
+			return location, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.GetProviderName(), err))
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no IP geolocation backends configured")
+	}
+	return nil, errors.Join(errs...)
+}
+
+// GetProviderName returns the name of the provider.
+func (p *FallbackIPGeoProvider) GetProviderName() string {
+	return config.ProviderIPGeo
+}
+
+// CreateIPGeoProvider is a factory function that builds the chain of IP
+// geolocation backends listed in the configuration. The static backend is
+// only included when a static location has been configured.
+func CreateIPGeoProvider(cfg config.Config) IPGeoProvider {
+	chain := &FallbackIPGeoProvider{}
+	for _, name := range cfg.IPGeoProviders {
+		switch strings.ToLower(name) {
+		case config.IPGeoStatic:
+			if cfg.StaticLocation.IsSet() {
+				chain.Providers = append(chain.Providers, &StaticIPGeoProvider{Location: IPLocation{
+					City:     cfg.StaticLocation.Name,
+					Lat:      cfg.StaticLocation.Latitude,
+					Lon:      cfg.StaticLocation.Longitude,
+					Timezone: cfg.StaticLocation.Timezone,
+				}})
+			}
+		case config.IPGeoIPInfo:
+			chain.Providers = append(chain.Providers, NewIPInfoProvider(cfg.IPInfoToken))
+		case config.IPGeoIPAPICo:
+			chain.Providers = append(chain.Providers, NewIPAPICoProvider())
+		case config.IPGeoIPAPI:
+			chain.Providers = append(chain.Providers, NewIPAPIProvider())
+		}
+	}
+	return chain
 }
 
 // DetectLocationFromIP attempts to determine the user's location based on their
-// public IP address. It uses the free ip-api.com service, which requires no
-// API key.
-func DetectLocationFromIP() (string, error) {
-	// Initialize an HTTP client with a 10-second timeout to prevent the
-	// application from hanging on slow network requests.
-	client := &http.Client{Timeout: 10 * time.Second}
-
-	// Make a GET request to the ip-api.com JSON endpoint.
-	resp, err := client.Get("http://ip-api.com/json/")
+// public IP address, trying the configured geolocation backends in order.
+func DetectLocationFromIP(cfg config.Config) (*IPLocation, error) {
+	return CreateIPGeoProvider(cfg).LocateIP()
+}
+
+// getJSON performs a GET request and decodes the JSON response into target.
+func getJSON(client *http.Client, apiURL string, target interface{}) error {
+	resp, err := client.Get(apiURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to get location from IP: %w", err)
+		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Check for a successful HTTP status code.
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("IP geolocation service returned status %d", resp.StatusCode)
+		return fmt.Errorf("service returned status %d", resp.StatusCode)
 	}
 
-	// Read the response body.
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return fmt.Errorf("failed to read response: %w", err)
 	}
 
-	// Unmarshal the JSON response into the IPLocationResponse struct.
-	var location IPLocationResponse
-	if err := json.Unmarshal(body, &location); err != nil {
-		return "", fmt.Errorf("failed to parse location response: %w", err)
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
 	}
+	return nil
+}
 
-	// Return the most specific location information available.
-	if location.City != "" {
-		if location.Region != "" && location.Region != location.City {
-			return fmt.Sprintf("%s, %s", location.City, location.Region), nil
-		}
-		return location.City, nil
+// parseCoordinates parses a "lat,lon" string into its two components.
+func parseCoordinates(s string) (float64, float64, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return 0, 0, false
 	}
-
-	// Fallback to the country name if the city is not available.
-	if location.Country != "" {
-		return location.Country, nil
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, false
 	}
-
-	// If no location information can be determined, return an error.
-	return "", fmt.Errorf("no location information available")
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
 }
@@ -0,0 +1,233 @@
+package weather
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"wms/internal/config"
+)
+
+// fakeIPGeo is an IP geolocation backend that returns a fixed result and
+// counts its lookups.
+type fakeIPGeo struct {
+	name     string
+	location *IPLocation
+	err      error
+	calls    int
+}
+
+func (f *fakeIPGeo) LocateIP() (*IPLocation, error) {
+	f.calls++
+	return f.location, f.err
+}
+
+func (f *fakeIPGeo) GetProviderName() string {
+	return f.name
+}
+
+func TestFallbackIPGeoProvider(t *testing.T) {
+	berlin := &IPLocation{City: "Berlin"}
+	tokyo := &IPLocation{City: "Tokyo"}
+	tests := []struct {
+		name      string
+		providers []*fakeIPGeo
+		want      *IPLocation
+		wantCalls []int
+		wantErr   []string
+	}{
+		{
+			name:      "first backend wins",
+			providers: []*fakeIPGeo{{name: "a", location: berlin}, {name: "b", location: tokyo}},
+			want:      berlin,
+			wantCalls: []int{1, 0},
+		},
+		{
+			name:      "falls back after a failure",
+			providers: []*fakeIPGeo{{name: "a", err: errors.New("rate limited")}, {name: "b", location: tokyo}},
+			want:      tokyo,
+			wantCalls: []int{1, 1},
+		},
+		{
+			name:      "every backend fails",
+			providers: []*fakeIPGeo{{name: "a", err: errors.New("rate limited")}, {name: "b", err: errors.New("timeout")}},
+			wantCalls: []int{1, 1},
+			wantErr:   []string{"a: rate limited", "b: timeout"},
+		},
+		{
+			name:    "no backends",
+			wantErr: []string{"no IP geolocation backends"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &FallbackIPGeoProvider{}
+			for _, provider := range tt.providers {
+				chain.Providers = append(chain.Providers, provider)
+			}
+
+			got, err := chain.LocateIP()
+			if got != tt.want {
+				t.Errorf("LocateIP() = %v, want %v", got, tt.want)
+			}
+			for _, want := range tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("LocateIP() error = %v, want one containing %q", err, want)
+				}
+			}
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Errorf("LocateIP() error = %v", err)
+			}
+			for i, provider := range tt.providers {
+				if provider.calls != tt.wantCalls[i] {
+					t.Errorf("backend %s called %d times, want %d", provider.name, provider.calls, tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCreateIPGeoProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers []string
+		static    config.StaticLocation
+		want      []string
+	}{
+		{
+			name:      "configured order",
+			providers: []string{"ip-api", "ipinfo", "ipapi.co"},
+			want:      []string{config.IPGeoIPAPI, config.IPGeoIPInfo, config.IPGeoIPAPICo},
+		},
+		{
+			name:      "static location without coordinates is skipped",
+			providers: []string{"static", "ipinfo"},
+			want:      []string{config.IPGeoIPInfo},
+		},
+		{
+			name:      "static location",
+			providers: []string{"Static", "ipinfo"},
+			static:    config.StaticLocation{Name: "Home", Latitude: 52.5, Longitude: 13.4},
+			want:      []string{config.IPGeoStatic, config.IPGeoIPInfo},
+		},
+		{
+			name:      "unknown backends are ignored",
+			providers: []string{"carrier-pigeon", "ipinfo"},
+			want:      []string{config.IPGeoIPInfo},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.IPGeoProviders = tt.providers
+			cfg.StaticLocation = tt.static
+
+			chain := CreateIPGeoProvider(cfg).(*FallbackIPGeoProvider)
+			var got []string
+			for _, provider := range chain.Providers {
+				got = append(got, provider.GetProviderName())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("backends = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// roundTripFunc answers HTTP requests without a network.
+type roundTripFunc func(*http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+// fakeClient returns an HTTP client that answers every request with the
+// given status and body.
+func fakeClient(status int, body string) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(*http.Request) *http.Response {
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header)}
+	})}
+}
+
+func TestIPGeoResponses(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider IPGeoProvider
+		wantCity string
+		wantLat  float64
+		wantErr  string
+	}{
+		{
+			name:     "ipinfo",
+			provider: &IPInfoProvider{Client: fakeClient(200, `{"city":"Berlin","loc":"52.52,13.40","timezone":"Europe/Berlin"}`)},
+			wantCity: "Berlin",
+			wantLat:  52.52,
+		},
+		{
+			name:     "ipinfo without coordinates",
+			provider: &IPInfoProvider{Client: fakeClient(200, `{"city":"Berlin","loc":"north"}`)},
+			wantErr:  "coordinates",
+		},
+		{
+			name:     "ipapi.co",
+			provider: &IPAPICoProvider{Client: fakeClient(200, `{"city":"Tokyo","latitude":35.68,"longitude":139.69}`)},
+			wantCity: "Tokyo",
+			wantLat:  35.68,
+		},
+		{
+			name:     "ipapi.co error",
+			provider: &IPAPICoProvider{Client: fakeClient(200, `{"error":true,"reason":"RateLimited"}`)},
+			wantErr:  "RateLimited",
+		},
+		{
+			name:     "ip-api failure",
+			provider: &IPAPIProvider{Client: fakeClient(200, `{"status":"fail","message":"reserved range"}`)},
+			wantErr:  "reserved range",
+		},
+		{
+			name:     "HTTP error",
+			provider: &IPAPIProvider{Client: fakeClient(429, `{}`)},
+			wantErr:  "status 429",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, err := tt.provider.LocateIP()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LocateIP() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LocateIP() error = %v", err)
+			}
+			if location.City != tt.wantCity || location.Lat != tt.wantLat || location.Source != tt.provider.GetProviderName() {
+				t.Errorf("LocateIP() = %+v, want %s at %v from %s", location, tt.wantCity, tt.wantLat, tt.provider.GetProviderName())
+			}
+		})
+	}
+}
+
+func TestParseCoordinates(t *testing.T) {
+	tests := []struct {
+		in       string
+		lat, lon float64
+		ok       bool
+	}{
+		{"52.52,13.405", 52.52, 13.405, true},
+		{" -33.87 , 151.21 ", -33.87, 151.21, true},
+		{"91,0", 0, 0, false},
+		{"0,181", 0, 0, false},
+		{"52.52", 0, 0, false},
+		{"a,b", 0, 0, false},
+	}
+	for _, tt := range tests {
+		lat, lon, ok := parseCoordinates(tt.in)
+		if lat != tt.lat || lon != tt.lon || ok != tt.ok {
+			t.Errorf("parseCoordinates(%q) = %v, %v, %v, want %v, %v, %v", tt.in, lat, lon, ok, tt.lat, tt.lon, tt.ok)
+		}
+	}
+}
@@ -29,23 +29,42 @@ type Weather struct {
 		Lon       float64 `json:"lon"`
 		LocalTime string  `json:"localtime"`
 	} `json:"location"`
-	Current struct {
-		TempC      float64 `json:"temp_c"`
-		TempF      float64 `json:"temp_f"`
-		IsDay      int     `json:"is_day"`
-		Condition  string  `json:"condition"`
-		WindMph    float64 `json:"wind_mph"`
-		WindKph    float64 `json:"wind_kph"`
-		WindDir    string  `json:"wind_dir"`
-		Humidity   int     `json:"humidity"`
-		FeelslikeC float64 `json:"feelslike_c"`
-		FeelslikeF float64 `json:"feelslike_f"`
-		UV         float64 `json:"uv"`
-		PrecipMm   float64 `json:"precip_mm"`
-		PressureMb float64 `json:"pressure_mb"`
-		Cloud      int     `json:"cloud"`
-		Visibility float64 `json:"vis_km"`
-	} `json:"current"`
+	Current Current          `json:"current"`
+	Hourly  []HourlyForecast `json:"hourly,omitempty"` // Starting with the current hour, up to HourlyForecastHours
+}
+
+// Current holds the observed conditions at the time of a fetch.
+type Current struct {
+	TempC      float64 `json:"temp_c"`
+	TempF      float64 `json:"temp_f"`
+	IsDay      int     `json:"is_day"`
+	Condition  string  `json:"condition"`
+	WindMph    float64 `json:"wind_mph"`
+	WindKph    float64 `json:"wind_kph"`
+	WindDir    string  `json:"wind_dir"`
+	Humidity   int     `json:"humidity"`
+	FeelslikeC float64 `json:"feelslike_c"`
+	FeelslikeF float64 `json:"feelslike_f"`
+	UV         float64 `json:"uv"`
+	PrecipMm   float64 `json:"precip_mm"`
+	PressureMb float64 `json:"pressure_mb"`
+	Cloud      int     `json:"cloud"`
+	Visibility float64 `json:"vis_km"`
+}
+
+// HourlyForecastHours is how far ahead the hourly forecast reaches.
+const HourlyForecastHours = 48
+
+// HourlyForecast is the forecast for a single hour.
+type HourlyForecast struct {
+	Time         string  `json:"time"` // Local time at the location, "2006-01-02 15:04"
+	TempC        float64 `json:"temp_c"`
+	TempF        float64 `json:"temp_f"`
+	FeelslikeC   float64 `json:"feelslike_c"`
+	FeelslikeF   float64 `json:"feelslike_f"`
+	ChanceOfRain int     `json:"chance_of_rain"` // Probability of precipitation in percent
+	WindKph      float64 `json:"wind_kph"`
+	WindMph      float64 `json:"wind_mph"`
 }
 
 // WeatherAPIResponse represents the specific JSON structure returned by the
@@ -80,6 +99,22 @@ type WeatherAPIResponse struct {
 		Cloud      int     `json:"cloud"`
 		Visibility float64 `json:"vis_km"`
 	} `json:"current"`
+	Forecast struct {
+		Forecastday []struct {
+			Hour []struct {
+				TimeEpoch    int64   `json:"time_epoch"`
+				Time         string  `json:"time"`
+				TempC        float64 `json:"temp_c"`
+				TempF        float64 `json:"temp_f"`
+				FeelslikeC   float64 `json:"feelslike_c"`
+				FeelslikeF   float64 `json:"feelslike_f"`
+				ChanceOfRain int     `json:"chance_of_rain"`
+				ChanceOfSnow int     `json:"chance_of_snow"`
+				WindKph      float64 `json:"wind_kph"`
+				WindMph      float64 `json:"wind_mph"`
+			} `json:"hour"`
+		} `json:"forecastday"`
+	} `json:"forecast"`
 }
 
 // OpenMeteoResponse represents the specific JSON structure returned by the
@@ -98,6 +133,13 @@ type OpenMeteoResponse struct {
 		WindDirection10m   int     `json:"wind_direction_10m"`
 		IsDay              int     `json:"is_day"`
 	} `json:"current"`
+	Hourly struct {
+		Time                     []string  `json:"time"`
+		Temperature2m            []float64 `json:"temperature_2m"`
+		ApparentTemperature      []float64 `json:"apparent_temperature"`
+		PrecipitationProbability []int     `json:"precipitation_probability"`
+		WindSpeed10m             []float64 `json:"wind_speed_10m"`
+	} `json:"hourly"`
 }
 
 // GeoResult represents a single geocoding result from the Open-Meteo geocoding API.
@@ -156,7 +198,7 @@ func NewOpenMeteoProvider() *OpenMeteoProvider {
 func (w *WeatherAPIProvider) FetchWeather(location string) (*Weather, error) {
 	encodedLocation := url.QueryEscape(location)
 	apiURL := fmt.Sprintf(
-		"http://api.weatherapi.com/v1/current.json?key=%s&q=%s&aqi=no",
+		"http://api.weatherapi.com/v1/forecast.json?key=%s&q=%s&days=3&aqi=no&alerts=no",
 		w.APIKey,
 		encodedLocation,
 	)
@@ -223,6 +265,27 @@ func (w *WeatherAPIProvider) FetchWeather(location string) (*Weather, error) {
 		},
 	}
 
+	// Keep the hours from the current one on; the first day of the forecast
+	// starts at midnight
+	startEpoch := time.Now().Truncate(time.Hour).Unix()
+	for _, day := range weatherAPIResp.Forecast.Forecastday {
+		for _, hour := range day.Hour {
+			if hour.TimeEpoch < startEpoch || len(weather.Hourly) == HourlyForecastHours {
+				continue
+			}
+			weather.Hourly = append(weather.Hourly, HourlyForecast{
+				Time:         hour.Time,
+				TempC:        hour.TempC,
+				TempF:        hour.TempF,
+				FeelslikeC:   hour.FeelslikeC,
+				FeelslikeF:   hour.FeelslikeF,
+				ChanceOfRain: max(hour.ChanceOfRain, hour.ChanceOfSnow),
+				WindKph:      hour.WindKph,
+				WindMph:      hour.WindMph,
+			})
+		}
+	}
+
 	return weather, nil
 }
 
@@ -241,7 +304,7 @@ func (o *OpenMeteoProvider) FetchWeather(location string) (*Weather, error) {
 
 	// Then fetch weather data
 	apiURL := fmt.Sprintf(
-		"https://api.open-meteo.com/v1/forecast?latitude=%f&longitude=%f&current=temperature_2m,weather_code,precipitation,relative_humidity_2m,wind_speed_10m,wind_direction_10m,is_day&wind_speed_unit=kmh&temperature_unit=celsius",
+		"https://api.open-meteo.com/v1/forecast?latitude=%f&longitude=%f&current=temperature_2m,weather_code,precipitation,relative_humidity_2m,wind_speed_10m,wind_direction_10m,is_day&hourly=temperature_2m,apparent_temperature,precipitation_probability,wind_speed_10m&forecast_days=3&timezone=auto&wind_speed_unit=kmh&temperature_unit=celsius",
 		geoResult.Latitude,
 		geoResult.Longitude,
 	)
@@ -313,6 +376,7 @@ func (o *OpenMeteoProvider) FetchWeather(location string) (*Weather, error) {
 			Visibility: 0, // Open-Meteo doesn't provide visibility in basic plan
 		},
 	}
+	weather.Hourly = openMeteoHourly(openMeteoResp)
 
 	return weather, nil
 }
@@ -322,9 +386,46 @@ func (o *OpenMeteoProvider) GetProviderName() string {
 	return ProviderOpenMeteo
 }
 
+// openMeteoHourly converts the hourly columns of an Open-Meteo response into
+// an hourly forecast starting with the current hour. Times are local to the
+// location, like the time of the current conditions, so they can be compared
+// as strings.
+func openMeteoHourly(resp OpenMeteoResponse) []HourlyForecast {
+	h := resp.Hourly
+	n := min(min(len(h.Time), len(h.Temperature2m)), min(min(len(h.ApparentTemperature), len(h.PrecipitationProbability)), len(h.WindSpeed10m)))
+	currentHour := resp.Current.Time
+	if len(currentHour) >= len("2006-01-02T15") {
+		currentHour = currentHour[:len("2006-01-02T15")] + ":00"
+	}
+
+	var hourly []HourlyForecast
+	for i := 0; i < n && len(hourly) < HourlyForecastHours; i++ {
+		if h.Time[i] < currentHour {
+			continue
+		}
+		hourly = append(hourly, HourlyForecast{
+			Time:         strings.Replace(h.Time[i], "T", " ", 1),
+			TempC:        h.Temperature2m[i],
+			TempF:        celsiusToFahrenheit(h.Temperature2m[i]),
+			FeelslikeC:   h.ApparentTemperature[i],
+			FeelslikeF:   celsiusToFahrenheit(h.ApparentTemperature[i]),
+			ChanceOfRain: h.PrecipitationProbability[i],
+			WindKph:      h.WindSpeed10m[i],
+			WindMph:      kmhToMph(h.WindSpeed10m[i]),
+		})
+	}
+	return hourly
+}
+
 // getFirstGeoResult is a helper function that fetches the geographic
-// coordinates for a given location string.
+// coordinates for a given location string, which may also be a "lat,lon" pair.
 func (o *OpenMeteoProvider) getFirstGeoResult(location string) (*GeoResult, error) {
+	// Coordinates (e.g. from IP geolocation) need no lookup. The name is left
+	// empty so the caller can fill it in from its own source.
+	if lat, lon, ok := parseCoordinates(location); ok {
+		return &GeoResult{Latitude: lat, Longitude: lon}, nil
+	}
+
 	encodedLocation := url.QueryEscape(location)
 	geoURL := fmt.Sprintf("https://geocoding-api.open-meteo.com/v1/search?name=%s&count=1", encodedLocation)
 